@@ -0,0 +1,55 @@
+package conveyor
+
+// Acknowledged is implemented by sources that want to know once each of their items has been fully processed,
+// e.g. to ack it back to a queue. OnAck() is called once every item derived from it went through the sinks,
+// across every branch of a ReplicateJoint. OnNack() is called as soon as one of them fails instead:
+// an executor returns an error, panics, or records one with ctx.RecordError(), or an edge drops it.
+// Exactly one of them is called per item, possibly from any goroutine, except for items still in flight
+// when the conveyor is stopped, which get neither.
+type Acknowledged[TOut any] interface {
+	OnAck(item TOut)
+	OnNack(item TOut, err error)
+}
+
+// EnableAcks makes every item of the source carry an ack handle, through the operations & joints, so that its
+// OnAck() or OnNack() is called once the item has been processed. The source must implement Acknowledged[TOut].
+//
+// Like with EnableCheckpoints(), operations must run in WorkerModeTransaction, so that their outputs get the
+// handle of their inputs, and ReplicateJoint is the only joint. Sinks must too, so that an item is only acked
+// once a sink is done with it, except the sink of OutputChannel() or Collect(), acking an item once it's on the channel.
+// The source may run in either mode.
+// Will have no effect, once you add your first node
+func (cnv *Conveyor) EnableAcks() *Conveyor {
+	if !cnv.openForConfigChange {
+		return cnv
+	}
+	cnv.acks = true
+	return cnv
+}
+
+// itemContext is given to executors along with a tracked item, so that the errors they record nack it,
+// and they can read its metadata
+type itemContext struct {
+	CnvContext
+	tok *token
+}
+
+func (ic *itemContext) RecordError(stage string, err error) {
+	ic.CnvContext.RecordError(stage, err)
+	ic.tok.fail(err)
+}
+
+// onNack calls fn if the item ctx was given with is nacked, so that an executor can undo what it did for it
+func onNack(ctx CnvContext, fn func()) {
+	if ic, ok := ctx.(*itemContext); ok && ic.tok.onNack != nil {
+		ic.tok.onFail(fn)
+	}
+}
+
+// contextFor returns the context to run the item of tok with
+func contextFor(ctx CnvContext, tok *token) CnvContext {
+	if tok.onNack == nil && tok.meta == nil {
+		return ctx
+	}
+	return &itemContext{CnvContext: ctx, tok: tok}
+}
@@ -0,0 +1,159 @@
+package conveyor
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ackingSource records the acks & nacks of the items of any source
+type ackingSource struct {
+	SourceExecutor[int]
+	mu    sync.Mutex
+	acks  []int
+	nacks map[int]error
+}
+
+func newAckingSource(src SourceExecutor[int]) *ackingSource {
+	return &ackingSource{SourceExecutor: src, nacks: make(map[int]error)}
+}
+
+func (as *ackingSource) OnAck(item int) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	as.acks = append(as.acks, item)
+}
+
+func (as *ackingSource) OnNack(item int, err error) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	as.nacks[item] = err
+}
+
+func (as *ackingSource) acked() []int {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	sort.Ints(as.acks)
+	return as.acks
+}
+
+// TestAcks_Replicate verifies that an item is acked once every branch is done with it, and nacked
+// as soon as any of them fails, or records an error.
+func TestAcks_Replicate(t *testing.T) {
+	errOdd := errors.New("odd")
+	errTens := errors.New("tens")
+	errFour := errors.New("four")
+	src := newAckingSource(SourceFromSeq("src", upTo(50)))
+
+	cnv, _ := NewConveyor("acks", 10)
+	cnv.EnableAcks()
+	require.NoError(t, AddSource[int](cnv, src, WorkerModeTransaction))
+	require.NoError(t, AddOperation[int, int](cnv, OperationFunc("evens", 3, func(ctx CnvContext, in int) (int, error) {
+		if in%2 == 1 {
+			return 0, errOdd
+		}
+		return in, nil
+	}), WorkerModeTransaction))
+	joint, _ := NewReplicateJoint[int]("replicate", 2)
+	require.NoError(t, AddJointAfterNode[int, int](cnv, joint))
+	require.NoError(t, AddSinkAfterJoint[int](cnv, SinkFunc("tens", 2, func(ctx CnvContext, in int) error {
+		if in%10 == 0 {
+			return errTens
+		}
+		return nil
+	}), WorkerModeTransaction))
+	require.NoError(t, AddSinkAfterJoint[int](cnv, SinkFunc("four", 2, func(ctx CnvContext, in int) error {
+		if in == 4 {
+			ctx.RecordError("four", errFour) // The sink carries on, but the item has failed
+		}
+		return nil
+	}), WorkerModeTransaction))
+	require.NoError(t, startWithin(t, cnv, time.Second))
+
+	var acks []int
+	for i := 0; i < 50; i++ {
+		switch {
+		case i%2 == 1:
+			assert.ErrorIs(t, src.nacks[i], errOdd, "item %d", i)
+		case i%10 == 0:
+			assert.ErrorIs(t, src.nacks[i], errTens, "item %d", i)
+		case i == 4:
+			assert.ErrorIs(t, src.nacks[i], errFour, "item %d", i)
+		default:
+			acks = append(acks, i)
+		}
+	}
+	assert.Equal(t, acks, src.acked())
+	assert.Len(t, src.nacks, 50-len(acks), "an item is either acked or nacked, once")
+	assert.Equal(t, int64(1), cnv.Errors().Count("four", errFour))
+}
+
+// TestAcks_LoopMode verifies that items of a loop-mode source are acked once Collect() has them on its channel.
+func TestAcks_LoopMode(t *testing.T) {
+	src := newAckingSource(SourceFromSeq("src", upTo(100)))
+
+	cnv, _ := NewConveyor("loop_acks", 10)
+	cnv.EnableAcks().EnableBatching(8, time.Millisecond)
+	require.NoError(t, AddSource[int](cnv, src, WorkerModeLoop))
+	require.NoError(t, AddOperation[int, int](cnv, OperationFunc("double", 2, func(ctx CnvContext, in int) (int, error) {
+		return 2 * in, nil
+	}), WorkerModeTransaction))
+
+	results, err := Collect[int](context.Background(), cnv)
+	require.NoError(t, err)
+	assert.Len(t, results, 100)
+	assert.Len(t, src.acked(), 100)
+	assert.Empty(t, src.nacks)
+}
+
+// TestAcks_Dropped verifies that items dropped by an edge are nacked.
+func TestAcks_Dropped(t *testing.T) {
+	release := make(chan struct{})
+	src := newAckingSource(SourceFromSeq("src", upTo(100)))
+
+	cnv, _ := NewConveyor("dropped_acks", 10)
+	cnv.EnableAcks().SetEdge("snk", EdgeConfig{Buffer: 4, Overflow: OverflowDropNewest})
+	require.NoError(t, AddSource[int](cnv, src, WorkerModeLoop))
+	require.NoError(t, AddSink[int](cnv, SinkFunc("snk", 1, func(ctx CnvContext, in int) error {
+		<-release
+		return nil
+	}), WorkerModeTransaction))
+
+	done := make(chan error, 1)
+	go func() { done <- cnv.Start() }()
+	require.Eventually(t, func() bool { return cnv.Drops().Count("snk") == 94 }, time.Second, time.Millisecond)
+	close(release)
+	require.NoError(t, <-done)
+
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5}, src.acked(), "the items in the buffer, the one being processed, and the one waiting for a worker")
+	assert.Len(t, src.nacks, 94)
+	for _, err := range src.nacks {
+		assert.ErrorIs(t, err, ErrItemDropped)
+	}
+}
+
+// TestAcks_LoopModeSink verifies that a sink in loop mode is refused, as it can't tell when it's done with an item.
+func TestAcks_LoopModeSink(t *testing.T) {
+	cnv, _ := NewConveyor("loop_sink_acks", 10)
+	cnv.EnableAcks()
+	require.NoError(t, AddSource[int](cnv, newAckingSource(SourceFromSeq("src", upTo(10))), WorkerModeLoop))
+	err := AddSink[int](cnv, SinkLoopFunc("snk", 1, func(ctx CnvContext, in <-chan int) error {
+		for range in {
+		}
+		return nil
+	}), WorkerModeLoop)
+	assert.ErrorIs(t, err, ErrTrackedLoopMode)
+}
+
+// TestAcks_NotAcknowledged verifies that the source must implement Acknowledged.
+func TestAcks_NotAcknowledged(t *testing.T) {
+	cnv, _ := NewConveyor("not_acknowledged", 10)
+	cnv.EnableAcks()
+	assert.ErrorIs(t, AddSource[int](cnv, SourceFromSeq("src", upTo(10)), WorkerModeLoop), ErrNotAcknowledged)
+}
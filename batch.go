@@ -0,0 +1,154 @@
+package conveyor
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultBatchSize is the number of items in a chunk, if EnableBatching() is given a size <= 0
+	DefaultBatchSize = 64
+	// DefaultBatchFlushInterval is how long an incomplete chunk may wait, if EnableBatching() is given an interval <= 0
+	DefaultBatchFlushInterval = 10 * time.Millisecond
+)
+
+// batchConfig is the micro-batched transport used between the workers of a conveyor
+type batchConfig struct {
+	size  int
+	flush time.Duration
+}
+
+// batchedWorker is implemented by workers that can exchange chunks of items with each other
+type batchedWorker interface {
+	setBatching(batch *batchConfig)
+}
+
+// EnableBatching makes the conveyor's stages exchange chunks of up to size items, rather than single items,
+// over its bufferLen-sized channels. Executors are unchanged: they still get one item at a time.
+// It saves most of the channel operations of pipelines moving many small items, at the cost of latency:
+// an incomplete chunk waits for at most flushInterval before it's sent.
+//
+// In transaction mode, a worker goes through a whole chunk, calling Execute() for each item in turn,
+// so Count() is the number of chunks processed at the same time.
+// Will have no effect, once you add your first node
+func (cnv *Conveyor) EnableBatching(size int, flushInterval time.Duration) *Conveyor {
+	if !cnv.openForConfigChange {
+		return cnv
+	}
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = DefaultBatchFlushInterval
+	}
+	cnv.batch = &batchConfig{size: size, flush: flushInterval}
+	return cnv
+}
+
+// setBatching sets the transport of the worker's channels, before they are created. nil means one item at a time.
+func (wp *WPool) setBatching(batch *batchConfig) {
+	wp.batch = batch
+}
+
+// relay runs f in a new goroutine, waited for by waitRelays()
+func (wp *WPool) relay(f func()) {
+	wp.relays.Add(1)
+	wp.spawn(func() {
+		defer wp.relays.Done()
+		f()
+	})
+}
+
+// waitRelays waits for every goroutine started with relay() to return
+func (wp *WPool) waitRelays() {
+	wp.relays.Wait()
+}
+
+// chunker collects items into chunks, and sends each one to out once it's full, or flush has elapsed
+// since its first item. Chunks are sent in order, and it's safe to add items from several goroutines.
+type chunker[T any] struct {
+	size int
+	out  chan<- []T
+	done <-chan struct{} // once done, chunks are dropped, rather than sent
+
+	mu     sync.Mutex
+	buf    []T
+	timer  *time.Timer
+	flush  time.Duration
+	closed bool
+}
+
+func newChunker[T any](batch *batchConfig, out chan<- []T, done <-chan struct{}) *chunker[T] {
+	c := &chunker[T]{size: batch.size, out: out, done: done, flush: batch.flush}
+	c.timer = time.AfterFunc(batch.flush, c.flushTimer)
+	c.timer.Stop()
+	return c
+}
+
+// add appends item to the current chunk, and sends it if it's full
+func (c *chunker[T]) add(item T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.buf == nil {
+		c.buf = make([]T, 0, c.size)
+		c.timer.Reset(c.flush)
+	}
+	c.buf = append(c.buf, item)
+	if len(c.buf) >= c.size {
+		c.sendLocked()
+	}
+}
+
+// flushTimer sends the current chunk, however many items it has
+func (c *chunker[T]) flushTimer() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.sendLocked()
+	}
+}
+
+// close sends the last chunk, and closes out. Nothing can be added afterwards.
+func (c *chunker[T]) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sendLocked()
+	c.closed = true
+	close(c.out)
+}
+
+func (c *chunker[T]) sendLocked() {
+	c.timer.Stop()
+	if len(c.buf) == 0 {
+		return
+	}
+	chunk := c.buf
+	c.buf = nil
+
+	select {
+	case <-c.done:
+	case c.out <- chunk:
+	}
+}
+
+// chunkItems reads items from in until it's closed, and sends them to out in chunks. It closes out once done.
+func chunkItems[T any](batch *batchConfig, in <-chan T, out chan<- []T, done <-chan struct{}) {
+	c := newChunker(batch, out, done)
+	for v := range in {
+		c.add(v)
+	}
+	c.close()
+}
+
+// unchunkItems forwards the items of every chunk read from in to out, and closes out once in is closed
+func unchunkItems[T any](in <-chan []T, out chan<- T) {
+	for chunk := range in {
+		for _, v := range chunk {
+			out <- v
+		}
+	}
+	close(out)
+}
@@ -0,0 +1,147 @@
+package conveyor
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// upTo yields 0, 1, 2... n-1
+func upTo(n int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := range n {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+// TestBatching_BothModes verifies that every item goes through a batched pipeline, whatever the worker mode.
+func TestBatching_BothModes(t *testing.T) {
+	errOdd := errors.New("odd")
+
+	for _, mode := range []WorkerMode{WorkerModeTransaction, WorkerModeLoop} {
+		cnv, _ := NewConveyor("batched", 4)
+		cnv.EnableBatching(8, time.Millisecond)
+		require.NoError(t, AddSource[int](cnv, SourceFromSeq("src", upTo(100)), mode))
+		require.NoError(t, AddOperation[int, int](cnv, OperationFunc("evens", 3, func(ctx CnvContext, in int) (int, error) {
+			if in%2 == 1 {
+				return 0, errOdd
+			}
+			return in, nil
+		}), mode))
+
+		results, err := Collect[int](context.Background(), cnv)
+		require.NoError(t, err)
+		assert.Len(t, results, 50, "mode %v", mode)
+		assert.Equal(t, int64(50), cnv.Errors().Count("evens", errOdd), "mode %v", mode)
+	}
+}
+
+// TestBatching_Joint verifies that a joint relays chunks to every branch.
+func TestBatching_Joint(t *testing.T) {
+	var sum1, sum2 atomic.Int64
+	sum := func(total *atomic.Int64) SinkExecutor[int] {
+		return SinkFunc("sum", 2, func(ctx CnvContext, in int) error {
+			total.Add(int64(in))
+			return nil
+		})
+	}
+
+	cnv, _ := NewConveyor("batched_joint", 4)
+	cnv.EnableBatching(16, time.Millisecond).EnableProgress(time.Second)
+	require.NoError(t, AddSource[int](cnv, SourceFromSeq("src", upTo(100)), WorkerModeTransaction))
+	joint, _ := NewReplicateJoint[int]("replicate", 2)
+	require.NoError(t, AddJointAfterNode[int, int](cnv, joint))
+	require.NoError(t, AddSinkAfterJoint[int](cnv, sum(&sum1), WorkerModeTransaction))
+	require.NoError(t, AddSinkAfterJoint[int](cnv, sum(&sum2), WorkerModeLoop))
+
+	require.NoError(t, startWithin(t, cnv, time.Second))
+	assert.Equal(t, int64(4950), sum1.Load())
+	assert.Equal(t, int64(4950), sum2.Load())
+	assert.Equal(t, int64(100), delivered(cnv.sinks))
+}
+
+// TestBatching_FlushInterval verifies that an incomplete chunk doesn't wait for more items than flushInterval.
+func TestBatching_FlushInterval(t *testing.T) {
+	release := make(chan struct{})
+	received := make(chan int, 3)
+
+	cnv, _ := NewConveyor("batched_flush", 4)
+	cnv.EnableBatching(64, 5*time.Millisecond)
+	require.NoError(t, AddSource[int](cnv, SourceLoopFunc("src", 1, func(ctx CnvContext, out chan<- int) error {
+		for i := 0; i < 3; i++ {
+			out <- i
+		}
+		<-release
+		return nil
+	}), WorkerModeLoop))
+	require.NoError(t, AddSink[int](cnv, SinkFunc("snk", 1, func(ctx CnvContext, in int) error {
+		received <- in
+		return nil
+	}), WorkerModeTransaction))
+
+	done := make(chan error, 1)
+	go func() { done <- cnv.Start() }()
+
+	// The source is still running, so only the timer can have sent the chunk
+	for i := 0; i < 3; i++ {
+		select {
+		case v := <-received:
+			assert.Equal(t, i, v)
+		case <-time.After(time.Second):
+			t.Fatal("incomplete chunk wasn't flushed")
+		}
+	}
+	close(release)
+	require.NoError(t, <-done)
+}
+
+// TestBatching_Stop verifies that batched workers and their relays don't block once the conveyor is stopped.
+func TestBatching_Stop(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+
+	cnv, _ := NewConveyor("batched_stop", 1)
+	cnv.EnableBatching(4, time.Millisecond).EnableLeakCheck(time.Second)
+	src := &tickingSource{ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"}}
+	require.NoError(t, AddSource[int](cnv, src, WorkerModeLoop))
+	require.NoError(t, AddOperation[int, int](cnv, &loopDoubleOp{ConcreteOperationExecutor[int, int]{Name: "op"}}, WorkerModeLoop))
+	require.NoError(t, AddSink[int](cnv, &slowCountingSink{ConcreteSinkExecutor: ConcreteSinkExecutor[int]{Name: "snk"}}, WorkerModeTransaction))
+
+	done := make(chan error, 1)
+	go func() { done <- cnv.Start() }()
+	require.Eventually(t, func() bool { return src.emitted.Load() >= 10 }, time.Second, time.Millisecond)
+	cnv.Stop()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Start() didn't return after Stop()")
+	}
+	expectNoGoroutinesAbove(t, baseline)
+}
+
+// TestChunker_KeepsOrder verifies that chunks are sent in order, full or flushed, and that close() sends the last one.
+func TestChunker_KeepsOrder(t *testing.T) {
+	out := make(chan []int, 10)
+	c := newChunker(&batchConfig{size: 3, flush: time.Hour}, out, nil)
+	for i := 0; i < 7; i++ {
+		c.add(i)
+	}
+	c.close()
+
+	var chunks [][]int
+	for chunk := range out {
+		chunks = append(chunks, chunk)
+	}
+	assert.Equal(t, [][]int{{0, 1, 2}, {3, 4, 5}, {6}}, chunks)
+}
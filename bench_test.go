@@ -0,0 +1,129 @@
+package conveyor
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+// benchPipeline runs b.N ints through source → operation → operation → sink, all in the given mode,
+// one at a time or in chunks
+func benchPipeline(b *testing.B, mode WorkerMode, batched bool) {
+	b.Helper()
+	var sum atomic.Int64
+
+	cnv, _ := NewConveyor("bench", 100)
+	if batched {
+		cnv.EnableBatching(DefaultBatchSize, DefaultBatchFlushInterval)
+	}
+	switch mode {
+	case WorkerModeLoop:
+		MustAddSource[int](cnv, SourceLoopFunc("src", 1, func(ctx CnvContext, out chan<- int) error {
+			for i := 0; i < b.N; i++ {
+				out <- i
+			}
+			return nil
+		}), mode)
+		for _, name := range []string{"inc", "double"} {
+			MustAddOperation[int, int](cnv, OperationLoopFunc(name, 1, func(ctx CnvContext, in <-chan int, out chan<- int) error {
+				for v := range in {
+					out <- v + 1
+				}
+				return nil
+			}), mode)
+		}
+		MustAddSink[int](cnv, SinkLoopFunc("sum", 1, func(ctx CnvContext, in <-chan int) error {
+			for v := range in {
+				sum.Add(int64(v))
+			}
+			return nil
+		}), mode)
+
+	case WorkerModeTransaction:
+		var next atomic.Int64
+		MustAddSource[int](cnv, SourceFunc("src", 4, func(ctx CnvContext) (int, error) {
+			if v := next.Add(1); v <= int64(b.N) {
+				return int(v), nil
+			}
+			return 0, ErrSourceExhausted
+		}), mode)
+		for _, name := range []string{"inc", "double"} {
+			MustAddOperation[int, int](cnv, OperationFunc(name, 4, func(ctx CnvContext, in int) (int, error) {
+				return in + 1, nil
+			}), mode)
+		}
+		MustAddSink[int](cnv, SinkFunc("sum", 4, func(ctx CnvContext, in int) error {
+			sum.Add(int64(in))
+			return nil
+		}), mode)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	if err := cnv.Start(); err != nil {
+		b.Fatal(err)
+	}
+}
+
+// BenchmarkPipeline_Loop measures the throughput of a pipeline whose nodes all run in loop mode,
+// where items go straight from one executor's channel to the next one's.
+func BenchmarkPipeline_Loop(b *testing.B) {
+	benchPipeline(b, WorkerModeLoop, false)
+}
+
+// BenchmarkPipeline_LoopBatched is BenchmarkPipeline_Loop, with stages exchanging chunks of items.
+func BenchmarkPipeline_LoopBatched(b *testing.B) {
+	benchPipeline(b, WorkerModeLoop, true)
+}
+
+// BenchmarkPipeline_Transaction measures the throughput of a pipeline whose nodes all run in transaction mode.
+func BenchmarkPipeline_Transaction(b *testing.B) {
+	benchPipeline(b, WorkerModeTransaction, false)
+}
+
+// BenchmarkPipeline_TransactionBatched is BenchmarkPipeline_Transaction, with stages exchanging chunks of items.
+func BenchmarkPipeline_TransactionBatched(b *testing.B) {
+	benchPipeline(b, WorkerModeTransaction, true)
+}
+
+// BenchmarkPipeline_Joint measures the throughput of a loop-mode source replicated to two loop-mode sinks.
+func BenchmarkPipeline_Joint(b *testing.B) {
+	benchJoint(b, false)
+}
+
+// BenchmarkPipeline_JointBatched is BenchmarkPipeline_Joint, with stages exchanging chunks of items.
+func BenchmarkPipeline_JointBatched(b *testing.B) {
+	benchJoint(b, true)
+}
+
+// benchJoint runs b.N ints from a loop-mode source replicated to two loop-mode sinks
+func benchJoint(b *testing.B, batched bool) {
+	b.Helper()
+	var sum atomic.Int64
+	count := func(ctx CnvContext, in <-chan int) error {
+		for v := range in {
+			sum.Add(int64(v))
+		}
+		return nil
+	}
+
+	cnv, _ := NewConveyor("bench_joint", 100)
+	if batched {
+		cnv.EnableBatching(DefaultBatchSize, DefaultBatchFlushInterval)
+	}
+	MustAddSource[int](cnv, SourceLoopFunc("src", 1, func(ctx CnvContext, out chan<- int) error {
+		for i := 0; i < b.N; i++ {
+			out <- i
+		}
+		return nil
+	}), WorkerModeLoop)
+	joint, _ := NewReplicateJoint[int]("replicate", 2)
+	MustAddJointAfterNode[int, int](cnv, joint)
+	MustAddSinkAfterJoint[int](cnv, SinkLoopFunc("sum1", 1, count), WorkerModeLoop)
+	MustAddSinkAfterJoint[int](cnv, SinkLoopFunc("sum2", 1, count), WorkerModeLoop)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	if err := cnv.Start(); err != nil {
+		b.Fatal(err)
+	}
+}
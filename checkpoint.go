@@ -0,0 +1,301 @@
+package conveyor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultCheckpointInterval is the interval of EnableCheckpoints(), if it's <= 0
+const DefaultCheckpointInterval = 10 * time.Second
+
+// Checkpointable is implemented by sources that can save how far they got, and resume from there.
+// Checkpoint() is only ever called between two Execute() calls, and Restore() before the first one.
+type Checkpointable interface {
+	Checkpoint() ([]byte, error)
+	Restore(checkpoint []byte) error
+}
+
+// CheckpointStore keeps the last checkpoint of each conveyor, by ID
+type CheckpointStore interface {
+	// Save replaces the checkpoint of the conveyor with the given id
+	Save(id string, checkpoint []byte) error
+	// Load returns the last checkpoint saved for id, or nil if there is none
+	Load(id string) ([]byte, error)
+}
+
+// FileCheckpointStore is a CheckpointStore keeping a file per conveyor ID in a directory.
+// Files are replaced atomically, so a crash never leaves a partial checkpoint behind.
+type FileCheckpointStore struct {
+	dir string
+}
+
+// NewFileCheckpointStore creates a FileCheckpointStore in dir, creating the directory if needed
+func NewFileCheckpointStore(dir string) (*FileCheckpointStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating checkpoint directory '%s': %w", dir, err)
+	}
+	return &FileCheckpointStore{dir: dir}, nil
+}
+
+func (fcs *FileCheckpointStore) path(id string) string {
+	return filepath.Join(fcs.dir, url.PathEscape(id)+".checkpoint")
+}
+
+// Save replaces the checkpoint file of id
+func (fcs *FileCheckpointStore) Save(id string, checkpoint []byte) error {
+	return writeFileAtomic(fcs.path(id), checkpoint)
+}
+
+// Load reads the checkpoint file of id, if any
+func (fcs *FileCheckpointStore) Load(id string) ([]byte, error) {
+	data, err := os.ReadFile(fcs.path(id))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("reading checkpoint of '%s': %w", id, err)
+	}
+
+	var checkpoint []byte
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("decoding checkpoint of '%s': %w", id, err)
+	}
+	return checkpoint, nil
+}
+
+// checkpointConfig is set by EnableCheckpoints()
+type checkpointConfig struct {
+	store    CheckpointStore
+	interval time.Duration
+}
+
+// EnableCheckpoints makes the conveyor save a checkpoint of its source in store, about every interval,
+// under the conveyor's ID. A checkpoint is only saved once every item the source had produced before it
+// went through all the sinks. A conveyor with the same SetID() resumes from the last one. An item that failed,
+// or was dropped, holds back the checkpoints after it, so that the next run processes it again.
+//
+// The source must implement Checkpointable, and run in WorkerModeTransaction, like the operations and the sinks:
+// items are tracked one by one, across joints too, which only ReplicateJoint supports. The sink of OutputChannel()
+// or Collect() is the exception, done with an item once it's on the channel.
+// Will have no effect, once you add your first node
+func (cnv *Conveyor) EnableCheckpoints(store CheckpointStore, interval time.Duration) *Conveyor {
+	if !cnv.openForConfigChange {
+		return cnv
+	}
+	if interval <= 0 {
+		interval = DefaultCheckpointInterval
+	}
+	cnv.checkpoints = &checkpointConfig{store: store, interval: interval}
+	return cnv
+}
+
+// pendingCheckpoint is a checkpoint taken once the source had produced upTo items, to save once they're all done
+type pendingCheckpoint struct {
+	upTo  uint64
+	state []byte
+}
+
+// itemTracker numbers the items produced by a tracked source. With checkpoints, it saves them
+// as the low watermark, below which every item is done, goes past them.
+type itemTracker struct {
+	id    string
+	src   Checkpointable // nil without checkpoints
+	store CheckpointStore
+
+	// emitMu is held while the source produces an item, so that checkpoints fall between two of them
+	emitMu    sync.Mutex
+	next      uint64
+	interval  time.Duration
+	lastAt    time.Time
+	exhausted bool
+
+	mu      sync.Mutex
+	low     uint64              // every item numbered below low is done
+	done    map[uint64]struct{} // items done, above low
+	pending []pendingCheckpoint
+
+	// A failed item is never done, so that the next run processes it again: the low watermark stays below it
+	held   bool
+	heldAt uint64 // the first item that failed, if held
+
+	// saveMu guards the checkpoint to save next. A single goroutine saves at a time, outside of mu,
+	// so that others only hand it the latest checkpoint rather than wait for the store.
+	saveMu sync.Mutex
+	toSave *pendingCheckpoint
+	saving bool
+	latest uint64 // upTo of the latest checkpoint handed to save, older ones coming late are skipped
+}
+
+// newItemTracker restores src from the last checkpoint saved for id, and returns the tracker of its items
+func newItemTracker(id string, src Checkpointable, cfg *checkpointConfig) (*itemTracker, error) {
+	checkpoint, err := cfg.store.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint != nil {
+		if err := src.Restore(checkpoint); err != nil {
+			return nil, fmt.Errorf("restoring checkpoint of '%s': %w", id, err)
+		}
+	}
+	return &itemTracker{
+		id:       id,
+		src:      src,
+		store:    cfg.store,
+		interval: cfg.interval,
+		lastAt:   time.Now(),
+		done:     make(map[uint64]struct{}),
+	}, nil
+}
+
+// checkpointing returns true if the tracker saves checkpoints, rather than only tracks items for acks
+func (tr *itemTracker) checkpointing() bool {
+	return tr.src != nil
+}
+
+// emit returns the token of a new item. Call it with emitMu held, if checkpointing.
+func (tr *itemTracker) emit() *token {
+	tok := &token{tracker: tr}
+	tok.refs.Store(1)
+	if !tr.checkpointing() {
+		return tok
+	}
+
+	tok.seq = tr.next
+	tr.next++
+	if time.Since(tr.lastAt) >= tr.interval {
+		tr.snapshot()
+	}
+	return tok
+}
+
+// snapshot takes a checkpoint of the source, as it is after the items emitted so far. Call it with emitMu held.
+func (tr *itemTracker) snapshot() {
+	tr.lastAt = time.Now()
+	state, err := tr.src.Checkpoint()
+	if err != nil {
+		log.Printf("Conveyor:[%s] Failed to take a checkpoint Error:[%v]\n", tr.id, err)
+		return
+	}
+
+	tr.mu.Lock()
+	if tr.held {
+		// The watermark won't go past the failed item, nor this checkpoint
+		tr.mu.Unlock()
+		return
+	}
+	tr.pending = append(tr.pending, pendingCheckpoint{upTo: tr.next, state: state})
+	cp := tr.committedLocked()
+	tr.mu.Unlock()
+	tr.save(cp)
+}
+
+// complete records that the item numbered seq is done, and saves the checkpoints the low watermark went past.
+// If the item failed, the low watermark stops below it instead.
+func (tr *itemTracker) complete(seq uint64, failed bool) {
+	if !tr.checkpointing() {
+		return
+	}
+	tr.mu.Lock()
+	if failed {
+		tr.hold(seq)
+		tr.mu.Unlock()
+		return
+	}
+	if tr.held && seq > tr.heldAt {
+		// Past the failed item, it doesn't matter anymore
+		tr.mu.Unlock()
+		return
+	}
+	if seq != tr.low {
+		tr.done[seq] = struct{}{}
+		tr.mu.Unlock()
+		return
+	}
+	tr.low++
+	for {
+		if _, ok := tr.done[tr.low]; !ok {
+			break
+		}
+		delete(tr.done, tr.low)
+		tr.low++
+	}
+	cp := tr.committedLocked()
+	tr.mu.Unlock()
+	tr.save(cp)
+}
+
+// hold stops the low watermark below the failed item numbered seq, forgetting what's past it. Call it with mu held.
+func (tr *itemTracker) hold(seq uint64) {
+	if tr.held && seq >= tr.heldAt {
+		return
+	}
+	tr.held, tr.heldAt = true, seq
+	for done := range tr.done {
+		if done > seq {
+			delete(tr.done, done)
+		}
+	}
+	for i, cp := range tr.pending {
+		if cp.upTo > seq {
+			tr.pending = tr.pending[:i]
+			break
+		}
+	}
+}
+
+// committedLocked returns the latest checkpoint whose items are all done, if any, and forgets the older ones
+func (tr *itemTracker) committedLocked() *pendingCheckpoint {
+	last := -1
+	for i, cp := range tr.pending {
+		if cp.upTo > tr.low {
+			break
+		}
+		last = i
+	}
+	if last < 0 {
+		return nil
+	}
+
+	cp := tr.pending[last]
+	tr.pending = tr.pending[last+1:]
+	return &cp
+}
+
+// save stores cp, or hands it over to the goroutine already saving, which then saves the latest one it got
+func (tr *itemTracker) save(cp *pendingCheckpoint) {
+	if cp == nil {
+		return
+	}
+	tr.saveMu.Lock()
+	if cp.upTo < tr.latest {
+		tr.saveMu.Unlock()
+		return
+	}
+	tr.latest = cp.upTo
+	tr.toSave = cp
+	if tr.saving {
+		tr.saveMu.Unlock()
+		return
+	}
+
+	tr.saving = true
+	for tr.toSave != nil {
+		next := tr.toSave
+		tr.toSave = nil
+		tr.saveMu.Unlock()
+		if err := tr.store.Save(tr.id, next.state); err != nil {
+			log.Printf("Conveyor:[%s] Failed to save a checkpoint Error:[%v]\n", tr.id, err)
+		}
+		tr.saveMu.Lock()
+	}
+	tr.saving = false
+	tr.saveMu.Unlock()
+}
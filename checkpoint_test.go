@@ -0,0 +1,253 @@
+package conveyor
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resumableSource produces 0, 1, 2... up to n, and checkpoints the next number to produce
+type resumableSource struct {
+	ConcreteSourceExecutor[int]
+	n    int
+	next int
+}
+
+func (src *resumableSource) Execute(ctx CnvContext) (int, error) {
+	if src.next >= src.n {
+		return 0, ErrSourceExhausted
+	}
+	src.next++
+	return src.next - 1, nil
+}
+
+func (src *resumableSource) Checkpoint() ([]byte, error) {
+	return []byte(strconv.Itoa(src.next)), nil
+}
+
+func (src *resumableSource) Restore(checkpoint []byte) error {
+	next, err := strconv.Atoi(string(checkpoint))
+	src.next = next
+	return err
+}
+
+// memoryStore is a CheckpointStore keeping every checkpoint saved
+type memoryStore struct {
+	mu    sync.Mutex
+	saved []string
+}
+
+func (ms *memoryStore) Save(id string, checkpoint []byte) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.saved = append(ms.saved, string(checkpoint))
+	return nil
+}
+
+func (ms *memoryStore) Load(id string) ([]byte, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if len(ms.saved) == 0 {
+		return nil, nil
+	}
+	return []byte(ms.saved[len(ms.saved)-1]), nil
+}
+
+// crashingStore stops saving checkpoints once the process has "crashed"
+type crashingStore struct {
+	*FileCheckpointStore
+	crashed atomic.Bool
+}
+
+func (cs *crashingStore) Save(id string, checkpoint []byte) error {
+	if cs.crashed.Load() {
+		return errors.New("crashed")
+	}
+	return cs.FileCheckpointStore.Save(id, checkpoint)
+}
+
+// TestCheckpoint_Resume verifies that a run killed halfway is resumed by the next one with the same ID,
+// without losing any item.
+func TestCheckpoint_Resume(t *testing.T) {
+	files, err := NewFileCheckpointStore(t.TempDir())
+	require.NoError(t, err)
+	store := &crashingStore{FileCheckpointStore: files}
+
+	var mu sync.Mutex
+	seen := make(map[int]int)
+	run := func(stopAfter int64) *Conveyor {
+		var received atomic.Int64
+		cnv, _ := NewConveyor("resumable", 10)
+		cnv.SetID("job-1").EnableCheckpoints(store, time.Millisecond)
+		require.NoError(t, AddSource[int](cnv, &resumableSource{ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"}, n: 1000}, WorkerModeTransaction))
+		require.NoError(t, AddSink[int](cnv, SinkFunc("snk", 4, func(ctx CnvContext, in int) error {
+			if received.Add(1) > stopAfter {
+				store.crashed.Store(true) // Nothing is saved from now on, as if the process had died with items in flight
+				return nil
+			}
+			time.Sleep(20 * time.Microsecond)
+			mu.Lock()
+			seen[in]++
+			mu.Unlock()
+			return nil
+		}), WorkerModeTransaction))
+
+		done := make(chan error, 1)
+		go func() { done <- cnv.Start() }()
+		if stopAfter < 1000 {
+			require.Eventually(t, func() bool { return received.Load() > stopAfter }, 5*time.Second, time.Millisecond)
+			cnv.Stop()
+		}
+		require.NoError(t, <-done)
+		return cnv
+	}
+
+	run(400)
+	checkpoint, err := store.Load("job-1")
+	require.NoError(t, err)
+	resumeAt, _ := strconv.Atoi(string(checkpoint))
+	assert.Positive(t, resumeAt)
+	assert.LessOrEqual(t, resumeAt, 400, "the checkpoint can't be ahead of the items done")
+
+	store.crashed.Store(false)
+	run(1000)
+	for i := 0; i < 1000; i++ {
+		assert.Positive(t, seen[i], "item %d lost", i)
+	}
+	checkpoint, _ = store.Load("job-1")
+	assert.Equal(t, "1000", string(checkpoint), "the last checkpoint is saved once every item is done")
+}
+
+// TestCheckpoint_Watermark verifies that a checkpoint is only saved once every item before it is done,
+// whatever order they're done in.
+func TestCheckpoint_Watermark(t *testing.T) {
+	store := &memoryStore{}
+	src := &resumableSource{n: 10}
+	tr, err := newItemTracker("wm", src, &checkpointConfig{store: store, interval: time.Hour})
+	require.NoError(t, err)
+
+	var toks []*token
+	for i := 0; i < 3; i++ {
+		_, _ = src.Execute(nil)
+		toks = append(toks, tr.emit())
+	}
+	tr.snapshot() // "3"
+	_, _ = src.Execute(nil)
+	toks = append(toks, tr.emit())
+	tr.snapshot() // "4"
+
+	toks[1].fork(1)
+	toks[2].done()
+	toks[1].done()
+	toks[0].done()
+	assert.Empty(t, store.saved, "item 1 has a copy still in flight")
+
+	toks[1].done()
+	assert.Equal(t, []string{"3"}, store.saved)
+	toks[3].done()
+	assert.Equal(t, []string{"3", "4"}, store.saved)
+
+	for i := 0; i < 3; i++ {
+		_, _ = src.Execute(nil)
+		toks = append(toks, tr.emit())
+	}
+	tr.snapshot() // "7"
+	toks[5].fail(errors.New("failed"))
+	toks[4].done()
+	toks[5].done()
+	toks[6].done()
+	tr.snapshot() // held back
+	assert.Equal(t, []string{"3", "4"}, store.saved, "item 5 failed")
+	assert.Equal(t, uint64(5), tr.low)
+	assert.Empty(t, tr.pending)
+}
+
+// TestCheckpoint_Joint verifies that items are done once every branch of a ReplicateJoint is.
+func TestCheckpoint_Joint(t *testing.T) {
+	store := &memoryStore{}
+
+	cnv, _ := NewConveyor("replicated", 10)
+	cnv.EnableCheckpoints(store, time.Millisecond)
+	require.NoError(t, AddSource[int](cnv, &resumableSource{ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"}, n: 500}, WorkerModeTransaction))
+	joint, _ := NewReplicateJoint[int]("replicate", 2)
+	require.NoError(t, AddJointAfterNode[int, int](cnv, joint))
+	require.NoError(t, AddSinkAfterJoint[int](cnv, SinkFunc("slow", 1, func(ctx CnvContext, in int) error {
+		time.Sleep(10 * time.Microsecond)
+		return nil
+	}), WorkerModeTransaction))
+	require.NoError(t, AddOperationAfterJoint[int, int](cnv, OperationFunc("copy", 1, func(ctx CnvContext, in int) (int, error) {
+		return in, nil
+	}), WorkerModeTransaction))
+	out, err := Collect[int](context.Background(), cnv)
+	require.NoError(t, err)
+
+	assert.Len(t, out, 500)
+	assert.Equal(t, "500", store.saved[len(store.saved)-1])
+	assert.Equal(t, uint64(500), cnv.items.low)
+}
+
+// TestCheckpoint_Failed verifies that an item that failed holds the checkpoints back, so that the next run processes it again.
+func TestCheckpoint_Failed(t *testing.T) {
+	store := &memoryStore{}
+	errFailed := errors.New("failed")
+
+	var processed []int
+	run := func(failing int) {
+		cnv, _ := NewConveyor("failed", 10)
+		cnv.SetID("job-2").EnableCheckpoints(store, time.Millisecond)
+		require.NoError(t, AddSource[int](cnv, &resumableSource{ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"}, n: 500}, WorkerModeTransaction))
+		require.NoError(t, AddSink[int](cnv, SinkFunc("snk", 1, func(ctx CnvContext, in int) error {
+			if in == failing {
+				return errFailed
+			}
+			processed = append(processed, in)
+			return nil
+		}), WorkerModeTransaction))
+		require.NoError(t, cnv.Start())
+	}
+
+	run(300)
+	assert.NotContains(t, processed, 300)
+	checkpoint, _ := store.Load("job-2")
+	resumeAt, _ := strconv.Atoi(string(checkpoint))
+	assert.LessOrEqual(t, resumeAt, 300, "no checkpoint goes past the failed item")
+
+	processed = nil
+	run(-1)
+	assert.Contains(t, processed, 300)
+	assert.Len(t, processed, 500-resumeAt)
+	checkpoint, _ = store.Load("job-2")
+	assert.Equal(t, "500", string(checkpoint))
+}
+
+// TestCheckpoint_Unsupported verifies what can't be tracked is refused when the conveyor is built.
+func TestCheckpoint_Unsupported(t *testing.T) {
+	newConveyor := func() *Conveyor {
+		cnv, _ := NewConveyor("unsupported", 10)
+		return cnv.EnableCheckpoints(&memoryStore{}, 0)
+	}
+	resumable := func() *resumableSource {
+		return &resumableSource{ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"}, n: 10}
+	}
+
+	cnv := newConveyor()
+	assert.ErrorIs(t, AddSource[int](cnv, SourceFromSeq("seq", upTo(10)), WorkerModeTransaction), ErrNotCheckpointable)
+	assert.ErrorIs(t, AddSource[int](cnv, resumable(), WorkerModeLoop), ErrTrackedLoopMode)
+
+	cnv = newConveyor()
+	require.NoError(t, AddSource[int](cnv, resumable(), WorkerModeTransaction))
+	assert.ErrorIs(t, AddOperation[int, int](cnv, OperationFunc("op", 1, func(ctx CnvContext, in int) (int, error) {
+		return in, nil
+	}), WorkerModeLoop), ErrTrackedLoopMode)
+
+	cnv = newConveyor()
+	require.NoError(t, AddSource[int](cnv, resumable(), WorkerModeTransaction))
+	assert.ErrorIs(t, AddJointAfterNode[int, int](cnv, &ConcreteJointExecutor[int, int]{Name: "joint"}), ErrTrackedJoint)
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"log"
 	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/satori/go.uuid"
@@ -25,11 +27,32 @@ type Conveyor struct {
 	needProgress bool
 	tickProgress time.Duration
 	bufferLen    int
+	batch        *batchConfig          // set by EnableBatching()
+	edges        map[string]EdgeConfig // set by SetEdge(), by the name of the stage they feed
 
 	progress         chan float64
-	duration         time.Duration
 	expectedDuration time.Duration
 	lifeCycle        LifeCycleHandler
+	tickLifeCycle    time.Duration
+
+	stateMu sync.Mutex // serialises state transitions, along with their LifeCycleHandler calls
+	state   string
+	stopped atomic.Bool // set by Stop(), so that Start() doesn't mark the final state itself
+
+	drainMu     sync.Mutex
+	draining    atomic.Bool        // set by Drain()
+	drainSource context.CancelFunc // stops the sources, once Start() has created their context
+
+	remoteKillInterval time.Duration // how often GetState() is polled for StateToKill, 0 to disable
+	remoteKillMode     StopMode
+
+	sizedSource Sized         // set when the source reports its total amount of work
+	sinks       []sinkCounter // sinks whose delivered counts drive item-based progress
+	tracker     progressTracker
+
+	progressMu      sync.Mutex
+	progressRunning bool          // set once updateProgress() has been launched
+	progressDone    chan struct{} // closed by updateProgress() once it has closed "progress"
 
 	workers []NodeWorker
 	joints  []JointWorker
@@ -39,10 +62,23 @@ type Conveyor struct {
 
 	lastNodeOutType  reflect.Type
 	lastJointOutType reflect.Type
+	branch           *branchEnd // left branch, once AddBranchSource() has started a right one to join it with
 
 	cleanupOnce sync.Once // To ensure that conveyor can't be cleaned up again
 
+	leakCheckGrace time.Duration // how long Start() waits for goroutines once the conveyor is done, 0 to wait forever
+
 	errorStats *ErrorStats
+	dropStats  *DropStats
+	caches     *CacheStatsByStage
+	stages     map[string]any  // executors by name, for Stage()
+	dropped    deliveryCounter // progress units of the items dropped by edges, or skipped by operations
+	spills     spillDirs       // temp directories of the edges spilling to disk
+
+	checkpoints *checkpointConfig // set by EnableCheckpoints()
+	acks        bool              // set by EnableAcks()
+	metadata    bool              // set by EnableMetadata()
+	items       *itemTracker      // tracks the items of the source, once it's added, with checkpoints, acks or metadata
 }
 
 // NewConveyor creates a new Conveyor instance, with all options set to default values/implementations
@@ -66,12 +102,16 @@ func NewConveyor(name string, bufferLen int) (*Conveyor, error) {
 
 	// Set lifeCycle to nil (default value)
 	cnv.lifeCycle = nil
+	cnv.tickLifeCycle = defaultLifeCycleSyncInterval
 
 	// Set needProgress to false by default
 	cnv.needProgress = false
 
 	// Initialize shared error statistics for this pipeline.
 	cnv.errorStats = &ErrorStats{}
+	cnv.dropStats = &DropStats{}
+	cnv.caches = &CacheStatsByStage{}
+	cnv.stages = make(map[string]any)
 
 	_ctx := &cnvContext{
 		Context: context.Background(),
@@ -80,6 +120,9 @@ func NewConveyor(name string, bufferLen int) (*Conveyor, error) {
 			logs:       make(chan Message, 100),
 			status:     make(chan string, 100),
 			errorStats: cnv.errorStats,
+			skipped:    &cnv.dropped,
+			closer:     &channelCloser{},
+			lastStatus: new(atomic.Pointer[string]),
 		},
 	}
 
@@ -134,6 +177,7 @@ func (cnv *Conveyor) EnableProgress(expectedDuration time.Duration) *Conveyor {
 
 	cnv.needProgress = true
 	cnv.progress = make(chan float64, 1)
+	cnv.progressDone = make(chan struct{})
 	cnv.tickProgress = time.Millisecond * 500
 
 	if expectedDuration == 0 {
@@ -144,7 +188,9 @@ func (cnv *Conveyor) EnableProgress(expectedDuration time.Duration) *Conveyor {
 	return cnv
 }
 
-// SetLifeCycleHandler sets the conveyor's LifeCycleHandler interface to a given implementation
+// SetLifeCycleHandler sets the conveyor's LifeCycleHandler interface to a given implementation.
+// Conveyor then marks its own state transitions: preparing & started from Start(),
+// toKill & killed from Stop(), finished on success, and internalError on failure or timeout.
 // Will have no effect, once you add your first node
 func (cnv *Conveyor) SetLifeCycleHandler(lch LifeCycleHandler) *Conveyor {
 	if !cnv.openForConfigChange {
@@ -154,6 +200,20 @@ func (cnv *Conveyor) SetLifeCycleHandler(lch LifeCycleHandler) *Conveyor {
 	return cnv
 }
 
+// SetLifeCycleSyncInterval sets how often the latest status message & progress
+// are mirrored into the LifeCycleHandler, while the conveyor runs. Default is 1 second.
+// Will have no effect, once you add your first node
+func (cnv *Conveyor) SetLifeCycleSyncInterval(interval time.Duration) *Conveyor {
+	if !cnv.openForConfigChange {
+		return cnv
+	}
+	if interval <= 0 {
+		interval = defaultLifeCycleSyncInterval
+	}
+	cnv.tickLifeCycle = interval
+	return cnv
+}
+
 // SetCustomContext sets the conveyor's CnvContext interface to a given implementation
 // Will have no effect, once you add your first node
 // This method must be called before you call "SetTimeout()"
@@ -201,7 +261,9 @@ func (cnv *Conveyor) Status() <-chan string {
 	return nil
 }
 
-// Progress returns a channel which is regularly updated with progress %
+// Progress returns a channel which is regularly updated with progress %.
+// If the source implements Sized, progress is the share of items that have reached
+// the sinks, otherwise it's estimated from the expected duration given to EnableProgress().
 func (cnv *Conveyor) Progress() <-chan float64 {
 	if cnv.needProgress {
 		return cnv.progress
@@ -209,6 +271,12 @@ func (cnv *Conveyor) Progress() <-chan float64 {
 	return nil
 }
 
+// ProgressReport returns the most recent progress computation, including the ETA.
+// It is the zero value until progress is enabled and the first tick has elapsed.
+func (cnv *Conveyor) ProgressReport() ProgressReport {
+	return cnv.tracker.report()
+}
+
 // GetLastWorker returns the last added worker, or error if conveyor is empty
 func (cnv *Conveyor) GetLastWorker() (NodeWorker, error) {
 	workerCount := len(cnv.workers)
@@ -222,15 +290,12 @@ func (cnv *Conveyor) GetLastWorker() (NodeWorker, error) {
 // AddSource adds a source node to the conveyor. It must be the first node added.
 // TOut is the type of data this source produces.
 //
-// The executor is wrapped into a type-erased adapter, a SourceWorkerPool is created
-// for it, and the pool is appended to the conveyor's worker list. After a successful
+// A SourceWorkerPool[TOut] is created for the executor, and the pool is appended to the conveyor's worker list. After a successful
 // call, cnv.lastNodeOutType is set to reflect.TypeFor[TOut]() so that the next
 // AddOperation or AddSink call can validate its input type at construction time.
 func AddSource[TOut any](cnv *Conveyor, exec SourceExecutor[TOut], mode WorkerMode) error {
-	wrapped := wrapSource[TOut](exec)
 	workerType := WorkerTypeSource
-
-	nodeWorker, err := newNodeWorker(wrapped, mode, workerType)
+	nodeWorker, err := sourceWorkerFor[TOut](cnv, exec, mode)
 	if err != nil {
 		return err
 	}
@@ -241,11 +306,23 @@ func AddSource[TOut any](cnv *Conveyor, exec SourceExecutor[TOut], mode WorkerMo
 		return addErr
 	}
 
+	if sized, ok := exec.(Sized); ok {
+		cnv.sizedSource = sized
+	}
+
+	cnv.stages[exec.GetName()] = exec
 	cnv.lastNodeOutType = reflect.TypeFor[TOut]()
 	cnv.lockConfig()
 	return nil
 }
 
+// Stage returns the executor added under name, if it's an E, e.g. the ReduceSink of a conveyor created by a PipelineSpec.
+// With several stages of the same name, it's the last one added.
+func Stage[E any](cnv *Conveyor, name string) (E, bool) {
+	exec, ok := cnv.stages[name].(E)
+	return exec, ok
+}
+
 // MustAddSource is like AddSource but panics if the node cannot be added.
 // Use this when a type mismatch or misconfiguration is a programmer error
 // that should be caught immediately during pipeline construction.
@@ -266,10 +343,13 @@ func AddOperation[TIn, TOut any](cnv *Conveyor, exec OperationExecutor[TIn, TOut
 		return fmt.Errorf("%w: expected input type %v but got %v", ErrTypeMismatch, cnv.lastNodeOutType, expectedIn)
 	}
 
-	wrapped := wrapOperation[TIn, TOut](exec)
-	workerType := WorkerTypeOperation
+	return addOperation[TIn, TOut](cnv, exec, mode)
+}
 
-	nodeWorker, err := newNodeWorker(wrapped, mode, workerType)
+// addOperation adds an operation node, once its input type has been validated
+func addOperation[TIn, TOut any](cnv *Conveyor, exec OperationExecutor[TIn, TOut], mode WorkerMode) error {
+	workerType := WorkerTypeOperation
+	nodeWorker, err := operationWorkerFor[TIn, TOut](cnv, exec, mode)
 	if err != nil {
 		return err
 	}
@@ -280,6 +360,8 @@ func AddOperation[TIn, TOut any](cnv *Conveyor, exec OperationExecutor[TIn, TOut
 		return addErr
 	}
 
+	cnv.caches.register(exec)
+	cnv.stages[exec.GetName()] = exec
 	cnv.lastNodeOutType = reflect.TypeFor[TOut]()
 	cnv.lockConfig()
 	return nil
@@ -303,10 +385,13 @@ func AddSink[TIn any](cnv *Conveyor, exec SinkExecutor[TIn], mode WorkerMode) er
 		return fmt.Errorf("%w: expected input type %v but got %v", ErrTypeMismatch, cnv.lastNodeOutType, expectedIn)
 	}
 
-	wrapped := wrapSink[TIn](exec)
-	workerType := WorkerTypeSink
+	return addSink[TIn](cnv, exec, mode)
+}
 
-	nodeWorker, err := newNodeWorker(wrapped, mode, workerType)
+// addSink adds a sink node, once its input type has been validated
+func addSink[TIn any](cnv *Conveyor, exec SinkExecutor[TIn], mode WorkerMode) error {
+	workerType := WorkerTypeSink
+	nodeWorker, err := sinkWorkerFor[TIn](cnv, exec, mode)
 	if err != nil {
 		return err
 	}
@@ -317,6 +402,8 @@ func AddSink[TIn any](cnv *Conveyor, exec SinkExecutor[TIn], mode WorkerMode) er
 		return addErr
 	}
 
+	cnv.sinks = append(cnv.sinks, nodeWorker)
+	cnv.stages[exec.GetName()] = exec
 	cnv.lastNodeOutType = nil // sinks produce no output
 	cnv.lockConfig()
 	return nil
@@ -344,13 +431,20 @@ func AddJointAfterNode[TIn, TOut any](cnv *Conveyor, exec JointExecutor[TIn, TOu
 		return fmt.Errorf("%w: expected input type %v but got %v", ErrTypeMismatch, cnv.lastNodeOutType, expectedIn)
 	}
 
-	nodeCount := len(cnv.workers)
-	if nodeCount == 0 {
+	if len(cnv.workers) == 0 {
 		return ErrNoNodesAvailable
 	}
 
-	wrapped := wrapJoint[TIn, TOut](exec)
-	jointWorker := NewJointWorkerPool(wrapped)
+	return addJointAfterNode[TIn, TOut](cnv, exec)
+}
+
+// addJointAfterNode adds a joint after the last node, once its input type has been validated
+func addJointAfterNode[TIn, TOut any](cnv *Conveyor, exec JointExecutor[TIn, TOut]) error {
+	nodeCount := len(cnv.workers)
+	jointWorker, err := jointWorkerFor[TIn, TOut](cnv, exec)
+	if err != nil {
+		return err
+	}
 
 	if addErr := cnv.AddJointWorker(jointWorker); addErr != nil {
 		fmt.Printf("Adding joint-%s after node to conveyor failed. Error:[%v]\n",
@@ -366,6 +460,7 @@ func AddJointAfterNode[TIn, TOut any](cnv *Conveyor, exec JointExecutor[TIn, TOu
 
 	// After a joint, nodes must be added via AddSinkAfterJoint / AddOperationAfterJoint,
 	// not through the linear AddOperation / AddSink path.
+	cnv.stages[exec.GetName()] = exec
 	cnv.lastNodeOutType = nil
 	cnv.lastJointOutType = reflect.TypeFor[TOut]()
 	cnv.lockConfig()
@@ -393,15 +488,17 @@ func AddSinkAfterJoint[TIn any](cnv *Conveyor, exec SinkExecutor[TIn], mode Work
 		return fmt.Errorf("%w: expected input type %v but got %v", ErrTypeMismatch, cnv.lastJointOutType, expectedIn)
 	}
 
-	jointCount := len(cnv.joints)
-	if jointCount == 0 {
+	if len(cnv.joints) == 0 {
 		return ErrNoJointsAvailable
 	}
 
-	wrapped := wrapSink[TIn](exec)
-	workerType := WorkerTypeSink
+	return addSinkAfterJoint[TIn](cnv, exec, mode)
+}
 
-	nodeWorker, err := newNodeWorker(wrapped, mode, workerType)
+// addSinkAfterJoint adds a sink after the last joint, once its input type has been validated
+func addSinkAfterJoint[TIn any](cnv *Conveyor, exec SinkExecutor[TIn], mode WorkerMode) error {
+	jointCount := len(cnv.joints)
+	nodeWorker, err := sinkWorkerFor[TIn](cnv, exec, mode)
 	if err != nil {
 		return err
 	}
@@ -420,6 +517,8 @@ func AddSinkAfterJoint[TIn any](cnv *Conveyor, exec SinkExecutor[TIn], mode Work
 		return linkErr
 	}
 
+	cnv.sinks = append(cnv.sinks, nodeWorker)
+	cnv.stages[exec.GetName()] = exec
 	cnv.lockConfig()
 	return nil
 }
@@ -446,15 +545,17 @@ func AddOperationAfterJoint[TIn, TOut any](cnv *Conveyor, exec OperationExecutor
 		return fmt.Errorf("%w: expected input type %v but got %v", ErrTypeMismatch, cnv.lastJointOutType, expectedIn)
 	}
 
-	jointCount := len(cnv.joints)
-	if jointCount == 0 {
+	if len(cnv.joints) == 0 {
 		return ErrNoJointsAvailable
 	}
 
-	wrapped := wrapOperation[TIn, TOut](exec)
-	workerType := WorkerTypeOperation
+	return addOperationAfterJoint[TIn, TOut](cnv, exec, mode)
+}
 
-	nodeWorker, err := newNodeWorker(wrapped, mode, workerType)
+// addOperationAfterJoint adds an operation after the last joint, once its input type has been validated
+func addOperationAfterJoint[TIn, TOut any](cnv *Conveyor, exec OperationExecutor[TIn, TOut], mode WorkerMode) error {
+	jointCount := len(cnv.joints)
+	nodeWorker, err := operationWorkerFor[TIn, TOut](cnv, exec, mode)
 	if err != nil {
 		return err
 	}
@@ -473,6 +574,8 @@ func AddOperationAfterJoint[TIn, TOut any](cnv *Conveyor, exec OperationExecutor
 		return linkErr
 	}
 
+	cnv.caches.register(exec)
+	cnv.stages[exec.GetName()] = exec
 	cnv.lastNodeOutType = reflect.TypeFor[TOut]()
 	cnv.lockConfig()
 	return nil
@@ -487,7 +590,16 @@ func MustAddOperationAfterJoint[TIn, TOut any](cnv *Conveyor, exec OperationExec
 
 // AddNodeWorker employs a new worker station to the conveyor
 func (cnv *Conveyor) AddNodeWorker(worker NodeWorker, toLink bool) error {
-	worker.CreateChannels(cnv.bufferLen)
+	if bw, ok := worker.(batchedWorker); ok {
+		bw.setBatching(cnv.batch)
+	}
+	buffer := cnv.bufferLen
+	if ew, ok := worker.(edgeWorker); ok {
+		edge := cnv.edgeFor(ew.stage())
+		ew.setEdge(edge)
+		buffer = edge.Buffer
+	}
+	worker.CreateChannels(buffer)
 	cnv.workers = append(cnv.workers, worker)
 
 	workerCount := len(cnv.workers)
@@ -509,7 +621,16 @@ func (cnv *Conveyor) AddNodeWorker(worker NodeWorker, toLink bool) error {
 // AddJointWorker employs a new joint station to the conveyor
 func (cnv *Conveyor) AddJointWorker(joint JointWorker) error {
 
-	joint.CreateChannels(cnv.bufferLen)
+	if bw, ok := joint.(batchedWorker); ok {
+		bw.setBatching(cnv.batch)
+	}
+	buffer := cnv.bufferLen
+	if ew, ok := joint.(edgeWorker); ok {
+		edge := cnv.edgeFor(ew.stage())
+		ew.setEdge(edge)
+		buffer = edge.Buffer
+	}
+	joint.CreateChannels(buffer)
 	cnv.joints = append(cnv.joints, joint)
 
 	return nil
@@ -523,113 +644,224 @@ func (cnv *Conveyor) Start() error {
 
 	wg := sync.WaitGroup{}
 
-	if cnv.needProgress {
-		go cnv.updateProgress()
-	}
-
 	workerCount := len(cnv.workers)
 	if workerCount == 0 {
 		return ErrEmptyConveyor
 	}
+	if cnv.branch != nil {
+		return ErrUnjoinedBranch
+	}
+
+	cnv.markState(StatusPreparing)
+
+	if cnv.needProgress {
+		cnv.progressMu.Lock()
+		if !cnv.progressRunning {
+			cnv.progressRunning = true
+			go cnv.updateProgress()
+		}
+		cnv.progressMu.Unlock()
+	}
+
+	syncDone := make(chan struct{})
+	if cnv.lifeCycle != nil {
+		go cnv.syncLifeCycle(syncDone)
+	} else {
+		close(syncDone)
+	}
+
+	// Sources get their own context, so that Drain() can stop them without stopping everything else
+	cnv.drainMu.Lock()
+	sourceCtx, drainSource := newSourceContext(cnv.ctx)
+	cnv.drainSource = drainSource
+	if cnv.draining.Load() {
+		drainSource()
+	}
+	cnv.drainMu.Unlock()
+
+	watchDone := make(chan struct{})
+	if cnv.lifeCycle != nil && cnv.remoteKillInterval > 0 {
+		go cnv.watchRemoteKill(watchDone)
+	} else {
+		close(watchDone)
+	}
+
+	// A worker that can't start leaves its neighbours waiting on channels that will never close,
+	// so the whole conveyor is cancelled, and marked as failed.
+	var startFailed atomic.Bool
 
 	for _, nodeWorker := range cnv.workers {
 		wg.Add(1)
-		go func(nodeWorker NodeWorker) {
+		spawnWorker(nodeWorker, func() {
 			defer wg.Done()
-			if err := nodeWorker.Start(cnv.ctx); err != nil {
+
+			var ctx CnvContext = cnv.ctx
+			if nodeWorker.WorkerType() == WorkerTypeSource {
+				ctx = sourceCtx
+			}
+
+			if err := nodeWorker.Start(ctx); err != nil {
 				log.Println("node worker start failed", err)
-				return
+				startFailed.Store(true)
+				cnv.ctx.Cancel()
+				// Still close the worker's output, so that the next node doesn't wait for it forever
 			}
 
-			if err := nodeWorker.WaitAndStop(cnv.ctx); err != nil {
+			if err := nodeWorker.WaitAndStop(ctx); err != nil {
 				log.Println("node worker stop failed", err)
 			}
-		}(nodeWorker)
+		})
 	}
 
 	for _, jointWorker := range cnv.joints {
 		wg.Add(1)
-		go func(jointWorker JointWorker) {
+		spawnWorker(jointWorker, func() {
 			defer wg.Done()
 
 			if err := jointWorker.Start(cnv.ctx); err != nil {
 				log.Println("join worker start failed", err)
-				return
+				startFailed.Store(true)
+				cnv.ctx.Cancel()
 			}
 
 			if err := jointWorker.WaitAndStop(); err != nil {
 				log.Println("join worker stop failed", err)
 			}
-
-		}(jointWorker)
+		})
 	}
 
-	// wait for the conveyor to finish
-	wg.Wait()
-
-	cnv.cleanup(false) // Cleanup() will be called from here, in case of success or timeout
+	cnv.markState(StateStarted)
 
+	// wait for the conveyor to finish
+	leaked := cnv.waitWorkers(&wg)
+
+	// Find out how the conveyor ended, before cleanup() cancels the context
+	ctxErr := cnv.ctx.Err()
+
+	cnv.cleanup() // Cleanup() will be called from here, in case of success or timeout
+	<-syncDone
+	<-watchDone
+
+	switch {
+	case cnv.stopped.Load():
+		// Stop() marks the conveyor as killed by itself
+	case cnv.draining.Load():
+		// Drain() has already marked the conveyor as toKill, and it's now done draining
+		cnv.markState(StateKilled)
+	case startFailed.Load(), errors.Is(ctxErr, context.DeadlineExceeded):
+		cnv.markState(StateInternalError)
+	case ctxErr != nil:
+		// Context was cancelled directly, rather than through Stop()
+		cnv.markState(StateToKill)
+		cnv.markState(StateKilled)
+	default:
+		cnv.markState(StateFinished)
+	}
+
+	if len(leaked) > 0 {
+		err := fmt.Errorf("%w: %s", ErrGoroutineLeak, strings.Join(leaked, ", "))
+		log.Printf("Conveyor:[%s] Error:[%v]\n", cnv.Name, err)
+		return err
+	}
 	return nil
 }
 
 // Stop Conveyor by cancelling context. It's used to kill a pipeline while it's running.
 // No need to call it if the pipeline is finishing on it's own
 func (cnv *Conveyor) Stop() time.Duration {
+	cnv.stopped.Store(true)
+	cnv.markState(StateToKill)
 	// Cancel ctx
-	cnv.cleanup(true) // cleanup() will be called from here, in case of killing conveyor
-	return cnv.duration
+	cnv.cleanup() // cleanup() will be called from here, in case of killing conveyor
+	cnv.markState(StateKilled)
+	return cnv.tracker.report().Elapsed
+}
+
+// Drain stops the conveyor's sources, and lets the rest of the nodes finish processing the items
+// that are already in the pipeline. Start() returns once they are done, just like it does when sources
+// are exhausted. Loop-mode sources must watch ctx.Done() for this to work.
+// The conveyor is marked as toKill right away, and as killed once it has drained.
+// Unlike Stop(), it doesn't wait for anything.
+func (cnv *Conveyor) Drain() {
+	if !cnv.draining.CompareAndSwap(false, true) {
+		return
+	}
+	cnv.markState(StateToKill)
+
+	cnv.drainMu.Lock()
+	defer cnv.drainMu.Unlock()
+	if cnv.drainSource != nil {
+		cnv.drainSource()
+	}
 }
 
 // cleanup should be called in all the termination cases: success, kill, & timeout
-func (cnv *Conveyor) cleanup(abruptKill bool) {
+func (cnv *Conveyor) cleanup() {
 	// In case, conveyor was killed, ctx.Cancel() night have been already called, but it's an idempotent method
 	cnv.ctx.Cancel()
+	cnv.drainMu.Lock()
+	if cnv.drainSource != nil {
+		cnv.drainSource() // Only releases the source context's resources, as ctx is already cancelled
+	}
+	cnv.drainMu.Unlock()
+	cnv.spills.removeAll()
 	if cnv.needProgress {
 		cnv.cleanupOnce.Do(func() {
-			close(cnv.progress)
+			// The progress goroutine is the only sender on "progress", so it closes the
+			// channel itself. Close it here only if it never got started.
+			cnv.progressMu.Lock()
+			running := cnv.progressRunning
+			cnv.progressRunning = true
+			cnv.progressMu.Unlock()
+			if !running {
+				close(cnv.progress)
+				close(cnv.progressDone)
+			}
 		})
-	}
-	if !abruptKill && cnv.lifeCycle != nil {
-		if err := cnv.MarkCurrentState(StateFinished); err != nil {
-			log.Printf("Conveyor:[%s] unable to set status as 'finished': Error:[%v]\n", cnv.Name, err)
-		}
+		<-cnv.progressDone
 	}
 }
 
 func (cnv *Conveyor) updateProgress() {
-
-	start := time.Now()
+	defer close(cnv.progressDone)
+	defer close(cnv.progress)
 
 	ticker := time.NewTicker(cnv.tickProgress)
 	defer ticker.Stop()
-trackProgress:
-	for range ticker.C {
-		cnv.duration = time.Since(start)
-
-		percentDone := float64((cnv.duration.Seconds() / cnv.expectedDuration.Seconds()) * 100)
-		// if estimate is incorrect, don't overflow progress end
-		if percentDone > 100 {
-			percentDone = 99.0
-		}
 
-		// Seperated out, contex closed check, as in the next check, "default" is always picked.
-		// Even if ctx is done, and "cleanup()" function above, has closed "cnv.progress"
+	cnv.tracker.update(time.Now(), 0, 0, cnv.expectedDuration)
+	for {
 		select {
 		case <-cnv.ctx.Done():
-			break trackProgress
-		default:
+			// One last report, with whatever was delivered since the last tick
+			cnv.reportProgress(time.Now())
+			return
+		case now := <-ticker.C:
+			cnv.reportProgress(now)
 		}
+	}
+}
 
-		select {
-		case cnv.progress <- percentDone:
-		default:
-			<-cnv.progress
-			cnv.progress <- percentDone
-		}
+// reportProgress updates the progress tracker, and sends the new percentage on the progress channel
+func (cnv *Conveyor) reportProgress(now time.Time) {
+	var total int64
+	if cnv.sizedSource != nil {
+		total = cnv.sizedSource.Size()
+	}
+	// Dropped items will never reach a sink, but they are done with all the same
+	report := cnv.tracker.update(now, delivered(cnv.sinks)+cnv.dropped.delivered(), total, cnv.expectedDuration)
+
+	// If the last value hasn't been consumed, throw it away and update with the new one
+	select {
+	case <-cnv.progress:
+	default:
 	}
+	cnv.progress <- report.Percent
 }
 
-// MarkCurrentState marks the current stage of conveyor using internal life-cycle handler interface
+// MarkCurrentState marks the current stage of conveyor using internal life-cycle handler interface.
+// Returns ErrIllegalStateTransition if the conveyor can't move to the given state from its current one,
+// for example from "finished" to "started".
 func (cnv *Conveyor) MarkCurrentState(state string) error {
 	if cnv.lifeCycle == nil {
 		return ErrLifeCycleNotSupported
@@ -641,5 +873,100 @@ func (cnv *Conveyor) MarkCurrentState(state string) error {
 			"Look for 'Valid States for a Conveyor' in docs", state)
 	}
 
-	return statusMarkerFunc()
+	cnv.stateMu.Lock()
+	defer cnv.stateMu.Unlock()
+
+	if !isValidTransition(cnv.state, state) {
+		return fmt.Errorf("%w: from '%s' to '%s'", ErrIllegalStateTransition, cnv.state, state)
+	}
+
+	if err := statusMarkerFunc(); err != nil {
+		return err
+	}
+	cnv.state = state
+	return nil
+}
+
+// CurrentState returns the state the conveyor is in, or an empty string if it hasn't started yet.
+// Conveyor tracks its own state transitions even without a LifeCycleHandler.
+func (cnv *Conveyor) CurrentState() string {
+	cnv.stateMu.Lock()
+	defer cnv.stateMu.Unlock()
+	return cnv.state
+}
+
+// ID returns the id of the Conveyor, as set by SetID(), or the UUID generated by NewConveyor()
+func (cnv *Conveyor) ID() string {
+	return cnv.id
+}
+
+// markState is used by the conveyor to mark its own state transitions. Without a LifeCycleHandler,
+// the state is only tracked internally. Failures are only logged, as they must not stop the conveyor.
+func (cnv *Conveyor) markState(state string) {
+	if cnv.lifeCycle != nil {
+		if err := cnv.MarkCurrentState(state); err != nil {
+			log.Printf("Conveyor:[%s] unable to set state as '%s': Error:[%v]\n", cnv.Name, state, err)
+		}
+		return
+	}
+
+	cnv.stateMu.Lock()
+	defer cnv.stateMu.Unlock()
+	if isValidTransition(cnv.state, state) {
+		cnv.state = state
+	}
+}
+
+// syncLifeCycle periodically mirrors the latest status message & progress into the LifeCycleHandler,
+// until the conveyor's context is done. It does a last sync before closing "done".
+func (cnv *Conveyor) syncLifeCycle(done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(cnv.tickLifeCycle)
+	defer ticker.Stop()
+
+	var lastStatus, lastProgress string
+	mirror := func() {
+		if status, ok := cnv.latestStatus(); ok && status != lastStatus {
+			if err := cnv.lifeCycle.UpdateStatusMsg(status); err != nil {
+				log.Printf("Conveyor:[%s] unable to update status message: Error:[%v]\n", cnv.Name, err)
+			} else {
+				lastStatus = status
+			}
+		}
+		if !cnv.needProgress {
+			return
+		}
+		if progress := fmt.Sprintf("%.2f", cnv.tracker.report().Percent); progress != lastProgress {
+			if err := cnv.lifeCycle.UpdateProgress(progress); err != nil {
+				log.Printf("Conveyor:[%s] unable to update progress: Error:[%v]\n", cnv.Name, err)
+			} else {
+				lastProgress = progress
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-cnv.ctx.Done():
+			mirror()
+			return
+		case <-ticker.C:
+			mirror()
+		}
+	}
+}
+
+// latestStatus returns the last message sent through CnvContext.SendStatus(), if any.
+// It's only available with the default context implementation.
+func (cnv *Conveyor) latestStatus() (string, bool) {
+	ctxData, ok := cnv.ctx.GetData().(CtxData)
+	if !ok || ctxData.lastStatus == nil {
+		return "", false
+	}
+	status := ctxData.lastStatus.Load()
+	if status == nil {
+		return "", false
+	}
+	return *status, true
 }
@@ -0,0 +1,136 @@
+package conveyortest
+
+import (
+	"sync"
+
+	"github.com/tushar2708/conveyor"
+)
+
+// SliceSource emits the items of a slice, in order, and is then exhausted.
+// It works in both WorkerModeTransaction and WorkerModeLoop, and implements conveyor.Sized,
+// so progress is tracked by items delivered.
+type SliceSource[T any] struct {
+	conveyor.ConcreteSourceExecutor[T]
+	mu    sync.Mutex
+	items []T
+	next  int
+}
+
+// NewSliceSource creates a SliceSource that emits items
+func NewSliceSource[T any](name string, items ...T) *SliceSource[T] {
+	return &SliceSource[T]{
+		ConcreteSourceExecutor: conveyor.ConcreteSourceExecutor[T]{Name: name},
+		items:                  items,
+	}
+}
+
+// Size returns the number of items the source emits in total
+func (s *SliceSource[T]) Size() int64 {
+	return int64(len(s.items))
+}
+
+// pop returns the next item, or false once every item has been emitted
+func (s *SliceSource[T]) pop() (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.next >= len(s.items) {
+		var zero T
+		return zero, false
+	}
+	item := s.items[s.next]
+	s.next++
+	return item, true
+}
+
+// Execute returns the next item, or conveyor.ErrSourceExhausted
+func (s *SliceSource[T]) Execute(ctx conveyor.CnvContext) (T, error) {
+	item, ok := s.pop()
+	if !ok {
+		return item, conveyor.ErrSourceExhausted
+	}
+	return item, nil
+}
+
+// ExecuteLoop sends every remaining item to outChan, and returns once done or once ctx is done
+func (s *SliceSource[T]) ExecuteLoop(ctx conveyor.CnvContext, outChan chan<- T) error {
+	for {
+		item, ok := s.pop()
+		if !ok {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case outChan <- item:
+		}
+	}
+}
+
+// CollectSink keeps every item it receives. It's safe for concurrent use,
+// and works in both WorkerModeTransaction and WorkerModeLoop.
+type CollectSink[T any] struct {
+	conveyor.ConcreteSinkExecutor[T]
+	mu    sync.Mutex
+	items []T
+}
+
+// NewCollectSink creates an empty CollectSink
+func NewCollectSink[T any](name string) *CollectSink[T] {
+	return &CollectSink[T]{ConcreteSinkExecutor: conveyor.ConcreteSinkExecutor[T]{Name: name}}
+}
+
+// Execute keeps the item
+func (s *CollectSink[T]) Execute(ctx conveyor.CnvContext, inData T) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = append(s.items, inData)
+	return nil
+}
+
+// ExecuteLoop keeps every item received from inChan, until it's closed
+func (s *CollectSink[T]) ExecuteLoop(ctx conveyor.CnvContext, inChan <-chan T) error {
+	for item := range inChan {
+		_ = s.Execute(ctx, item)
+	}
+	return nil
+}
+
+// Items returns a copy of the items received so far, in the order they were received
+func (s *CollectSink[T]) Items() []T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]T(nil), s.items...)
+}
+
+// Len returns the number of items received so far
+func (s *CollectSink[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.items)
+}
+
+// FuncOperation is an operation that applies Fn to every item.
+// In WorkerModeLoop, it runs like conveyor.OperationFunc(): items for which Fn fails are recorded
+// in the conveyor's ErrorStats, and dropped, and those it skips with conveyor.ErrSkipItem are only dropped.
+type FuncOperation[TIn, TOut any] struct {
+	conveyor.ConcreteOperationExecutor[TIn, TOut]
+	Fn func(ctx conveyor.CnvContext, in TIn) (TOut, error)
+}
+
+// NewFuncOperation creates a FuncOperation that applies fn
+func NewFuncOperation[TIn, TOut any](name string, fn func(ctx conveyor.CnvContext, in TIn) (TOut, error)) *FuncOperation[TIn, TOut] {
+	return &FuncOperation[TIn, TOut]{
+		ConcreteOperationExecutor: conveyor.ConcreteOperationExecutor[TIn, TOut]{Name: name},
+		Fn:                        fn,
+	}
+}
+
+// Execute applies Fn to inData
+func (o *FuncOperation[TIn, TOut]) Execute(ctx conveyor.CnvContext, inData TIn) (TOut, error) {
+	return o.Fn(ctx, inData)
+}
+
+// ExecuteLoop applies Fn to every item received from inChan, until it's closed or ctx is done
+func (o *FuncOperation[TIn, TOut]) ExecuteLoop(ctx conveyor.CnvContext, inChan <-chan TIn, outChan chan<- TOut) error {
+	return conveyor.OperationFunc(o.Name, o.Count(), o.Fn).ExecuteLoop(ctx, inChan, outChan)
+}
@@ -0,0 +1,94 @@
+package conveyortest_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tushar2708/conveyor"
+	"github.com/tushar2708/conveyor/conveyortest"
+)
+
+var errOdd = errors.New("odd number")
+
+// dropOdd fails for odd numbers, and formats even ones
+func dropOdd(ctx conveyor.CnvContext, in int) (string, error) {
+	if in%2 != 0 {
+		return "", fmt.Errorf("rejecting %d: %w", in, errOdd)
+	}
+	return fmt.Sprintf("#%d", in), nil
+}
+
+func TestPipeline_BothModes(t *testing.T) {
+	for _, mode := range []conveyor.WorkerMode{conveyor.WorkerModeTransaction, conveyor.WorkerModeLoop} {
+		t.Run(fmt.Sprintf("mode_%d", mode), func(t *testing.T) {
+			cnv, err := conveyor.NewConveyor("helpers", 10)
+			require.NoError(t, err)
+
+			snk := conveyortest.NewCollectSink[string]("snk")
+			conveyor.MustAddSource[int](cnv, conveyortest.NewSliceSource("src", 1, 2, 3, 4, 5, 6), mode)
+			conveyor.MustAddOperation[int, string](cnv, conveyortest.NewFuncOperation("op", dropOdd), mode)
+			conveyor.MustAddSink[string](cnv, snk, mode)
+
+			conveyortest.RunToCompletion(t, cnv, time.Second)
+
+			assert.ElementsMatch(t, []string{"#2", "#4", "#6"}, snk.Items())
+			assert.Equal(t, 3, snk.Len())
+			conveyortest.AssertErrorTotal(t, cnv.Errors(), 3)
+			conveyortest.AssertStageErrors(t, cnv.Errors(), "op", 3)
+			conveyortest.AssertStageErrors(t, cnv.Errors(), "snk", 0)
+			conveyortest.AssertErrorCount(t, cnv.Errors(), "op", errOdd, 3)
+		})
+	}
+}
+
+func TestFuncOperation_Skip(t *testing.T) {
+	skipOdd := func(ctx conveyor.CnvContext, in int) (int, error) {
+		if in%2 != 0 {
+			return 0, conveyor.ErrSkipItem
+		}
+		return in, nil
+	}
+	for _, mode := range []conveyor.WorkerMode{conveyor.WorkerModeTransaction, conveyor.WorkerModeLoop} {
+		cnv, err := conveyor.NewConveyor("skip", 10)
+		require.NoError(t, err)
+
+		snk := conveyortest.NewCollectSink[int]("snk")
+		conveyor.MustAddSource[int](cnv, conveyortest.NewSliceSource("src", 1, 2, 3, 4, 5, 6), mode)
+		conveyor.MustAddOperation[int, int](cnv, conveyortest.NewFuncOperation("op", skipOdd), mode)
+		conveyor.MustAddSink[int](cnv, snk, mode)
+
+		conveyortest.RunToCompletion(t, cnv, time.Second)
+
+		assert.ElementsMatch(t, []int{2, 4, 6}, snk.Items(), "mode: %d", mode)
+		conveyortest.AssertErrorTotal(t, cnv.Errors(), 0)
+	}
+}
+
+func TestSliceSource_Exhausted(t *testing.T) {
+	src := conveyortest.NewSliceSource("src", "a")
+	assert.Equal(t, int64(1), src.Size())
+
+	item, err := src.Execute(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "a", item)
+
+	_, err = src.Execute(nil)
+	assert.Equal(t, conveyor.ErrSourceExhausted, err)
+}
+
+func TestRunToCompletion_NoErrors(t *testing.T) {
+	cnv, err := conveyor.NewConveyor("no_errors", 10)
+	require.NoError(t, err)
+	snk := conveyortest.NewCollectSink[int]("snk")
+	conveyor.MustAddSource[int](cnv, conveyortest.NewSliceSource("src", 1, 2, 3), conveyor.WorkerModeLoop)
+	conveyor.MustAddSink[int](cnv, snk, conveyor.WorkerModeLoop)
+
+	conveyortest.RunToCompletion(t, cnv, time.Second)
+
+	assert.Equal(t, []int{1, 2, 3}, snk.Items())
+	conveyortest.AssertNoErrors(t, cnv.Errors())
+}
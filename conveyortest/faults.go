@@ -0,0 +1,310 @@
+package conveyortest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tushar2708/conveyor"
+)
+
+// FaultError is the error injected by faulty executors, unless Faults.Err is set.
+// It has its own type, so that injected errors get their own ErrorStats bucket.
+type FaultError struct {
+	Call int64 // number of the call it was injected in, starting at 1
+}
+
+func (fe *FaultError) Error() string {
+	return fmt.Sprintf("conveyortest: fault injected in call %d", fe.Call)
+}
+
+// LatencyDist draws a latency to add to a call, from rng
+type LatencyDist func(rng *rand.Rand) time.Duration
+
+// FixedLatency adds d to every call
+func FixedLatency(d time.Duration) LatencyDist {
+	return func(*rand.Rand) time.Duration { return d }
+}
+
+// UniformLatency adds a latency picked uniformly in [min, max)
+func UniformLatency(min, max time.Duration) LatencyDist {
+	return func(rng *rand.Rand) time.Duration {
+		if max <= min {
+			return min
+		}
+		return min + time.Duration(rng.Int63n(int64(max-min)))
+	}
+}
+
+// NormalLatency adds a normally distributed latency. Negative draws are clamped to 0.
+func NormalLatency(mean, stdDev time.Duration) LatencyDist {
+	return func(rng *rand.Rand) time.Duration {
+		return time.Duration(math.Max(0, rng.NormFloat64()*float64(stdDev)+float64(mean)))
+	}
+}
+
+// ExponentialLatency adds an exponentially distributed latency, i.e. mostly short with a long tail
+func ExponentialLatency(mean time.Duration) LatencyDist {
+	return func(rng *rand.Rand) time.Duration {
+		return time.Duration(rng.ExpFloat64() * float64(mean))
+	}
+}
+
+// Faults describes the faults to inject on every call to a decorated executor.
+// They're applied in this order: hang, panic, latency, error.
+type Faults struct {
+	// ErrorRate is the probability, between 0 and 1, of failing a call with Err
+	ErrorRate float64
+
+	// Err is the error injected. Defaults to a *FaultError.
+	Err error
+
+	// Latency, if set, is slept before every call, or until the context is done
+	Latency LatencyDist
+
+	// PanicEvery makes every Nth call panic. 0 disables it.
+	PanicEvery int
+
+	// HangEvery makes every Nth call block until the context is done. 0 disables it.
+	HangEvery int
+
+	// Seed of the random generator. Runs with the same seed inject the same faults,
+	// as long as the executor runs a single worker. 0 picks a random seed.
+	Seed int64
+}
+
+// FaultStats counts the faults injected by a decorated executor
+type FaultStats struct {
+	Calls  int64
+	Errors int64
+	Panics int64
+	Hangs  int64
+}
+
+// injector decides, call after call, which faults to inject
+type injector struct {
+	faults Faults
+	mu     sync.Mutex
+	rng    *rand.Rand
+
+	calls  atomic.Int64
+	errors atomic.Int64
+	panics atomic.Int64
+	hangs  atomic.Int64
+}
+
+func newInjector(faults Faults) *injector {
+	seed := faults.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &injector{faults: faults, rng: rand.New(rand.NewSource(seed))}
+}
+
+// FaultStats returns the number of calls, and of faults injected so far
+func (inj *injector) FaultStats() FaultStats {
+	return FaultStats{
+		Calls:  inj.calls.Load(),
+		Errors: inj.errors.Load(),
+		Panics: inj.panics.Load(),
+		Hangs:  inj.hangs.Load(),
+	}
+}
+
+// inject applies the faults due for this call. It returns the error to fail the call with, if any.
+func (inj *injector) inject(ctx conveyor.CnvContext) error {
+	n := inj.calls.Add(1)
+
+	if inj.faults.HangEvery > 0 && n%int64(inj.faults.HangEvery) == 0 {
+		inj.hangs.Add(1)
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	if inj.faults.PanicEvery > 0 && n%int64(inj.faults.PanicEvery) == 0 {
+		inj.panics.Add(1)
+		panic(fmt.Sprintf("conveyortest: injected panic on call %d", n))
+	}
+
+	// Draws are made under a single lock, so that a seed always gives the same sequence
+	inj.mu.Lock()
+	var latency time.Duration
+	if inj.faults.Latency != nil {
+		latency = inj.faults.Latency(inj.rng)
+	}
+	fail := inj.faults.ErrorRate > 0 && inj.rng.Float64() < inj.faults.ErrorRate
+	inj.mu.Unlock()
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if fail {
+		inj.errors.Add(1)
+		if inj.faults.Err != nil {
+			return inj.faults.Err
+		}
+		return &FaultError{Call: n}
+	}
+	return nil
+}
+
+// FaultySource decorates a SourceExecutor, to inject faults before every item it produces.
+// In WorkerModeLoop, items hit by an injected error are dropped, and the error is recorded in ErrorStats.
+type FaultySource[T any] struct {
+	conveyor.SourceExecutor[T]
+	*injector
+}
+
+// NewFaultySource decorates exec with faults
+func NewFaultySource[T any](exec conveyor.SourceExecutor[T], faults Faults) *FaultySource[T] {
+	return &FaultySource[T]{SourceExecutor: exec, injector: newInjector(faults)}
+}
+
+// Execute injects faults, then calls the decorated executor
+func (fs *FaultySource[T]) Execute(ctx conveyor.CnvContext) (T, error) {
+	if err := fs.inject(ctx); err != nil {
+		var zero T
+		return zero, err
+	}
+	return fs.SourceExecutor.Execute(ctx)
+}
+
+// ExecuteLoop runs the decorated executor, and injects faults before forwarding every item it produces
+func (fs *FaultySource[T]) ExecuteLoop(ctx conveyor.CnvContext, outChan chan<- T) (loopErr error) {
+	produced := make(chan T)
+	errCh := make(chan error, 1)
+	loopCtx := ctx.WithCancel()
+	go func() {
+		defer close(produced)
+		errCh <- fs.SourceExecutor.ExecuteLoop(loopCtx, produced)
+	}()
+
+	// Even after a panic, the decorated executor must have returned before this one does,
+	// so it's told to stop rather than drained, as it may never run out of items
+	defer func() {
+		loopCtx.Cancel()
+		for range produced {
+		}
+		loopErr = <-errCh
+	}()
+
+	for item := range produced {
+		if err := fs.inject(ctx); err != nil {
+			ctx.RecordError(fs.GetName(), err)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case outChan <- item:
+		}
+	}
+	return nil
+}
+
+// FaultyOperation decorates an OperationExecutor, to inject faults before every item it processes.
+// In WorkerModeLoop, items hit by an injected error are dropped, and the error is recorded in ErrorStats.
+type FaultyOperation[TIn, TOut any] struct {
+	conveyor.OperationExecutor[TIn, TOut]
+	*injector
+}
+
+// NewFaultyOperation decorates exec with faults
+func NewFaultyOperation[TIn, TOut any](exec conveyor.OperationExecutor[TIn, TOut], faults Faults) *FaultyOperation[TIn, TOut] {
+	return &FaultyOperation[TIn, TOut]{OperationExecutor: exec, injector: newInjector(faults)}
+}
+
+// Execute injects faults, then calls the decorated executor
+func (fo *FaultyOperation[TIn, TOut]) Execute(ctx conveyor.CnvContext, inData TIn) (TOut, error) {
+	if err := fo.inject(ctx); err != nil {
+		var zero TOut
+		return zero, err
+	}
+	return fo.OperationExecutor.Execute(ctx, inData)
+}
+
+// ExecuteLoop injects faults before handing every item from inChan to the decorated executor
+func (fo *FaultyOperation[TIn, TOut]) ExecuteLoop(ctx conveyor.CnvContext, inChan <-chan TIn, outChan chan<- TOut) error {
+	accepted := make(chan TIn)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fo.OperationExecutor.ExecuteLoop(ctx, accepted, outChan)
+	}()
+	return forwardFaulty(ctx, fo.injector, fo.GetName(), inChan, accepted, errCh)
+}
+
+// FaultySink decorates a SinkExecutor, to inject faults before every item it consumes.
+// In WorkerModeLoop, items hit by an injected error are dropped, and the error is recorded in ErrorStats.
+type FaultySink[T any] struct {
+	conveyor.SinkExecutor[T]
+	*injector
+}
+
+// NewFaultySink decorates exec with faults
+func NewFaultySink[T any](exec conveyor.SinkExecutor[T], faults Faults) *FaultySink[T] {
+	return &FaultySink[T]{SinkExecutor: exec, injector: newInjector(faults)}
+}
+
+// Execute injects faults, then calls the decorated executor
+func (fs *FaultySink[T]) Execute(ctx conveyor.CnvContext, inData T) error {
+	if err := fs.inject(ctx); err != nil {
+		return err
+	}
+	return fs.SinkExecutor.Execute(ctx, inData)
+}
+
+// ExecuteLoop injects faults before handing every item from inChan to the decorated executor
+func (fs *FaultySink[T]) ExecuteLoop(ctx conveyor.CnvContext, inChan <-chan T) error {
+	accepted := make(chan T)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fs.SinkExecutor.ExecuteLoop(ctx, accepted)
+	}()
+	return forwardFaulty(ctx, fs.injector, fs.GetName(), inChan, accepted, errCh)
+}
+
+// forwardFaulty forwards items from inChan to accepted, unless a fault is injected, until inChan
+// is closed or ctx is done. errCh receives the error of the decorated executor consuming accepted,
+// which is always waited for, even after a panic, so that it can't outlive the worker.
+func forwardFaulty[T any](ctx conveyor.CnvContext, inj *injector, name string,
+	inChan <-chan T, accepted chan T, errCh chan error) error {
+
+	var consumerErr error
+	consumerDone := make(chan struct{})
+	go func() {
+		defer close(consumerDone)
+		consumerErr = <-errCh
+	}()
+
+	func() {
+		defer func() {
+			close(accepted)
+			<-consumerDone
+		}()
+
+		for item := range inChan {
+			if err := inj.inject(ctx); err != nil {
+				ctx.RecordError(name, err)
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-consumerDone:
+				return
+			case accepted <- item:
+			}
+		}
+	}()
+	return consumerErr
+}
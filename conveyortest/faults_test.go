@@ -0,0 +1,150 @@
+package conveyortest_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tushar2708/conveyor"
+	"github.com/tushar2708/conveyor/conveyortest"
+)
+
+func identity(ctx conveyor.CnvContext, in int) (int, error) { return in, nil }
+
+func sequence(n int) []int {
+	items := make([]int, n)
+	for i := range items {
+		items[i] = i
+	}
+	return items
+}
+
+// TestFaults_Seeded verifies that the same seed injects the same errors.
+func TestFaults_Seeded(t *testing.T) {
+	failures := func(seed int64) []bool {
+		op := conveyortest.NewFaultyOperation[int, int](conveyortest.NewFuncOperation("op", identity),
+			conveyortest.Faults{ErrorRate: 0.3, Seed: seed})
+		var failed []bool
+		for i := 0; i < 100; i++ {
+			_, err := op.Execute(nil, i)
+			failed = append(failed, err != nil)
+		}
+		return failed
+	}
+
+	first := failures(42)
+	assert.Equal(t, first, failures(42))
+	assert.NotEqual(t, first, failures(7))
+	assert.Contains(t, first, true)
+	assert.Contains(t, first, false)
+}
+
+// TestFaults_TransactionMode verifies that injected errors and panics are all accounted for in ErrorStats.
+func TestFaults_TransactionMode(t *testing.T) {
+	cnv, err := conveyor.NewConveyor("chaos", 10)
+	require.NoError(t, err)
+
+	op := conveyortest.NewFaultyOperation[int, int](conveyortest.NewFuncOperation("op", identity),
+		conveyortest.Faults{ErrorRate: 0.2, PanicEvery: 10, Seed: 1})
+	snk := conveyortest.NewCollectSink[int]("snk")
+	conveyor.MustAddSource[int](cnv, conveyortest.NewSliceSource("src", sequence(100)...), conveyor.WorkerModeTransaction)
+	conveyor.MustAddOperation[int, int](cnv, op, conveyor.WorkerModeTransaction)
+	conveyor.MustAddSink[int](cnv, snk, conveyor.WorkerModeTransaction)
+
+	conveyortest.RunToCompletion(t, cnv, 5*time.Second)
+
+	stats := op.FaultStats()
+	assert.Equal(t, int64(100), stats.Calls)
+	assert.Equal(t, int64(10), stats.Panics)
+	assert.Positive(t, stats.Errors)
+	conveyortest.AssertErrorCount(t, cnv.Errors(), "op", &conveyortest.FaultError{}, stats.Errors)
+	conveyortest.AssertErrorCount(t, cnv.Errors(), "op", &conveyor.PanicError{}, stats.Panics)
+	assert.Equal(t, 100-int(stats.Errors+stats.Panics), snk.Len())
+}
+
+// TestFaults_LoopMode verifies that in loop mode, failed items are dropped and recorded.
+func TestFaults_LoopMode(t *testing.T) {
+	cnv, err := conveyor.NewConveyor("chaos_loop", 10)
+	require.NoError(t, err)
+
+	src := conveyortest.NewFaultySource[int](conveyortest.NewSliceSource("src", sequence(50)...),
+		conveyortest.Faults{ErrorRate: 0.1, Seed: 3})
+	op := conveyortest.NewFaultyOperation[int, int](conveyortest.NewFuncOperation("op", identity),
+		conveyortest.Faults{ErrorRate: 0.1, Seed: 4})
+	snk := conveyortest.NewFaultySink[int](conveyortest.NewCollectSink[int]("snk"),
+		conveyortest.Faults{ErrorRate: 0.1, Seed: 5, Latency: conveyortest.UniformLatency(0, time.Millisecond)})
+	conveyor.MustAddSource[int](cnv, src, conveyor.WorkerModeLoop)
+	conveyor.MustAddOperation[int, int](cnv, op, conveyor.WorkerModeLoop)
+	conveyor.MustAddSink[int](cnv, snk, conveyor.WorkerModeLoop)
+
+	conveyortest.RunToCompletion(t, cnv, 5*time.Second)
+
+	injected := src.FaultStats().Errors + op.FaultStats().Errors + snk.FaultStats().Errors
+	assert.Positive(t, injected)
+	conveyortest.AssertErrorTotal(t, cnv.Errors(), injected)
+	collected := snk.SinkExecutor.(*conveyortest.CollectSink[int]).Len()
+	assert.Equal(t, 50-int(injected), collected)
+}
+
+// TestFaults_EndlessSource verifies that a panic stops a source that never runs out of items.
+func TestFaults_EndlessSource(t *testing.T) {
+	cnv, err := conveyor.NewConveyor("endless", 10)
+	require.NoError(t, err)
+
+	endless := func(yield func(int) bool) {
+		for i := 0; yield(i); i++ {
+		}
+	}
+	src := conveyortest.NewFaultySource[int](conveyor.SourceFromSeq("src", endless), conveyortest.Faults{PanicEvery: 20})
+	conveyor.MustAddSource[int](cnv, src, conveyor.WorkerModeLoop)
+	conveyor.MustAddSink[int](cnv, conveyortest.NewCollectSink[int]("snk"), conveyor.WorkerModeLoop)
+
+	conveyortest.RunToCompletion(t, cnv, 5*time.Second)
+	assert.Equal(t, int64(1), src.FaultStats().Panics)
+	conveyortest.AssertErrorCount(t, cnv.Errors(), "src", &conveyor.PanicError{}, 1)
+}
+
+// TestFaults_Hang verifies that a hanging executor holds the conveyor until it times out.
+func TestFaults_Hang(t *testing.T) {
+	cnv, err := conveyor.NewConveyor("hang", 10)
+	require.NoError(t, err)
+	cnv.SetTimeout(50 * time.Millisecond)
+
+	snk := conveyortest.NewFaultySink[int](conveyortest.NewCollectSink[int]("snk"), conveyortest.Faults{HangEvery: 3})
+	conveyor.MustAddSource[int](cnv, conveyortest.NewSliceSource("src", sequence(5)...), conveyor.WorkerModeLoop)
+	conveyor.MustAddSink[int](cnv, snk, conveyor.WorkerModeLoop)
+
+	require.NoError(t, cnv.Start())
+	assert.Equal(t, conveyor.StateInternalError, cnv.CurrentState())
+	assert.Equal(t, int64(1), snk.FaultStats().Hangs)
+}
+
+func TestFaults_Latency(t *testing.T) {
+	src := conveyortest.NewFaultySource[int](conveyortest.NewSliceSource("src", 1, 2, 3),
+		conveyortest.Faults{Latency: conveyortest.FixedLatency(5 * time.Millisecond)})
+
+	cnv, err := conveyor.NewConveyor("latency", 10)
+	require.NoError(t, err)
+	conveyor.MustAddSource[int](cnv, src, conveyor.WorkerModeTransaction)
+	conveyor.MustAddSink[int](cnv, conveyortest.NewCollectSink[int]("snk"), conveyor.WorkerModeTransaction)
+
+	begin := time.Now()
+	conveyortest.RunToCompletion(t, cnv, time.Second)
+	assert.GreaterOrEqual(t, time.Since(begin), 15*time.Millisecond)
+}
+
+func TestLatencyDists(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	uniform := conveyortest.UniformLatency(time.Millisecond, 2*time.Millisecond)
+	normal := conveyortest.NormalLatency(time.Millisecond, 10*time.Millisecond)
+	exponential := conveyortest.ExponentialLatency(time.Millisecond)
+
+	for i := 0; i < 1000; i++ {
+		d := uniform(rng)
+		assert.True(t, d >= time.Millisecond && d < 2*time.Millisecond, "uniform draw %v out of range", d)
+		assert.GreaterOrEqual(t, normal(rng), time.Duration(0))
+		assert.GreaterOrEqual(t, exponential(rng), time.Duration(0))
+	}
+}
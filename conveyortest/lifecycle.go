@@ -0,0 +1,151 @@
+// Package conveyortest provides helpers to test code built on top of conveyor.
+package conveyortest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/tushar2708/conveyor"
+)
+
+// TestLifeCycleHandler runs a conformance suite against a LifeCycleHandler implementation.
+// newHandler must return a fresh handler, with no state, status message or progress stored yet,
+// every time it's called. Use it to check your own handler, e.g. one backed by redis:
+//
+//	func TestRedisHandler(t *testing.T) {
+//	    conveyortest.TestLifeCycleHandler(t, func(t *testing.T) conveyor.LifeCycleHandler {
+//	        return NewRedisHandler(client, t.Name())
+//	    })
+//	}
+func TestLifeCycleHandler(t *testing.T, newHandler func(t *testing.T) conveyor.LifeCycleHandler) {
+	t.Helper()
+
+	t.Run("Empty", func(t *testing.T) {
+		lch := newHandler(t)
+		expectGet(t, "GetState", lch.GetState, "")
+		expectGet(t, "GetStatusMsg", lch.GetStatusMsg, "")
+		expectGet(t, "GetProgress", lch.GetProgress, "")
+	})
+
+	t.Run("Markers", func(t *testing.T) {
+		lch := newHandler(t)
+		markers := []struct {
+			state string
+			mark  func() error
+		}{
+			{conveyor.StatusPreparing, lch.MarkPreparing},
+			{conveyor.StateStarted, lch.MarkStarted},
+			{conveyor.StateToKill, lch.MarkToKill},
+			{conveyor.StateKilled, lch.MarkKilled},
+			{conveyor.StateFinished, lch.MarkFinished},
+			{conveyor.StateInternalError, lch.MarkError},
+		}
+		for _, m := range markers {
+			if err := m.mark(); err != nil {
+				t.Fatalf("marking '%s' failed: %v", m.state, err)
+			}
+			expectGet(t, "GetState", lch.GetState, m.state)
+		}
+	})
+
+	t.Run("StatusMsg", func(t *testing.T) {
+		lch := newHandler(t)
+		for _, msg := range []string{"running query", "", "processed 5 batches"} {
+			if err := lch.UpdateStatusMsg(msg); err != nil {
+				t.Fatalf("UpdateStatusMsg(%q) failed: %v", msg, err)
+			}
+			expectGet(t, "GetStatusMsg", lch.GetStatusMsg, msg)
+		}
+	})
+
+	t.Run("Progress", func(t *testing.T) {
+		lch := newHandler(t)
+		for _, progress := range []string{"0.00", "42.50", "100.00"} {
+			if err := lch.UpdateProgress(progress); err != nil {
+				t.Fatalf("UpdateProgress(%q) failed: %v", progress, err)
+			}
+			expectGet(t, "GetProgress", lch.GetProgress, progress)
+		}
+	})
+
+	t.Run("IndependentFields", func(t *testing.T) {
+		lch := newHandler(t)
+		if err := lch.MarkStarted(); err != nil {
+			t.Fatalf("MarkStarted failed: %v", err)
+		}
+		if err := lch.UpdateStatusMsg("status"); err != nil {
+			t.Fatalf("UpdateStatusMsg failed: %v", err)
+		}
+		if err := lch.UpdateProgress("10.00"); err != nil {
+			t.Fatalf("UpdateProgress failed: %v", err)
+		}
+		expectGet(t, "GetState", lch.GetState, conveyor.StateStarted)
+		expectGet(t, "GetStatusMsg", lch.GetStatusMsg, "status")
+		expectGet(t, "GetProgress", lch.GetProgress, "10.00")
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		lch := newHandler(t)
+		const writers = 4
+
+		var wg sync.WaitGroup
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 3; j++ {
+					_ = lch.UpdateStatusMsg(fmt.Sprintf("msg-%d", i))
+					_ = lch.UpdateProgress(fmt.Sprintf("%d", i))
+					_ = lch.MarkStarted()
+					_, _ = lch.GetState()
+					_, _ = lch.GetStatusMsg()
+					_, _ = lch.GetProgress()
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		msg, err := lch.GetStatusMsg()
+		if err != nil {
+			t.Fatalf("GetStatusMsg failed: %v", err)
+		}
+		var found bool
+		for i := 0; i < writers; i++ {
+			found = found || msg == fmt.Sprintf("msg-%d", i)
+		}
+		if !found {
+			t.Errorf("GetStatusMsg returned %q, which no writer has written", msg)
+		}
+		expectGet(t, "GetState", lch.GetState, conveyor.StateStarted)
+	})
+
+	t.Run("DrivenByConveyor", func(t *testing.T) {
+		lch := newHandler(t)
+		cnv, err := conveyor.NewConveyor(t.Name(), 10)
+		if err != nil {
+			t.Fatalf("NewConveyor failed: %v", err)
+		}
+		cnv.SetLifeCycleHandler(lch)
+
+		conveyor.MustAddSource[int](cnv, NewSliceSource("src", 3, 2, 1), conveyor.WorkerModeTransaction)
+		conveyor.MustAddSink[int](cnv, NewCollectSink[int]("snk"), conveyor.WorkerModeTransaction)
+
+		if err := cnv.Start(); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		expectGet(t, "GetState", lch.GetState, conveyor.StateFinished)
+	})
+}
+
+// expectGet fails the test if get returns an error, or a value other than want
+func expectGet(t *testing.T, name string, get func() (string, error), want string) {
+	t.Helper()
+	got, err := get()
+	if err != nil {
+		t.Fatalf("%s failed: %v", name, err)
+	}
+	if got != want {
+		t.Errorf("%s returned %q, want %q", name, got, want)
+	}
+}
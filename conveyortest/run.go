@@ -0,0 +1,71 @@
+package conveyortest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tushar2708/conveyor"
+)
+
+// RunToCompletion starts cnv, and fails the test unless it finishes on its own within timeout.
+// A conveyor that's still running after timeout is stopped before failing, so it doesn't outlive the test.
+func RunToCompletion(t *testing.T, cnv *conveyor.Conveyor, timeout time.Duration) {
+	t.Helper()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cnv.Start()
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("conveyor '%s' failed to start: %v", cnv.Name, err)
+		}
+	case <-time.After(timeout):
+		cnv.Stop()
+		select {
+		case <-done:
+			t.Fatalf("conveyor '%s' didn't finish within %v", cnv.Name, timeout)
+		case <-time.After(timeout):
+			t.Fatalf("conveyor '%s' didn't finish within %v, and didn't return after being stopped", cnv.Name, timeout)
+		}
+	}
+
+	if state := cnv.CurrentState(); state != conveyor.StateFinished {
+		t.Fatalf("conveyor '%s' ended as '%s', want '%s'", cnv.Name, state, conveyor.StateFinished)
+	}
+}
+
+// AssertNoErrors fails the test if any error has been recorded in stats
+func AssertNoErrors(t *testing.T, stats *conveyor.ErrorStats) {
+	t.Helper()
+	if total := stats.Total(); total != 0 {
+		t.Errorf("expected no errors, got %d: %v", total, stats.Snapshot())
+	}
+}
+
+// AssertErrorTotal fails the test unless exactly want errors have been recorded in stats
+func AssertErrorTotal(t *testing.T, stats *conveyor.ErrorStats, want int64) {
+	t.Helper()
+	if total := stats.Total(); total != want {
+		t.Errorf("expected %d errors, got %d: %v", want, total, stats.Snapshot())
+	}
+}
+
+// AssertStageErrors fails the test unless exactly want errors have been recorded for stage, whatever their type
+func AssertStageErrors(t *testing.T, stats *conveyor.ErrorStats, stage string, want int64) {
+	t.Helper()
+	if got := stats.StageTotal(stage); got != want {
+		t.Errorf("expected %d errors for stage '%s', got %d: %v", want, stage, got, stats.Snapshot())
+	}
+}
+
+// AssertErrorCount fails the test unless exactly want errors have been recorded for stage,
+// with the same root error type as err
+func AssertErrorCount(t *testing.T, stats *conveyor.ErrorStats, stage string, err error, want int64) {
+	t.Helper()
+	if got := stats.Count(stage, err); got != want {
+		t.Errorf("expected %d errors of type %T for stage '%s', got %d: %v", want, err, stage, got, stats.Snapshot())
+	}
+}
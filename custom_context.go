@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -26,6 +27,67 @@ type CtxData struct {
 
 	// errorStats is a pointer so that all derived contexts (WithCancel, WithTimeout) share the same instance.
 	errorStats *ErrorStats
+
+	// skipped counts the progress units of the items operations skip with ErrSkipItem, as they never reach a sink
+	skipped *deliveryCounter
+
+	// closer guards "logs" & "status" against being closed by Cancel() while a message is being sent.
+	// It is a pointer so that all derived contexts close the shared channels only once.
+	closer *channelCloser
+
+	// lastStatus holds the latest message passed to SendStatus(), shared by all derived contexts,
+	// so that it can be mirrored into a LifeCycleHandler even if nobody reads from Status().
+	lastStatus *atomic.Pointer[string]
+}
+
+// channelCloser lets any number of senders publish on the context's channels,
+// while making sure that they are closed exactly once, and never during a send.
+type channelCloser struct {
+	mu     sync.RWMutex
+	closed bool
+}
+
+// sendLatest publishes v on ch without blocking. If the buffer is full, the oldest value is
+// thrown away to make room. Returns without sending, once the channels are closed.
+func sendLatest[T any](cc *channelCloser, ch chan T, v T) {
+	if cc != nil {
+		cc.mu.RLock()
+		defer cc.mu.RUnlock()
+		if cc.closed {
+			return
+		}
+	}
+
+	select {
+	case ch <- v:
+		return
+	default:
+	}
+	// If not consumed, throw away old value and update with new value
+	select {
+	case <-ch:
+	default:
+	}
+	select {
+	case ch <- v:
+	default:
+	}
+}
+
+// close closes the given channels, unless they have already been closed.
+func (cc *channelCloser) close(logs chan Message, status chan string) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.closed {
+		return
+	}
+	cc.closed = true
+	if logs != nil {
+		close(logs)
+	}
+	if status != nil {
+		close(status)
+	}
 }
 
 // CnvContext is an interface, which is satisfied by CnvContext.
@@ -68,6 +130,21 @@ func (ctx *cnvContext) RecordError(stage string, err error) {
 	}
 }
 
+// skipItem counts the progress units of an item skipped by an operation.
+// It is a no-op when skipped has not been initialized.
+func (ctx *cnvContext) skipItem(item any) {
+	if ctx.Data.skipped != nil {
+		ctx.Data.skipped.add(item)
+	}
+}
+
+// skipItem counts item as skipped with ErrSkipItem, if ctx belongs to a conveyor
+func skipItem(ctx CnvContext, item any) {
+	if sc, ok := ctx.(interface{ skipItem(item any) }); ok {
+		sc.skipItem(item)
+	}
+}
+
 // Errors returns the shared ErrorStats instance for this pipeline.
 // Returns nil when errorStats has not been initialized.
 func (ctx *cnvContext) Errors() *ErrorStats {
@@ -108,11 +185,16 @@ func (ctx *cnvContext) Cancel() {
 	}
 
 	ctx.cancelOnce.Do(func() {
+		if ctx.Data.closer != nil {
+			ctx.Data.closer.close(ctx.Data.logs, ctx.Data.status)
+			return
+		}
+
 		if ctx.Data.logs != nil {
 			close(ctx.Data.logs)
 		}
 
-		if ctx.Data.logs != nil {
+		if ctx.Data.status != nil {
 			close(ctx.Data.status)
 		}
 	})
@@ -137,18 +219,14 @@ func (ctx *cnvContext) SendLog(logLevel int32, text string, err error) {
 	default:
 	}
 
-	select {
-	case <-ctx.Done():
-		return
-	case ctx.Data.logs <- msg:
-	default:
-		<-ctx.Data.logs // If not consumed, throw away old status and update with new value
-		ctx.Data.logs <- msg
-	}
+	sendLatest(ctx.Data.closer, ctx.Data.logs, msg)
 }
 
 // SendStatus sends conveyor's internal logs to be available on conveyor.Status()
 func (ctx *cnvContext) SendStatus(status string) {
+	if ctx.Data.lastStatus != nil {
+		ctx.Data.lastStatus.Store(&status)
+	}
 
 	select {
 	case <-ctx.Done():
@@ -156,12 +234,61 @@ func (ctx *cnvContext) SendStatus(status string) {
 	default:
 	}
 
-	select {
-	case <-ctx.Done():
+	sendLatest(ctx.Data.closer, ctx.Data.status, status)
+}
+
+// sourceContext is the context given to source workers. It's done either when the conveyor's
+// context is done, or when the conveyor is drained, so that sources stop producing while
+// downstream nodes carry on with the items that are already in the pipeline.
+// Everything else is delegated to the conveyor's context, including Cancel(), unless it's derived.
+type sourceContext struct {
+	CnvContext
+	drainCtx context.Context
+	cancel   context.CancelFunc // only set on the contexts derived with WithCancel() or WithTimeout()
+}
+
+// newSourceContext derives a sourceContext from the conveyor's context, along with the function that drains it
+func newSourceContext(ctx CnvContext) (*sourceContext, context.CancelFunc) {
+	drainCtx, drain := context.WithCancel(ctx)
+	return &sourceContext{CnvContext: ctx, drainCtx: drainCtx}, drain
+}
+
+// Done is closed once the conveyor is either drained or done
+func (sc *sourceContext) Done() <-chan struct{} {
+	return sc.drainCtx.Done()
+}
+
+// Err returns context.Canceled once the conveyor is drained, or the conveyor's own error
+func (sc *sourceContext) Err() error {
+	return sc.drainCtx.Err()
+}
+
+// WithCancel derives a sourceContext that is done once Cancel() is called on it, or once sc is done
+func (sc *sourceContext) WithCancel() CnvContext {
+	drainCtx, cancel := context.WithCancel(sc.drainCtx)
+	return &sourceContext{CnvContext: sc.CnvContext, drainCtx: drainCtx, cancel: cancel}
+}
+
+// WithTimeout derives a sourceContext that is done after timeout, once Cancel() is called on it, or once sc is done
+func (sc *sourceContext) WithTimeout(timeout time.Duration) CnvContext {
+	drainCtx, cancel := context.WithTimeout(sc.drainCtx, timeout)
+	return &sourceContext{CnvContext: sc.CnvContext, drainCtx: drainCtx, cancel: cancel}
+}
+
+// Cancel only cancels a derived sourceContext, and the whole conveyor otherwise
+func (sc *sourceContext) Cancel() {
+	if sc.cancel != nil {
+		sc.cancel()
 		return
-	case ctx.Data.status <- status:
-	default:
-		<-ctx.Data.status // If not consumed, throw away old status and update with new value
-		ctx.Data.status <- status
 	}
+	sc.CnvContext.Cancel()
+}
+
+// pipelineContext returns the conveyor's context that ctx derives from. Unlike a sourceContext,
+// it isn't done while the conveyor is draining, so it tells if the rest of the pipeline is still running.
+func pipelineContext(ctx CnvContext) CnvContext {
+	if sc, ok := ctx.(*sourceContext); ok {
+		return sc.CnvContext
+	}
+	return ctx
 }
@@ -0,0 +1,144 @@
+package conveyor
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DedupeConfig sets which items a Dedupe drops, and how many keys it remembers
+type DedupeConfig[T any, K comparable] struct {
+	// Key returns the key of an item, e.g. its message ID
+	Key func(item T) K
+	// TTL is how long a key is remembered, since its last item was seen
+	TTL time.Duration
+	// MaxKeys is how many keys are remembered, the least recently seen being forgotten first.
+	// At least one of TTL & MaxKeys must be set
+	MaxKeys int
+}
+
+// Dedupe is an OperationExecutor dropping the items whose key was seen recently, e.g. the messages an
+// at-least-once source delivers again. Duplicates are skipped without being recorded as errors, and counted
+// by Duplicates(). A key is remembered once its item went through. With EnableAcks(), it's forgotten if the item
+// is nacked, so that the source's redelivery isn't taken for a duplicate.
+type Dedupe[T any, K comparable] struct {
+	ConcreteOperationExecutor[T, T]
+	concurrency int
+	cfg         DedupeConfig[T, K]
+	duplicates  atomic.Int64
+
+	mu     sync.Mutex
+	recent *list.List          // *dedupeKey[K], the most recently seen first
+	keys   map[K]*list.Element // the elements of recent, by key
+}
+
+type dedupeKey[K comparable] struct {
+	key  K
+	seen time.Time
+}
+
+// NewDedupe returns a Dedupe running concurrency workers, in either mode
+func NewDedupe[T any, K comparable](name string, concurrency int, cfg DedupeConfig[T, K]) (*Dedupe[T, K], error) {
+	if cfg.Key == nil || cfg.TTL < 0 || cfg.MaxKeys < 0 || (cfg.TTL == 0 && cfg.MaxKeys == 0) {
+		return nil, ErrInvalidDedupe
+	}
+	return &Dedupe[T, K]{
+		ConcreteOperationExecutor: ConcreteOperationExecutor[T, T]{Name: name},
+		concurrency:               workerCount(concurrency),
+		cfg:                       cfg,
+		recent:                    list.New(),
+		keys:                      make(map[K]*list.Element),
+	}, nil
+}
+
+// AddDedupe adds a Dedupe after the last node, in WorkerModeTransaction, so that it works with tracked items too.
+// A duplicate that's tracked is acked.
+func AddDedupe[T any, K comparable](cnv *Conveyor, name string, concurrency int, cfg DedupeConfig[T, K]) (*Dedupe[T, K], error) {
+	dd, err := NewDedupe(name, concurrency, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := AddOperation[T, T](cnv, dd, WorkerModeTransaction); err != nil {
+		return nil, err
+	}
+	return dd, nil
+}
+
+// Duplicates returns the number of items dropped so far
+func (dd *Dedupe[T, K]) Duplicates() int64 {
+	return dd.duplicates.Load()
+}
+
+func (dd *Dedupe[T, K]) Count() int {
+	return dd.concurrency
+}
+
+// Execute returns inData, or ErrSkipItem if its key was seen recently
+func (dd *Dedupe[T, K]) Execute(ctx CnvContext, inData T) (T, error) {
+	key := dd.cfg.Key(inData)
+	if dd.seen(key, time.Now()) {
+		dd.duplicates.Add(1)
+		var zero T
+		return zero, ErrSkipItem
+	}
+	onNack(ctx, func() { dd.forgetKey(key) })
+	return inData, nil
+}
+
+func (dd *Dedupe[T, K]) ExecuteLoop(ctx CnvContext, inChan <-chan T, outChan chan<- T) error {
+	for in := range inChan {
+		if dd.seen(dd.cfg.Key(in), time.Now()) {
+			dd.duplicates.Add(1)
+			skipItem(ctx, in)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case outChan <- in:
+		}
+	}
+	return nil
+}
+
+// seen records key as seen at now, and tells whether it already was, and is still remembered
+func (dd *Dedupe[T, K]) seen(key K, now time.Time) bool {
+	dd.mu.Lock()
+	defer dd.mu.Unlock()
+
+	// Keys are in the order they were last seen, so the expired ones are at the back
+	for dd.cfg.TTL > 0 {
+		oldest := dd.recent.Back()
+		if oldest == nil || now.Sub(oldest.Value.(*dedupeKey[K]).seen) < dd.cfg.TTL {
+			break
+		}
+		dd.forget(oldest)
+	}
+
+	if el, ok := dd.keys[key]; ok {
+		el.Value.(*dedupeKey[K]).seen = now
+		dd.recent.MoveToFront(el)
+		return true
+	}
+
+	dd.keys[key] = dd.recent.PushFront(&dedupeKey[K]{key: key, seen: now})
+	if dd.cfg.MaxKeys > 0 && dd.recent.Len() > dd.cfg.MaxKeys {
+		dd.forget(dd.recent.Back())
+	}
+	return false
+}
+
+// forgetKey forgets key, if it's still remembered
+func (dd *Dedupe[T, K]) forgetKey(key K) {
+	dd.mu.Lock()
+	defer dd.mu.Unlock()
+	if el, ok := dd.keys[key]; ok {
+		dd.forget(el)
+	}
+}
+
+func (dd *Dedupe[T, K]) forget(el *list.Element) {
+	dd.recent.Remove(el)
+	delete(dd.keys, el.Value.(*dedupeKey[K]).key)
+}
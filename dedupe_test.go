@@ -0,0 +1,134 @@
+package conveyor
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// redelivered yields 0, 1, 2... n-1, twice
+func redelivered(n int) []int {
+	return append(slices.Collect(upTo(n)), slices.Collect(upTo(n))...)
+}
+
+func identity(n int) int { return n }
+
+// TestDedupe_BothModes verifies that duplicates are dropped, and counted without being recorded as errors.
+func TestDedupe_BothModes(t *testing.T) {
+	for _, mode := range []WorkerMode{WorkerModeTransaction, WorkerModeLoop} {
+		for _, batched := range []bool{false, true} {
+			cnv, _ := NewConveyor("dedupe", 10)
+			if batched {
+				cnv.EnableBatching(8, time.Millisecond)
+			}
+			require.NoError(t, AddSource[int](cnv, SourceFromSeq("src", slices.Values(redelivered(50))), WorkerModeLoop))
+			dd, err := NewDedupe("dedupe", 4, DedupeConfig[int, int]{Key: identity, MaxKeys: 100})
+			require.NoError(t, err)
+			require.NoError(t, AddOperation[int, int](cnv, dd, mode))
+
+			results, err := Collect[int](context.Background(), cnv)
+			require.NoError(t, err)
+			slices.Sort(results)
+			assert.Equal(t, slices.Collect(upTo(50)), results, "mode: %d, batched: %v", mode, batched)
+			assert.Equal(t, int64(50), dd.Duplicates())
+			assert.Equal(t, int64(50), cnv.dropped.delivered(), "skipped items count towards progress")
+			assert.Zero(t, cnv.Errors().Total())
+		}
+	}
+}
+
+// TestDedupe_Acks verifies that duplicates of tracked items are acked.
+func TestDedupe_Acks(t *testing.T) {
+	src := newAckingSource(SourceFromSeq("src", slices.Values(redelivered(20))))
+
+	cnv, _ := NewConveyor("dedupe_acks", 10)
+	cnv.EnableAcks()
+	require.NoError(t, AddSource[int](cnv, src, WorkerModeLoop))
+	dd, err := AddDedupe(cnv, "dedupe", 2, DedupeConfig[int, int]{Key: identity, TTL: time.Minute})
+	require.NoError(t, err)
+
+	results, err := Collect[int](context.Background(), cnv)
+	require.NoError(t, err)
+	assert.Len(t, results, 20)
+	assert.Equal(t, int64(20), dd.Duplicates())
+	assert.Equal(t, slices.Sorted(slices.Values(redelivered(20))), src.acked())
+	assert.Empty(t, src.nacks)
+}
+
+// nackSignal closes nacked once an item is nacked
+type nackSignal struct {
+	*ackingSource
+	nacked chan struct{}
+}
+
+func (ns *nackSignal) OnNack(item int, err error) {
+	ns.ackingSource.OnNack(item, err)
+	close(ns.nacked)
+}
+
+// TestDedupe_Nacked verifies that the key of a nacked item is forgotten, so that its redelivery goes through.
+func TestDedupe_Nacked(t *testing.T) {
+	nacked := make(chan struct{})
+	src := &nackSignal{nacked: nacked, ackingSource: newAckingSource(SourceFromSeq("src", func(yield func(int) bool) {
+		for i := range 10 {
+			if !yield(i) {
+				return
+			}
+		}
+		<-nacked
+		yield(3) // delivered again
+	}))}
+
+	cnv, _ := NewConveyor("dedupe_nacked", 10)
+	cnv.EnableAcks()
+	require.NoError(t, AddSource[int](cnv, src, WorkerModeLoop))
+	dd, err := AddDedupe(cnv, "dedupe", 2, DedupeConfig[int, int]{Key: identity, MaxKeys: 100})
+	require.NoError(t, err)
+	var failed atomic.Bool
+	var mu sync.Mutex
+	var received []int
+	require.NoError(t, AddSink[int](cnv, SinkFunc("snk", 2, func(ctx CnvContext, in int) error {
+		if in == 3 && failed.CompareAndSwap(false, true) {
+			return errors.New("failed")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, in)
+		return nil
+	}), WorkerModeTransaction))
+	require.NoError(t, cnv.Start())
+
+	assert.Equal(t, slices.Collect(upTo(10)), slices.Sorted(slices.Values(received)))
+	assert.Zero(t, dd.Duplicates())
+	assert.Equal(t, slices.Collect(upTo(10)), src.acked())
+	assert.Contains(t, src.nacks, 3)
+}
+
+// TestDedupe_Bounded verifies that keys are forgotten once they're beyond MaxKeys, or their TTL.
+func TestDedupe_Bounded(t *testing.T) {
+	dd, _ := NewDedupe("dedupe", 1, DedupeConfig[string, string]{Key: func(s string) string { return s }, MaxKeys: 2})
+	now := time.Now()
+	assert.False(t, dd.seen("a", now))
+	assert.False(t, dd.seen("b", now))
+	assert.True(t, dd.seen("a", now), "a is now the most recently seen")
+	assert.False(t, dd.seen("c", now), "forgets b")
+	assert.True(t, dd.seen("a", now))
+	assert.False(t, dd.seen("b", now))
+
+	dd, _ = NewDedupe("dedupe", 1, DedupeConfig[string, string]{Key: func(s string) string { return s }, TTL: time.Minute})
+	assert.False(t, dd.seen("a", now))
+	assert.True(t, dd.seen("a", now.Add(50*time.Second)))
+	assert.True(t, dd.seen("a", now.Add(100*time.Second)), "the TTL starts again at every item")
+	assert.False(t, dd.seen("a", now.Add(200*time.Second)))
+	assert.Equal(t, 1, dd.recent.Len())
+
+	_, err := NewDedupe("dedupe", 1, DedupeConfig[int, int]{Key: identity})
+	assert.ErrorIs(t, err, ErrInvalidDedupe)
+}
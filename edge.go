@@ -0,0 +1,244 @@
+package conveyor
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy decides what happens to an item sent to an edge whose buffer is full
+type OverflowPolicy uint8
+
+const (
+	// OverflowBlock makes the previous stage wait for room in the buffer. It's the default.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest drops the item being sent
+	OverflowDropNewest
+	// OverflowDropOldest drops the item that has been waiting in the buffer the longest, to make room for the new one
+	OverflowDropOldest
+	// OverflowSample keeps one item out of EdgeConfig.SampleRate, in place of the oldest one, and drops the others
+	OverflowSample
+	// OverflowSpill writes the items beyond the buffer to disk, as configured by EdgeConfig.Spill,
+	// and replays them in order as the stage catches up. Nothing is dropped.
+	OverflowSpill
+)
+
+// DefaultSampleRate is the rate of OverflowSample, if EdgeConfig.SampleRate is <= 0
+const DefaultSampleRate = 10
+
+// EdgeConfig configures the edge feeding a stage, i.e. the channel it reads its input from
+type EdgeConfig struct {
+	// Buffer is the length of the edge's channel, the conveyor's bufferLen if <= 0.
+	// With batching, it's a number of chunks.
+	Buffer int
+	// Overflow is what happens to items sent while the buffer is full
+	Overflow OverflowPolicy
+	// SampleRate is used by OverflowSample: while the buffer is full, one item out of SampleRate is kept
+	SampleRate int
+	// Spill is used by OverflowSpill: where and how the items beyond the buffer are written to disk
+	Spill SpillConfig
+}
+
+// SetEdge configures the edge feeding the stage with the given name, that is its buffer length, and what happens
+// to the items sent while it's full. With a policy other than OverflowBlock, the previous stage never waits
+// for this one, and the items dropped are counted in Drops(). Segment files of OverflowSpill are removed
+// once the conveyor is done.
+// Will have no effect, once you add your first node
+func (cnv *Conveyor) SetEdge(stage string, edge EdgeConfig) *Conveyor {
+	if !cnv.openForConfigChange {
+		return cnv
+	}
+	if edge.SampleRate <= 0 {
+		edge.SampleRate = DefaultSampleRate
+	}
+	if cnv.edges == nil {
+		cnv.edges = make(map[string]EdgeConfig)
+	}
+	cnv.edges[stage] = edge
+	return cnv
+}
+
+// Drops gives the number of items dropped by the conveyor's edges, per stage
+func (cnv *Conveyor) Drops() *DropStats {
+	return cnv.dropStats
+}
+
+// edgeFor returns the configuration of the edge feeding stage, with the conveyor's defaults applied
+func (cnv *Conveyor) edgeFor(stage string) *edge {
+	cfg := cnv.edges[stage]
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = cnv.bufferLen
+	}
+	return &edge{EdgeConfig: cfg, stage: stage, drops: cnv.dropStats, units: &cnv.dropped, spills: &cnv.spills}
+}
+
+// DropStats counts the items dropped by edges with an overflow policy, per stage they were sent to.
+// All methods are safe for concurrent use.
+type DropStats struct {
+	total   atomic.Int64
+	byStage sync.Map // key: stage name → *atomic.Int64
+}
+
+func (ds *DropStats) record(stage string) {
+	ds.total.Add(1)
+	v, _ := ds.byStage.LoadOrStore(stage, new(atomic.Int64))
+	v.(*atomic.Int64).Add(1)
+}
+
+// Total returns the number of items dropped by every edge
+func (ds *DropStats) Total() int64 {
+	return ds.total.Load()
+}
+
+// Count returns the number of items dropped by the edge feeding stage
+func (ds *DropStats) Count(stage string) int64 {
+	v, ok := ds.byStage.Load(stage)
+	if !ok {
+		return 0
+	}
+	return v.(*atomic.Int64).Load()
+}
+
+// Snapshot returns a point-in-time copy of the per-stage counts
+func (ds *DropStats) Snapshot() map[string]int64 {
+	result := make(map[string]int64)
+	ds.byStage.Range(func(k, v any) bool {
+		result[k.(string)] = v.(*atomic.Int64).Load()
+		return true
+	})
+	return result
+}
+
+// edge is the configuration of the edge feeding a stage, along with where its drops are counted
+type edge struct {
+	EdgeConfig
+	stage  string
+	drops  *DropStats
+	units  *deliveryCounter // progress units of the dropped items, as they will never reach a sink
+	spills *spillDirs       // temp directories of OverflowSpill, removed by the conveyor's cleanup()
+}
+
+// edgeWorker is implemented by workers whose input edges can be configured with SetEdge()
+type edgeWorker interface {
+	stage() string
+	setEdge(edge *edge)
+}
+
+func (e *edge) dropped(item any) {
+	e.drops.record(e.stage)
+	e.units.add(item)
+	if s, ok := item.(settler); ok {
+		s.settle()
+	}
+}
+
+// dropsItems returns true if the edge never makes the previous stage wait
+func (e *edge) dropsItems() bool {
+	return e != nil && e.Overflow != OverflowBlock
+}
+
+// dropItem and dropChunk count what's dropped from an edge of single items, or of chunks
+func dropItem[T any](e *edge) func(T) {
+	return func(v T) { e.dropped(v) }
+}
+
+func dropChunk[T any](e *edge) func([]T) {
+	return func(chunk []T) {
+		for _, v := range chunk {
+			e.dropped(v)
+		}
+	}
+}
+
+// overflowInlet returns the channel the previous stage writes to, for an edge with an overflow policy.
+// Once the worker starts, a relay moves its items to buffer, the channel the stage reads from,
+// applying the policy whenever buffer is full. It closes buffer once the previous stage closes the inlet.
+func overflowInlet[E any](wp *WPool, e *edge, buffer chan E, drop func(E)) chan E {
+	inlet := make(chan E)
+	wp.onStart = append(wp.onStart, func(ctx CnvContext) {
+		if e.Overflow == OverflowSpill {
+			wp.relay(func() { relaySpill(ctx, e, inlet, buffer, drop) })
+			return
+		}
+		wp.relay(func() { relayOverflow(e, inlet, buffer, drop) })
+	})
+	return inlet
+}
+
+func relayOverflow[E any](e *edge, inlet <-chan E, buffer chan E, drop func(E)) {
+	overflowed := 0
+	for v := range inlet {
+		select {
+		case buffer <- v:
+			continue
+		default:
+		}
+
+		switch e.Overflow {
+		case OverflowDropNewest:
+			drop(v)
+		case OverflowDropOldest:
+			replaceOldest(buffer, v, drop)
+		case OverflowSample:
+			overflowed++
+			if overflowed%e.SampleRate != 0 {
+				drop(v)
+				continue
+			}
+			replaceOldest(buffer, v, drop)
+		}
+	}
+	close(buffer)
+}
+
+// replaceOldest puts v in buffer, dropping the items that have been waiting the longest until there's room
+func replaceOldest[E any](buffer chan E, v E, drop func(E)) {
+	for {
+		select {
+		case buffer <- v:
+			return
+		default:
+		}
+
+		select {
+		case old := <-buffer:
+			drop(old)
+		default:
+		}
+	}
+}
+
+// inlet returns the channel the previous stage writes to, for the input at index, if its edge drops items on overflow
+func (wp *WPool) inlet(index int) (any, bool) {
+	if index < len(wp.inlets) && wp.inlets[index] != nil {
+		return wp.inlets[index], true
+	}
+	return nil, false
+}
+
+// clearInlets forgets the worker's overflow inlets, once its input channels are replaced
+func (wp *WPool) clearInlets() {
+	wp.inlets = nil
+	wp.onStart = nil
+}
+
+// startInlets starts the relays of the worker's overflow inlets
+func (wp *WPool) startInlets(ctx CnvContext) {
+	for _, start := range wp.onStart {
+		start(ctx)
+	}
+	wp.onStart = nil
+}
+
+// stage returns the name of the stage the worker runs
+func (cnw *ConcreteNodeWorker) stage() string {
+	return cnw.Executor.GetName()
+}
+
+func (wp *ConcreteJointWorker) stage() string {
+	return wp.Executor.GetName()
+}
+
+// setEdge sets the configuration of the worker's input edges, before its channels are created
+func (wp *WPool) setEdge(edge *edge) {
+	wp.edge = edge
+}
@@ -0,0 +1,99 @@
+package conveyor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runOverflow sends 0..999 to a sink whose edge holds 4 items, and only lets it read once the edge
+// has dropped everything it's going to drop
+func runOverflow(t *testing.T, policy OverflowPolicy) (*Conveyor, []int) {
+	t.Helper()
+	release := make(chan struct{})
+	var received []int
+
+	cnv, _ := NewConveyor("overflow", 10)
+	cnv.SetEdge("snk", EdgeConfig{Buffer: 4, Overflow: policy})
+	require.NoError(t, AddSource[int](cnv, SourceFromSeq("src", upTo(1000)), WorkerModeLoop))
+	require.NoError(t, AddSink[int](cnv, SinkLoopFunc("snk", 1, func(ctx CnvContext, in <-chan int) error {
+		<-release
+		for v := range in {
+			received = append(received, v)
+		}
+		return nil
+	}), WorkerModeLoop))
+
+	done := make(chan error, 1)
+	go func() { done <- cnv.Start() }()
+	require.Eventually(t, func() bool { return cnv.Drops().Count("snk") == 996 }, time.Second, time.Millisecond)
+	close(release)
+	require.NoError(t, <-done)
+	return cnv, received
+}
+
+func TestEdge_DropNewest(t *testing.T) {
+	cnv, received := runOverflow(t, OverflowDropNewest)
+	assert.Equal(t, []int{0, 1, 2, 3}, received)
+	assert.Equal(t, int64(996), cnv.Drops().Total())
+	assert.Equal(t, map[string]int64{"snk": 996}, cnv.Drops().Snapshot())
+	assert.Equal(t, int64(996), cnv.dropped.delivered(), "dropped items count towards progress")
+}
+
+func TestEdge_DropOldest(t *testing.T) {
+	_, received := runOverflow(t, OverflowDropOldest)
+	assert.Equal(t, []int{996, 997, 998, 999}, received)
+}
+
+func TestEdge_Sample(t *testing.T) {
+	// While the edge is full, every 10th item (13, 23... 993) takes the place of the oldest one
+	_, received := runOverflow(t, OverflowSample)
+	assert.Equal(t, []int{963, 973, 983, 993}, received)
+}
+
+// TestEdge_Buffer verifies that an edge gets its own buffer length, and that the others keep the conveyor's one.
+func TestEdge_Buffer(t *testing.T) {
+	cnv, _ := NewConveyor("buffers", 10)
+	cnv.SetEdge("double", EdgeConfig{Buffer: 3})
+	require.NoError(t, AddSource[int](cnv, SourceFromSeq("src", upTo(10)), WorkerModeTransaction))
+	require.NoError(t, AddOperation[int, int](cnv, OperationFunc("double", 1, func(ctx CnvContext, in int) (int, error) {
+		return 2 * in, nil
+	}), WorkerModeTransaction))
+	require.NoError(t, AddSink[int](cnv, SinkFunc("snk", 1, func(ctx CnvContext, in int) error { return nil }), WorkerModeTransaction))
+
+	assert.Equal(t, 3, cap(cnv.workers[1].(*OperationWorkerPool[int, int]).inputChannel))
+	assert.Equal(t, 10, cap(cnv.workers[2].(*SinkWorkerPool[int]).inputChannel))
+	require.NoError(t, startWithin(t, cnv, time.Second))
+	assert.Equal(t, int64(0), cnv.Drops().Total())
+}
+
+// TestEdge_BatchedAndJoint verifies that drops are counted by item with batching, and on the inputs of a joint.
+func TestEdge_BatchedAndJoint(t *testing.T) {
+	slow := func(ctx CnvContext, in int) error {
+		time.Sleep(100 * time.Microsecond)
+		return nil
+	}
+
+	cnv, _ := NewConveyor("batched_drops", 1)
+	cnv.EnableBatching(10, time.Millisecond).
+		SetEdge("replicate", EdgeConfig{Overflow: OverflowDropNewest}).
+		SetEdge("slow", EdgeConfig{Overflow: OverflowDropOldest})
+	require.NoError(t, AddSource[int](cnv, SourceFromSeq("src", upTo(1000)), WorkerModeLoop))
+	joint, _ := NewReplicateJoint[int]("replicate", 2)
+	require.NoError(t, AddJointAfterNode[int, int](cnv, joint))
+	require.NoError(t, AddSinkAfterJoint[int](cnv, SinkFunc("slow", 1, slow), WorkerModeTransaction))
+	require.NoError(t, AddOperationAfterJoint[int, int](cnv, OperationFunc("copy", 1, func(ctx CnvContext, in int) (int, error) {
+		return in, nil
+	}), WorkerModeTransaction))
+
+	results, err := Collect[int](context.Background(), cnv)
+	require.NoError(t, err)
+
+	// Whatever was dropped by the joint's input is missing from both branches
+	assert.Equal(t, int64(1000), int64(len(results))+cnv.Drops().Count("replicate"))
+	assert.Equal(t, cnv.Drops().Count("replicate")+cnv.Drops().Count("slow"), cnv.Drops().Total())
+	assert.Positive(t, cnv.Drops().Count("slow"))
+}
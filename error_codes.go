@@ -1,6 +1,9 @@
 package conveyor
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// ErrInvalidWorkerType error
@@ -41,6 +44,9 @@ var (
 	// ErrSinkInternal error
 	ErrSinkInternal = errors.New("sink executor internal error")
 
+	// ErrExecutorPanicked error, matched by the PanicError recorded when a worker recovers from a panic
+	ErrExecutorPanicked = errors.New("executor panicked")
+
 	// ErrLessInputChannelsInJoint error
 	ErrLessInputChannelsInJoint = errors.New("joint worker doesn't have enough input channels")
 
@@ -50,9 +56,83 @@ var (
 	// ErrLifeCycleNotSupported error
 	ErrLifeCycleNotSupported = errors.New("conveyor instance is not created with life cycle support")
 
+	// ErrIllegalStateTransition is returned when a conveyor is marked with a state that can't follow its current one
+	ErrIllegalStateTransition = errors.New("illegal life cycle state transition")
+
+	// ErrConveyorNotFound is returned by a Manager for an ID that isn't registered
+	ErrConveyorNotFound = errors.New("no conveyor is registered with this id")
+
+	// ErrDuplicateConveyorID is returned by a Manager when a conveyor is registered with an ID that's already taken
+	ErrDuplicateConveyorID = errors.New("a conveyor is already registered with this id")
+
+	// ErrConveyorAlreadyRunning is returned by a Manager for a conveyor that is queued, running, or waiting to restart
+	ErrConveyorAlreadyRunning = errors.New("conveyor is already running")
+
+	// ErrConveyorNotRestartable is returned by a Manager when starting again a conveyor registered without a factory
+	ErrConveyorNotRestartable = errors.New("conveyor can only run once, register it with a factory to restart it")
+
+	// ErrGoroutineLeak is returned by Start() when leak checking is enabled, and goroutines are still
+	// running after the grace period. The error lists the stages they belong to.
+	ErrGoroutineLeak = errors.New("goroutines still running after the conveyor was done")
+
+	// ErrNoOutputNode is returned when reading a conveyor's output, but its last node has none (or there's no node at all)
+	ErrNoOutputNode = errors.New("conveyor doesn't end with a node producing output")
+
 	// ErrTypeMismatch is returned when adjacent nodes have incompatible types
 	ErrTypeMismatch = errors.New("type mismatch between adjacent pipeline nodes")
 
+	// ErrNotCheckpointable is returned when checkpoints are enabled, and the source doesn't implement Checkpointable
+	ErrNotCheckpointable = errors.New("source doesn't implement Checkpointable, which checkpoints require")
+
+	// ErrNotAcknowledged is returned when acks are enabled, and the source doesn't implement Acknowledged
+	ErrNotAcknowledged = errors.New("source doesn't implement Acknowledged, which acks require")
+
+	// ErrTrackedLoopMode is returned when a checkpointed source, or an operation or a sink whose items
+	// are acked or checkpointed, is added in loop mode
+	ErrTrackedLoopMode = errors.New("items are tracked one by one: checkpointed sources, operations and sinks must run in WorkerModeTransaction")
+
+	// ErrTrackedJoint is returned when a joint other than ReplicateJoint, or a branch to join, is added,
+	// while items are acked or checkpointed
+	ErrTrackedJoint = errors.New("items are tracked one by one: only a ReplicateJoint can be added")
+
+	// ErrTrackedSpill is returned when an edge spills to disk, while items are tracked
+	ErrTrackedSpill = errors.New("items are tracked one by one: edges can't spill to disk")
+
+	// ErrItemDropped is what OnNack() gets for an item dropped by an edge on overflow
+	ErrItemDropped = errors.New("item dropped by an edge on overflow")
+
+	// ErrInvalidWindow is returned for a window without a size, sliding by more than its size, or without aggregate
+	ErrInvalidWindow = errors.New("invalid window: Size must be positive, and Slide between 0 and Size")
+
+	// ErrLateItem is recorded for an item whose event time falls in windows that have already closed
+	ErrLateItem = errors.New("late item: its windows have already closed")
+
+	// ErrInvalidReducer is returned for a Reducer without Key, Init or Add
+	ErrInvalidReducer = errors.New("invalid reducer: Key, Init & Add are required")
+
+	// ErrInvalidJoin is returned for a join without keys or Join, or that keeps its items for neither a time nor a count
+	ErrInvalidJoin = errors.New("invalid join: LeftKey, RightKey & Join are required, and Retention or RetainCount")
+
+	// ErrNoBranch is returned when adding a JoinJoint, but AddBranchSource() hasn't started a branch to join
+	ErrNoBranch = errors.New("no branch to join: start one with AddBranchSource()")
+
+	// ErrUnjoinedBranch is returned when starting a branch, or the conveyor, while a branch is waiting to be joined
+	ErrUnjoinedBranch = errors.New("a branch is waiting to be joined with AddJoinJoint()")
+
+	// ErrSkipItem is returned by an operation's Execute() to drop an item on purpose, e.g. a duplicate.
+	// It isn't recorded in ErrorStats, a tracked item is acked rather than nacked, and its progress units count as done
+	ErrSkipItem = errors.New("item skipped by the operation")
+
+	// ErrInvalidDedupe is returned for a dedupe without Key, or that remembers keys for neither a time nor a count
+	ErrInvalidDedupe = errors.New("invalid dedupe: Key is required, and TTL or MaxKeys")
+
+	// ErrCoalesced is matched by the error a Memoized operation returns for an item coalesced with one that failed,
+	// along with that item's error. It isn't recorded in ErrorStats, as the failure already is, once
+	ErrCoalesced = errors.New("coalesced with an item that failed")
+
+	// ErrInvalidCache is returned for a cache without Key, or MaxSize
+	ErrInvalidCache = errors.New("invalid cache: Key is required, and a positive MaxSize")
+
 	// ErrNoInputChannel error
 	ErrNoInputChannel = errors.New("number of input channels is 0")
 
@@ -66,3 +146,34 @@ var (
 	ErrOneToOneConnection = errors.New("replicate joint isn't needed for one-to one mapping, " +
 		"you can just link the nodes directly")
 )
+
+// PanicError is recorded in ErrorStats when a worker recovers from a panic in an executor.
+// It has its own type, so that panics get their own ErrorStats bucket, and matches ErrExecutorPanicked with errors.Is()
+type PanicError struct {
+	Value any // value passed to panic()
+}
+
+func (pe *PanicError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrExecutorPanicked, pe.Value)
+}
+
+// Is reports whether target is ErrExecutorPanicked
+func (pe *PanicError) Is(target error) bool {
+	return target == ErrExecutorPanicked
+}
+
+// StageError is returned by an executor made of several stages, like a SubPipeline, when one of them fails.
+// ErrorStats records it under "{stage}/{StageError.Stage}", so that errors of inner stages are nested
+// under the name of the outer one.
+type StageError struct {
+	Stage string // name of the inner stage that failed
+	Err   error
+}
+
+func (se *StageError) Error() string {
+	return fmt.Sprintf("%s: %v", se.Stage, se.Err)
+}
+
+func (se *StageError) Unwrap() error {
+	return se.Err
+}
@@ -3,6 +3,7 @@ package conveyor
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 )
@@ -17,9 +18,10 @@ type ErrorStats struct {
 // Record increments the total error count and the per-type bucket.
 // The map key is "{stage}:{root error type}" where the root error type is obtained
 // by unwrapping all fmt.Errorf("%w", ...) layers to find the innermost error type.
+// If err wraps a StageError, the stage becomes "{stage}/{inner stage}", at every level of nesting.
 func (es *ErrorStats) Record(stage string, err error) {
 	es.total.Add(1)
-	key := stage + ":" + rootErrorType(err)
+	key := nestedStage(stage, err) + ":" + rootErrorType(err)
 	v, _ := es.byType.LoadOrStore(key, new(atomic.Int64))
 	v.(*atomic.Int64).Add(1)
 }
@@ -40,6 +42,38 @@ func (es *ErrorStats) Snapshot() map[string]int64 {
 	return result
 }
 
+// Count returns the number of errors recorded for the stage, whose root error has the same type as err's.
+func (es *ErrorStats) Count(stage string, err error) int64 {
+	v, ok := es.byType.Load(stage + ":" + rootErrorType(err))
+	if !ok {
+		return 0
+	}
+	return v.(*atomic.Int64).Load()
+}
+
+// StageTotal returns the number of errors recorded for the stage, whatever their type,
+// including the ones of the stages nested under it.
+func (es *ErrorStats) StageTotal(stage string) int64 {
+	var total int64
+	es.byType.Range(func(k, v any) bool {
+		if key := k.(string); strings.HasPrefix(key, stage+":") || strings.HasPrefix(key, stage+"/") {
+			total += v.(*atomic.Int64).Load()
+		}
+		return true
+	})
+	return total
+}
+
+// nestedStage appends the stage of every StageError wrapped in err to stage, separated by "/"
+func nestedStage(stage string, err error) string {
+	var se *StageError
+	for errors.As(err, &se) {
+		stage += "/" + se.Stage
+		err = se.Err
+	}
+	return stage
+}
+
 // rootErrorType walks errors.Unwrap() until the innermost (base) error is found
 // and returns its type as a string (e.g., "*fs.PathError", "*pgconn.PgError").
 // This ensures multi-level wrapped errors are always bucketed by their root cause.
@@ -3,7 +3,6 @@ package conveyor
 import (
 	"context"
 	"fmt"
-	"reflect"
 	"sync"
 	"testing"
 
@@ -15,7 +14,7 @@ import (
 // Test helpers - concrete executor implementations
 // ---------------------------------------------------------------------------
 
-// testSource is a minimal SourceExecutor[int] for testing the sourceWrapper.
+// testSource is a minimal SourceExecutor[int] for testing the source worker pool.
 // Execute returns a fixed value; ExecuteLoop emits that value and value+1.
 type testSource struct {
 	ConcreteSourceExecutor[int]
@@ -30,7 +29,7 @@ func (s *testSource) ExecuteLoop(ctx CnvContext, out chan<- int) error {
 	return nil
 }
 
-// testOp is a minimal OperationExecutor[int, string] for testing the operationWrapper.
+// testOp is a minimal OperationExecutor[int, string] for testing the operation worker pool.
 type testOp struct {
 	ConcreteOperationExecutor[int, string]
 }
@@ -46,7 +45,7 @@ func (o *testOp) ExecuteLoop(ctx CnvContext, in <-chan int, out chan<- string) e
 	return nil
 }
 
-// testSink is a minimal SinkExecutor[string] for testing the sinkWrapper.
+// testSink is a minimal SinkExecutor[string] for testing the sink worker pool.
 // It records every value it receives in a slice protected by a mutex.
 type testSink struct {
 	ConcreteSinkExecutor[string]
@@ -71,8 +70,8 @@ func (s *testSink) ExecuteLoop(ctx CnvContext, in <-chan string) error {
 }
 
 // newTestContext creates a valid CnvContext backed by a cancelable context.
-// It is used wherever a non-nil CnvContext is required by the untyped wrapper
-// methods. Because both the test files and the production code live in
+// It is used wherever a non-nil CnvContext is required by executor
+// or worker pool methods. Because both the test files and the production code live in
 // package conveyor, the unexported cnvContext type is accessible here.
 func newTestContext() CnvContext {
 	ctx := &cnvContext{
@@ -81,238 +80,83 @@ func newTestContext() CnvContext {
 			Name:   "test",
 			logs:   make(chan Message, 100),
 			status: make(chan string, 100),
+			closer: &channelCloser{},
 		},
 	}
 	return ctx.WithCancel()
 }
 
 // ---------------------------------------------------------------------------
-// sourceWrapper type-assertion tests
+// Typed worker pool tests
 // ---------------------------------------------------------------------------
 
-func TestSourceWrapper_Types(t *testing.T) {
-	src := &testSource{
-		ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"},
-		value:                  42,
-	}
-	w := wrapSource[int](src)
+func TestWorkerPools_TypedChannels(t *testing.T) {
+	src := NewSourceWorkerPool[int](&testSource{ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"}}, WorkerModeLoop)
+	op := NewOperationWorkerPool[int, string](&testOp{ConcreteOperationExecutor: ConcreteOperationExecutor[int, string]{Name: "op"}}, WorkerModeLoop)
+	snk := NewSinkWorkerPool[string](&testSink{ConcreteSinkExecutor: ConcreteSinkExecutor[string]{Name: "snk"}}, WorkerModeLoop)
 
-	assert.Nil(t, w.InType(), "source InType must be nil - sources have no input")
-	assert.Equal(t, reflect.TypeFor[int](), w.OutType())
-	assert.Equal(t, WorkerTypeSource, w.WorkerType())
-	assert.Equal(t, "src", w.GetName())
-	assert.Equal(t, "src", w.GetUniqueIdentifier())
-}
+	assert.Equal(t, WorkerTypeSource, src.WorkerType())
+	assert.Equal(t, WorkerTypeOperation, op.WorkerType())
+	assert.Equal(t, WorkerTypeSink, snk.WorkerType())
 
-func TestOperationWrapper_Types(t *testing.T) {
-	op := &testOp{
-		ConcreteOperationExecutor: ConcreteOperationExecutor[int, string]{Name: "op"},
-	}
-	w := wrapOperation[int, string](op)
-
-	assert.Equal(t, reflect.TypeFor[int](), w.InType())
-	assert.Equal(t, reflect.TypeFor[string](), w.OutType())
-	assert.Equal(t, WorkerTypeOperation, w.WorkerType())
-}
-
-func TestSinkWrapper_Types(t *testing.T) {
-	snk := &testSink{
-		ConcreteSinkExecutor: ConcreteSinkExecutor[string]{Name: "snk"},
-	}
-	w := wrapSink[string](snk)
-
-	assert.Equal(t, reflect.TypeFor[string](), w.InType())
-	assert.Nil(t, w.OutType(), "sink OutType must be nil - sinks produce no output")
-	assert.Equal(t, WorkerTypeSink, w.WorkerType())
-}
+	src.CreateChannels(1)
+	op.CreateChannels(1)
+	snk.CreateChannels(1)
 
-// ---------------------------------------------------------------------------
-// executeUntyped delegation tests
-// ---------------------------------------------------------------------------
-
-// TestSourceWrapper_executeUntyped verifies that the source wrapper delegates to
-// the underlying Execute method and boxes the typed result as any.
-func TestSourceWrapper_executeUntyped(t *testing.T) {
-	src := &testSource{
-		ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"},
-		value:                  42,
-	}
-	w := wrapSource[int](src)
-
-	ctx := newTestContext()
-	out, err := w.executeUntyped(ctx, nil)
+	out, err := src.GetOutputChannel()
 	require.NoError(t, err)
-	assert.Equal(t, 42, out.(int))
-}
-
-// TestOperationWrapper_executeUntyped verifies that the operation wrapper casts
-// the inData any value to the typed input and returns the boxed output.
-func TestOperationWrapper_executeUntyped(t *testing.T) {
-	op := &testOp{
-		ConcreteOperationExecutor: ConcreteOperationExecutor[int, string]{Name: "op"},
-	}
-	w := wrapOperation[int, string](op)
-
-	ctx := newTestContext()
-	out, err := w.executeUntyped(ctx, 7)
+	assert.IsType(t, make(chan int), out)
+	out, err = op.GetOutputChannel()
 	require.NoError(t, err)
-	assert.Equal(t, "val_7", out.(string))
-}
-
-// TestSinkWrapper_executeUntyped verifies that the sink wrapper casts inData, calls
-// Execute on the underlying executor, and always returns a nil output value.
-func TestSinkWrapper_executeUntyped(t *testing.T) {
-	snk := &testSink{
-		ConcreteSinkExecutor: ConcreteSinkExecutor[string]{Name: "snk"},
-	}
-	w := wrapSink[string](snk)
-
-	ctx := newTestContext()
-	out, err := w.executeUntyped(ctx, "hello")
+	assert.IsType(t, make(chan string), out)
+	in, err := snk.GetInputChannel()
 	require.NoError(t, err)
-	assert.Nil(t, out, "sink executeUntyped must return nil output")
-	assert.Equal(t, []string{"hello"}, snk.received)
-}
-
-// ---------------------------------------------------------------------------
-// executeLoopUntyped channel-bridging tests
-// ---------------------------------------------------------------------------
-
-// TestSourceWrapper_executeLoopUntyped verifies that the source wrapper bridges the
-// typed ExecuteLoop output onto the untyped outChan without losing values.
-func TestSourceWrapper_executeLoopUntyped(t *testing.T) {
-	src := &testSource{
-		ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"},
-		value:                  10,
-	}
-	w := wrapSource[int](src)
-
-	ctx := newTestContext()
-	outChan := make(chan any, 10)
+	assert.IsType(t, make(chan string), in)
 
-	err := w.executeLoopUntyped(ctx, nil, outChan)
-	require.NoError(t, err)
-
-	// executeLoopUntyped must have forwarded all values before returning.
-	close(outChan)
-	var results []int
-	for v := range outChan {
-		results = append(results, v.(int))
-	}
-	assert.Equal(t, []int{10, 11}, results)
-}
-
-// TestOperationWrapper_executeLoopUntyped verifies the bidirectional channel bridge:
-// untyped inChan → typed execute → untyped outChan.
-func TestOperationWrapper_executeLoopUntyped(t *testing.T) {
-	op := &testOp{
-		ConcreteOperationExecutor: ConcreteOperationExecutor[int, string]{Name: "op"},
-	}
-	w := wrapOperation[int, string](op)
-
-	ctx := newTestContext()
-	inChan := make(chan any, 10)
-	outChan := make(chan any, 10)
-
-	inChan <- 1
-	inChan <- 2
-	close(inChan)
-
-	err := w.executeLoopUntyped(ctx, inChan, outChan)
-	require.NoError(t, err)
-
-	close(outChan)
-	var results []string
-	for v := range outChan {
-		results = append(results, v.(string))
-	}
-	assert.Equal(t, []string{"val_1", "val_2"}, results)
+	_, err = src.GetInputChannel()
+	assert.ErrorIs(t, err, ErrInputChanDoesNotExist)
+	_, err = snk.GetOutputChannel()
+	assert.ErrorIs(t, err, ErrOutputChanDoesNotExist)
 }
 
-// TestSinkWrapper_executeLoopUntyped verifies that the sink wrapper bridges the
-// untyped inChan to the typed ExecuteLoop and collects all values.
-func TestSinkWrapper_executeLoopUntyped(t *testing.T) {
-	snk := &testSink{
-		ConcreteSinkExecutor: ConcreteSinkExecutor[string]{Name: "snk"},
-	}
-	w := wrapSink[string](snk)
-
-	ctx := newTestContext()
-	inChan := make(chan any, 10)
-
-	inChan <- "a"
-	inChan <- "b"
-	close(inChan)
+// TestWorkerPools_LinkMismatch verifies that pools only take channels of their own type
+func TestWorkerPools_LinkMismatch(t *testing.T) {
+	src := NewSourceWorkerPool[int](&testSource{ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"}}, WorkerModeLoop)
+	snk := NewSinkWorkerPool[string](&testSink{ConcreteSinkExecutor: ConcreteSinkExecutor[string]{Name: "snk"}}, WorkerModeLoop)
+	src.CreateChannels(1)
 
-	err := w.executeLoopUntyped(ctx, inChan, nil)
-	require.NoError(t, err)
-	assert.Equal(t, []string{"a", "b"}, snk.received)
+	assert.ErrorIs(t, LinkWorker2Worker(src, snk), ErrTypeMismatch)
+	assert.ErrorIs(t, snk.SetInputChannel(make(chan any)), ErrTypeMismatch)
+	assert.NoError(t, snk.SetInputChannel(make(chan string)))
 }
 
-// ---------------------------------------------------------------------------
-// Delegation tests for Count and CleanUp
-// ---------------------------------------------------------------------------
-
-// TestWrapper_DelegatesCleanUp verifies that CleanUp is forwarded to the underlying
-// executor. The default ConcreteSourceExecutor.CleanUp is a no-op returning nil.
-func TestWrapper_DelegatesCleanUp(t *testing.T) {
-	src := &testSource{
-		ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"},
+// runPools links and runs source → operation → sink, and returns what reached the sink
+func runPools(t *testing.T, mode WorkerMode) []string {
+	t.Helper()
+	snkExec := &testSink{ConcreteSinkExecutor: ConcreteSinkExecutor[string]{Name: "snk"}}
+	pools := []NodeWorker{
+		NewSourceWorkerPool[int](&testSource{ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"}, value: 10}, mode),
+		NewOperationWorkerPool[int, string](&testOp{ConcreteOperationExecutor: ConcreteOperationExecutor[int, string]{Name: "op"}}, mode),
+		NewSinkWorkerPool[string](snkExec, mode),
 	}
-	w := wrapSource[int](src)
-	assert.NoError(t, w.CleanUp())
-}
-
-// TestWrapper_DelegatesCount verifies that Count is forwarded to the underlying
-// executor. The default implementation returns 1.
-func TestWrapper_DelegatesCount(t *testing.T) {
-	src := &testSource{
-		ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"},
+	for _, pool := range pools {
+		pool.CreateChannels(10)
 	}
-	w := wrapSource[int](src)
-	assert.Equal(t, 1, w.Count())
-}
+	require.NoError(t, LinkWorker2Worker(pools[0], pools[1]))
+	require.NoError(t, LinkWorker2Worker(pools[1], pools[2]))
 
-// TestOperationWrapper_DelegatesCount verifies Count delegation for operations.
-func TestOperationWrapper_DelegatesCount(t *testing.T) {
-	op := &testOp{
-		ConcreteOperationExecutor: ConcreteOperationExecutor[int, string]{Name: "op"},
+	ctx := newTestContext()
+	for _, pool := range pools {
+		require.NoError(t, pool.Start(ctx))
 	}
-	w := wrapOperation[int, string](op)
-	assert.Equal(t, 1, w.Count())
-}
-
-// TestSinkWrapper_DelegatesCleanUp verifies CleanUp delegation for sinks.
-func TestSinkWrapper_DelegatesCleanUp(t *testing.T) {
-	snk := &testSink{
-		ConcreteSinkExecutor: ConcreteSinkExecutor[string]{Name: "snk"},
+	for _, pool := range pools {
+		require.NoError(t, pool.WaitAndStop(ctx))
 	}
-	w := wrapSink[string](snk)
-	assert.NoError(t, w.CleanUp())
+	return snkExec.received
 }
 
-// TestOperationWrapper_executeUntyped_MultipleValues verifies correct boxing of
-// several different input values in succession.
-func TestOperationWrapper_executeUntyped_MultipleValues(t *testing.T) {
-	op := &testOp{
-		ConcreteOperationExecutor: ConcreteOperationExecutor[int, string]{Name: "op"},
-	}
-	w := wrapOperation[int, string](op)
-	ctx := newTestContext()
-
-	cases := []struct {
-		in  int
-		out string
-	}{
-		{0, "val_0"},
-		{99, "val_99"},
-		{-1, "val_-1"},
-	}
-	for _, tc := range cases {
-		out, err := w.executeUntyped(ctx, tc.in)
-		require.NoError(t, err)
-		assert.Equal(t, tc.out, out.(string))
-	}
+func TestWorkerPools_LoopMode(t *testing.T) {
+	assert.Equal(t, []string{"val_10", "val_11"}, runPools(t, WorkerModeLoop))
 }
 
 // ---------------------------------------------------------------------------
@@ -0,0 +1,211 @@
+package conveyor
+
+import "errors"
+
+// Function adapters let a plain function be used as an executor, without declaring a struct that embeds
+// ConcreteSourceExecutor/ConcreteOperationExecutor/ConcreteSinkExecutor. The returned executors satisfy
+// the generic interfaces, so they work with AddSource(), AddOperation(), AddSink() and the other builders.
+//
+// The transaction-mode adapters (SourceFunc, OperationFunc, SinkFunc) work in both worker modes. In WorkerModeLoop,
+// every item is handed to the function in turn, and errors are recorded in the conveyor's ErrorStats.
+// The loop-mode adapters (SourceLoopFunc, OperationLoopFunc, SinkLoopFunc) only work in WorkerModeLoop.
+//
+// concurrency is the number of goroutines running the function, as returned by Count(). Values below 1 mean 1.
+
+// workerCount returns concurrency, with a minimum of 1
+func workerCount(concurrency int) int {
+	if concurrency < 1 {
+		return 1
+	}
+	return concurrency
+}
+
+// ---------------------------------------------------------------------------
+// Sources
+// ---------------------------------------------------------------------------
+
+type funcSource[TOut any] struct {
+	ConcreteSourceExecutor[TOut]
+	concurrency int
+	fn          func(ctx CnvContext) (TOut, error)
+	loopFn      func(ctx CnvContext, outChan chan<- TOut) error
+}
+
+// SourceFunc returns a SourceExecutor that calls fn for every item.
+// fn returns ErrSourceExhausted once there's nothing left to produce.
+func SourceFunc[TOut any](name string, concurrency int, fn func(ctx CnvContext) (TOut, error)) SourceExecutor[TOut] {
+	return &funcSource[TOut]{
+		ConcreteSourceExecutor: ConcreteSourceExecutor[TOut]{Name: name},
+		concurrency:            workerCount(concurrency),
+		fn:                     fn,
+	}
+}
+
+// SourceLoopFunc returns a SourceExecutor, for WorkerModeLoop, that runs fn to write items on outChan
+func SourceLoopFunc[TOut any](name string, concurrency int, fn func(ctx CnvContext, outChan chan<- TOut) error) SourceExecutor[TOut] {
+	return &funcSource[TOut]{
+		ConcreteSourceExecutor: ConcreteSourceExecutor[TOut]{Name: name},
+		concurrency:            workerCount(concurrency),
+		loopFn:                 fn,
+	}
+}
+
+func (fs *funcSource[TOut]) Count() int {
+	return fs.concurrency
+}
+
+func (fs *funcSource[TOut]) Execute(ctx CnvContext) (TOut, error) {
+	if fs.fn == nil {
+		return fs.ConcreteSourceExecutor.Execute(ctx)
+	}
+	return fs.fn(ctx)
+}
+
+func (fs *funcSource[TOut]) ExecuteLoop(ctx CnvContext, outChan chan<- TOut) error {
+	if fs.loopFn != nil {
+		return fs.loopFn(ctx, outChan)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		out, err := fs.fn(ctx)
+		switch err {
+		case nil:
+		case ErrSourceExhausted:
+			return nil
+		default:
+			ctx.RecordError(fs.Name, err)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case outChan <- out:
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Operations
+// ---------------------------------------------------------------------------
+
+type funcOperation[TIn, TOut any] struct {
+	ConcreteOperationExecutor[TIn, TOut]
+	concurrency int
+	fn          func(ctx CnvContext, inData TIn) (TOut, error)
+	loopFn      func(ctx CnvContext, inChan <-chan TIn, outChan chan<- TOut) error
+}
+
+// OperationFunc returns an OperationExecutor that calls fn for every item
+func OperationFunc[TIn, TOut any](name string, concurrency int, fn func(ctx CnvContext, inData TIn) (TOut, error)) OperationExecutor[TIn, TOut] {
+	return &funcOperation[TIn, TOut]{
+		ConcreteOperationExecutor: ConcreteOperationExecutor[TIn, TOut]{Name: name},
+		concurrency:               workerCount(concurrency),
+		fn:                        fn,
+	}
+}
+
+// OperationLoopFunc returns an OperationExecutor, for WorkerModeLoop, that runs fn to read items
+// from inChan and write results on outChan
+func OperationLoopFunc[TIn, TOut any](name string, concurrency int,
+	fn func(ctx CnvContext, inChan <-chan TIn, outChan chan<- TOut) error) OperationExecutor[TIn, TOut] {
+	return &funcOperation[TIn, TOut]{
+		ConcreteOperationExecutor: ConcreteOperationExecutor[TIn, TOut]{Name: name},
+		concurrency:               workerCount(concurrency),
+		loopFn:                    fn,
+	}
+}
+
+func (fo *funcOperation[TIn, TOut]) Count() int {
+	return fo.concurrency
+}
+
+func (fo *funcOperation[TIn, TOut]) Execute(ctx CnvContext, inData TIn) (TOut, error) {
+	if fo.fn == nil {
+		return fo.ConcreteOperationExecutor.Execute(ctx, inData)
+	}
+	return fo.fn(ctx, inData)
+}
+
+func (fo *funcOperation[TIn, TOut]) ExecuteLoop(ctx CnvContext, inChan <-chan TIn, outChan chan<- TOut) error {
+	if fo.loopFn != nil {
+		return fo.loopFn(ctx, inChan, outChan)
+	}
+
+	for in := range inChan {
+		out, err := fo.fn(ctx, in)
+		if errors.Is(err, ErrSkipItem) {
+			skipItem(ctx, in)
+			continue
+		}
+		if err != nil {
+			ctx.RecordError(fo.Name, err)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case outChan <- out:
+		}
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Sinks
+// ---------------------------------------------------------------------------
+
+type funcSink[TIn any] struct {
+	ConcreteSinkExecutor[TIn]
+	concurrency int
+	fn          func(ctx CnvContext, inData TIn) error
+	loopFn      func(ctx CnvContext, inChan <-chan TIn) error
+}
+
+// SinkFunc returns a SinkExecutor that calls fn for every item
+func SinkFunc[TIn any](name string, concurrency int, fn func(ctx CnvContext, inData TIn) error) SinkExecutor[TIn] {
+	return &funcSink[TIn]{
+		ConcreteSinkExecutor: ConcreteSinkExecutor[TIn]{Name: name},
+		concurrency:          workerCount(concurrency),
+		fn:                   fn,
+	}
+}
+
+// SinkLoopFunc returns a SinkExecutor, for WorkerModeLoop, that runs fn to read items from inChan
+func SinkLoopFunc[TIn any](name string, concurrency int, fn func(ctx CnvContext, inChan <-chan TIn) error) SinkExecutor[TIn] {
+	return &funcSink[TIn]{
+		ConcreteSinkExecutor: ConcreteSinkExecutor[TIn]{Name: name},
+		concurrency:          workerCount(concurrency),
+		loopFn:               fn,
+	}
+}
+
+func (fs *funcSink[TIn]) Count() int {
+	return fs.concurrency
+}
+
+func (fs *funcSink[TIn]) Execute(ctx CnvContext, inData TIn) error {
+	if fs.fn == nil {
+		return fs.ConcreteSinkExecutor.Execute(ctx, inData)
+	}
+	return fs.fn(ctx, inData)
+}
+
+func (fs *funcSink[TIn]) ExecuteLoop(ctx CnvContext, inChan <-chan TIn) error {
+	if fs.loopFn != nil {
+		return fs.loopFn(ctx, inChan)
+	}
+
+	for in := range inChan {
+		if err := fs.fn(ctx, in); err != nil {
+			ctx.RecordError(fs.Name, err)
+		}
+	}
+	return nil
+}
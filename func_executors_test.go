@@ -0,0 +1,102 @@
+package conveyor
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errOdd = errors.New("odd")
+
+// countTo returns a source function producing 1 to n, safe to call from many goroutines
+func countTo(n int64) func(ctx CnvContext) (int, error) {
+	var next atomic.Int64
+	return func(ctx CnvContext) (int, error) {
+		v := next.Add(1)
+		if v > n {
+			return 0, ErrSourceExhausted
+		}
+		return int(v), nil
+	}
+}
+
+// collectInto returns a sink function appending every item to a mutex-guarded slice
+func collectInto(mu *sync.Mutex, items *[]string) func(ctx CnvContext, in string) error {
+	return func(ctx CnvContext, in string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		*items = append(*items, in)
+		return nil
+	}
+}
+
+func rejectOdd(ctx CnvContext, in int) (string, error) {
+	if in%2 == 1 {
+		return "", errOdd
+	}
+	return strconv.Itoa(in), nil
+}
+
+func TestFuncExecutors_Count(t *testing.T) {
+	assert.Equal(t, 3, SourceFunc[int]("src", 3, countTo(1)).Count())
+	assert.Equal(t, 1, OperationFunc("op", 0, rejectOdd).Count())
+	assert.Equal(t, 1, SinkLoopFunc[int]("snk", -2, func(ctx CnvContext, in <-chan int) error { return nil }).Count())
+	assert.Equal(t, "op", OperationFunc("op", 0, rejectOdd).GetName())
+}
+
+// TestFuncExecutors_BothModes verifies that the per-item adapters run the same pipeline in either worker mode,
+// and that the failing items end up in ErrorStats.
+func TestFuncExecutors_BothModes(t *testing.T) {
+	for _, mode := range []WorkerMode{WorkerModeTransaction, WorkerModeLoop} {
+		cnv, err := NewConveyor("funcs", 10)
+		require.NoError(t, err)
+
+		var mu sync.Mutex
+		var items []string
+		require.NoError(t, AddSource[int](cnv, SourceFunc("src", 2, countTo(20)), mode))
+		require.NoError(t, AddOperation[int, string](cnv, OperationFunc("op", 3, rejectOdd), mode))
+		require.NoError(t, AddSink[string](cnv, SinkFunc("snk", 2, collectInto(&mu, &items)), mode))
+
+		require.NoError(t, startWithin(t, cnv, time.Second))
+		assert.Equal(t, StateFinished, cnv.CurrentState())
+		assert.ElementsMatch(t, []string{"2", "4", "6", "8", "10", "12", "14", "16", "18", "20"}, items, "mode %v", mode)
+		assert.Equal(t, int64(10), cnv.Errors().Count("op", errOdd), "mode %v", mode)
+	}
+}
+
+func TestFuncExecutors_LoopVariants(t *testing.T) {
+	cnv, err := NewConveyor("loop_funcs", 10)
+	require.NoError(t, err)
+
+	var total atomic.Int64
+	src := SourceLoopFunc("src", 1, func(ctx CnvContext, out chan<- int) error {
+		for i := 1; i <= 10; i++ {
+			out <- i
+		}
+		return nil
+	})
+	op := OperationLoopFunc("square", 2, func(ctx CnvContext, in <-chan int, out chan<- int) error {
+		for v := range in {
+			out <- v * v
+		}
+		return nil
+	})
+	snk := SinkLoopFunc("sum", 1, func(ctx CnvContext, in <-chan int) error {
+		for v := range in {
+			total.Add(int64(v))
+		}
+		return nil
+	})
+	require.NoError(t, AddSource[int](cnv, src, WorkerModeLoop))
+	require.NoError(t, AddOperation[int, int](cnv, op, WorkerModeLoop))
+	require.NoError(t, AddSink[int](cnv, snk, WorkerModeLoop))
+
+	require.NoError(t, startWithin(t, cnv, time.Second))
+	assert.Equal(t, int64(385), total.Load())
+}
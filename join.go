@@ -0,0 +1,345 @@
+package conveyor
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// JoinMode decides what a JoinJoint does with left items that never matched any right item
+type JoinMode uint8
+
+const (
+	// JoinInner only writes the pairs of items that matched
+	JoinInner = JoinMode(iota)
+	// JoinLeftOuter also writes the left items that never matched, once they're evicted, with a nil right item
+	JoinLeftOuter
+)
+
+// JoinConfig sets how a JoinJoint matches its items, and how long it keeps them
+type JoinConfig[L, R any, K comparable, Out any] struct {
+	// LeftKey & RightKey return the key items of both sides are matched on
+	LeftKey  func(item L) K
+	RightKey func(item R) K
+	// Join returns the result of a left item, and a right one with the same key,
+	// or nil for a left item that never matched, with JoinLeftOuter
+	Join func(ctx CnvContext, left L, right *R) (Out, error)
+	Mode JoinMode
+	// Retention is how long an item is kept, after it's been read, to match the items of the other side
+	Retention time.Duration
+	// RetainCount is how many items of each side are kept, the oldest being evicted first.
+	// At least one of Retention & RetainCount must be set
+	RetainCount int
+}
+
+// JoinJoint joins the items of two branches, left & right, on a common key: every left item is matched with
+// every right item of the same key that it's kept along with, and the result is written to every output.
+// Add it with AddJoinJoint(), once both branches are built.
+type JoinJoint[L, R any, K comparable, Out any] struct {
+	Name string
+	cfg  JoinConfig[L, R, K, Out]
+}
+
+// NewJoinJoint creates a new JoinJoint
+func NewJoinJoint[L, R any, K comparable, Out any](name string, cfg JoinConfig[L, R, K, Out]) (*JoinJoint[L, R, K, Out], error) {
+	if cfg.LeftKey == nil || cfg.RightKey == nil || cfg.Join == nil ||
+		cfg.Retention < 0 || cfg.RetainCount < 0 || (cfg.Retention == 0 && cfg.RetainCount == 0) {
+		return nil, ErrInvalidJoin
+	}
+	return &JoinJoint[L, R, K, Out]{Name: name, cfg: cfg}, nil
+}
+
+// GetName returns the name of the joint executor.
+func (jj *JoinJoint[L, R, K, Out]) GetName() string {
+	return jj.Name
+}
+
+// GetUniqueIdentifier returns a unique string identifying this executor.
+func (jj *JoinJoint[L, R, K, Out]) GetUniqueIdentifier() string {
+	return jj.Name
+}
+
+// Count is 1, as a single go-routine holds the items kept
+func (jj *JoinJoint[L, R, K, Out]) Count() int {
+	return 1
+}
+
+// InputCount is 2: the left branch, then the right one
+func (jj *JoinJoint[L, R, K, Out]) InputCount() int {
+	return 2
+}
+
+// OutputCount returns the number of output channels this joint writes its results to.
+func (jj *JoinJoint[L, R, K, Out]) OutputCount() int {
+	return 1
+}
+
+// joinEntry is an item kept to be matched
+type joinEntry[T any, K comparable] struct {
+	key     K
+	item    T
+	at      time.Time
+	matched bool
+}
+
+// joinSide holds the items kept of one side, in the order they were read, and by key
+type joinSide[T any, K comparable] struct {
+	queue []*joinEntry[T, K]
+	byKey map[K][]*joinEntry[T, K]
+}
+
+func newJoinSide[T any, K comparable]() *joinSide[T, K] {
+	return &joinSide[T, K]{byKey: make(map[K][]*joinEntry[T, K])}
+}
+
+func (js *joinSide[T, K]) add(e *joinEntry[T, K]) {
+	js.queue = append(js.queue, e)
+	js.byKey[e.key] = append(js.byKey[e.key], e)
+}
+
+// evict removes the oldest item
+func (js *joinSide[T, K]) evict() *joinEntry[T, K] {
+	e := js.queue[0]
+	js.queue[0] = nil
+	js.queue = js.queue[1:]
+
+	same := js.byKey[e.key]
+	if len(same) == 1 {
+		delete(js.byKey, e.key)
+	} else {
+		js.byKey[e.key] = same[1:] // Entries of a key are in the order they were read too
+	}
+	return e
+}
+
+// expired tells whether the oldest item has been kept for longer than retention at now
+func (js *joinSide[T, K]) expired(retention time.Duration, now time.Time) bool {
+	return retention > 0 && len(js.queue) > 0 && now.Sub(js.queue[0].at) >= retention
+}
+
+// executeLoop matches the items of left & right, until both are closed
+func (jj *JoinJoint[L, R, K, Out]) executeLoop(ctx CnvContext, left <-chan L, right <-chan R, outChans []chan Out) error {
+	if len(outChans) == 0 {
+		return ErrNoOutputChannel
+	}
+
+	cfg := jj.cfg
+	lefts, rights := newJoinSide[L, K](), newJoinSide[R, K]()
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
+
+	// join writes the result of a pair, and tells whether the conveyor is still running
+	join := func(l L, r *R) bool {
+		out, err := cfg.Join(ctx, l, r)
+		if err != nil {
+			ctx.RecordError(jj.Name, err)
+			return true
+		}
+		for _, outChan := range outChans {
+			select {
+			case <-ctx.Done():
+				return false
+			case outChan <- out:
+			}
+		}
+		return true
+	}
+
+	evictLeft := func() bool {
+		e := lefts.evict()
+		if cfg.Mode == JoinLeftOuter && !e.matched {
+			return join(e.item, nil)
+		}
+		return true
+	}
+
+	// expire evicts the items kept for too long, and makes the timer fire once the next one is
+	expire := func(now time.Time) bool {
+		if cfg.Retention == 0 {
+			return true
+		}
+		for lefts.expired(cfg.Retention, now) {
+			if !evictLeft() {
+				return false
+			}
+		}
+		for rights.expired(cfg.Retention, now) {
+			rights.evict()
+		}
+
+		var next time.Time
+		if len(lefts.queue) > 0 {
+			next = lefts.queue[0].at
+		}
+		if len(rights.queue) > 0 && (next.IsZero() || rights.queue[0].at.Before(next)) {
+			next = rights.queue[0].at
+		}
+		if !next.IsZero() {
+			timer.Reset(next.Add(cfg.Retention).Sub(now))
+		}
+		return true
+	}
+
+	for left != nil || right != nil {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case now := <-timer.C:
+			if !expire(now) {
+				return nil
+			}
+
+		case l, ok := <-left:
+			if !ok {
+				left = nil
+				continue
+			}
+			e := &joinEntry[L, K]{key: cfg.LeftKey(l), item: l, at: time.Now()}
+			for _, r := range rights.byKey[e.key] {
+				e.matched = true
+				if !join(l, &r.item) {
+					return nil
+				}
+			}
+			lefts.add(e)
+			if cfg.RetainCount > 0 && len(lefts.queue) > cfg.RetainCount && !evictLeft() {
+				return nil
+			}
+			if !expire(e.at) {
+				return nil
+			}
+
+		case r, ok := <-right:
+			if !ok {
+				right = nil
+				continue
+			}
+			e := &joinEntry[R, K]{key: cfg.RightKey(r), item: r, at: time.Now()}
+			for _, l := range lefts.byKey[e.key] {
+				l.matched = true
+				if !join(l.item, &e.item) {
+					return nil
+				}
+			}
+			rights.add(e)
+			if cfg.RetainCount > 0 && len(rights.queue) > cfg.RetainCount {
+				rights.evict()
+			}
+			if !expire(e.at) {
+				return nil
+			}
+		}
+	}
+
+	// Both branches are done, so the left items still kept won't match anything anymore
+	for len(lefts.queue) > 0 {
+		if !evictLeft() {
+			return nil
+		}
+	}
+	return nil
+}
+
+// AddBranchSource adds a source that starts a second branch, after the one that ends with the last node.
+// The two branches must then be joined with AddJoinJoint(), the last node of the second one being the right side.
+func AddBranchSource[TOut any](cnv *Conveyor, exec SourceExecutor[TOut], mode WorkerMode) error {
+	if cnv.lastNodeOutType == nil || len(cnv.workers) == 0 {
+		return ErrNoOutputNode
+	}
+	if cnv.branch != nil {
+		return ErrUnjoinedBranch
+	}
+	if cnv.settlesItems() {
+		return fmt.Errorf("%w: %s starts a branch to join", ErrTrackedJoint, exec.GetName())
+	}
+
+	nodeWorker, err := sourceWorkerFor[TOut](cnv, exec, mode)
+	if err != nil {
+		return err
+	}
+	left := &branchEnd{worker: cnv.workers[len(cnv.workers)-1], outType: cnv.lastNodeOutType}
+
+	// The source has no input, so it's linked to nothing
+	if addErr := cnv.AddNodeWorker(nodeWorker, false); addErr != nil {
+		fmt.Printf("Adding branch source-%s to conveyor failed. Error:[%v]\n",
+			exec.GetName(), addErr)
+		return addErr
+	}
+
+	cnv.branch = left
+	cnv.stages[exec.GetName()] = exec
+	cnv.lastNodeOutType = reflect.TypeFor[TOut]()
+	cnv.lockConfig()
+	return nil
+}
+
+// AddJoinJoint adds join after the two branches: the one started by AddBranchSource() is the right side,
+// and the one before it the left side. L & R must match the output types of their last nodes.
+// Like after AddJointAfterNode(), the next nodes are added with AddSinkAfterJoint / AddOperationAfterJoint.
+func AddJoinJoint[L, R any, K comparable, Out any](cnv *Conveyor, join *JoinJoint[L, R, K, Out]) error {
+	left := cnv.branch
+	if left == nil {
+		return ErrNoBranch
+	}
+	if expectedIn := reflect.TypeFor[L](); left.outType != expectedIn {
+		return fmt.Errorf("%w: expected left input type %v but got %v", ErrTypeMismatch, left.outType, expectedIn)
+	}
+	if expectedIn := reflect.TypeFor[R](); cnv.lastNodeOutType != expectedIn {
+		return fmt.Errorf("%w: expected right input type %v but got %v", ErrTypeMismatch, cnv.lastNodeOutType, expectedIn)
+	}
+
+	var jointWorker JointWorker = newJoinWorkerPool(join)
+	if cnv.items != nil {
+		jointWorker = newJoinWorkerPool(trackedJoin(join, cnv.items))
+	}
+	if addErr := cnv.AddJointWorker(jointWorker); addErr != nil {
+		fmt.Printf("Adding joint-%s after branches to conveyor failed. Error:[%v]\n",
+			join.GetName(), addErr)
+		return addErr
+	}
+
+	if linkErr := LinkJointAfterNode(left.worker, jointWorker, 0); linkErr != nil {
+		return linkErr
+	}
+	if linkErr := LinkJointAfterNode(cnv.workers[len(cnv.workers)-1], jointWorker, 1); linkErr != nil {
+		return linkErr
+	}
+
+	cnv.branch = nil
+	cnv.stages[join.GetName()] = join
+	cnv.lastNodeOutType = nil
+	cnv.lastJointOutType = reflect.TypeFor[Out]()
+	cnv.lockConfig()
+	return nil
+}
+
+// trackedJoin joins tracked items, when they merely carry their metadata: a result carries the metadata of its left item
+func trackedJoin[L, R any, K comparable, Out any](join *JoinJoint[L, R, K, Out], tracker *itemTracker) *JoinJoint[tracked[L], tracked[R], K, tracked[Out]] {
+	cfg := join.cfg
+	return &JoinJoint[tracked[L], tracked[R], K, tracked[Out]]{
+		Name: join.Name,
+		cfg: JoinConfig[tracked[L], tracked[R], K, tracked[Out]]{
+			LeftKey:  func(l tracked[L]) K { return cfg.LeftKey(l.item) },
+			RightKey: func(r tracked[R]) K { return cfg.RightKey(r.item) },
+			Join: func(ctx CnvContext, l tracked[L], r *tracked[R]) (tracked[Out], error) {
+				var right *R
+				if r != nil {
+					right = &r.item
+				}
+				out, err := cfg.Join(contextFor(ctx, l.tok), l.item, right)
+				return tracked[Out]{item: out, tok: tracker.carrying(l.tok.meta)}, err
+			},
+			Mode:        cfg.Mode,
+			Retention:   cfg.Retention,
+			RetainCount: cfg.RetainCount,
+		},
+	}
+}
+
+// branchEnd is the last node of a branch waiting to be joined
+type branchEnd struct {
+	worker  NodeWorker
+	outType reflect.Type
+}
@@ -0,0 +1,209 @@
+package conveyor
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type order struct {
+	ID     int
+	Amount int
+}
+
+type payment struct {
+	OrderID int
+	Paid    int
+}
+
+// paymentJoin joins orders with their payments, unpaid ones being written with JoinLeftOuter
+func paymentJoin(mode JoinMode, retention time.Duration, retainCount int) *JoinJoint[order, payment, int, string] {
+	join, _ := NewJoinJoint("match", JoinConfig[order, payment, int, string]{
+		LeftKey:  func(o order) int { return o.ID },
+		RightKey: func(p payment) int { return p.OrderID },
+		Join: func(ctx CnvContext, o order, p *payment) (string, error) {
+			if p == nil {
+				return fmt.Sprintf("%d: unpaid", o.ID), nil
+			}
+			return fmt.Sprintf("%d: %d/%d", o.ID, p.Paid, o.Amount), nil
+		},
+		Mode:        mode,
+		Retention:   retention,
+		RetainCount: retainCount,
+	})
+	return join
+}
+
+// TestJoin_Branches verifies that items of two sources are joined, whichever branch they come from first,
+// one item at a time or batched.
+func TestJoin_Branches(t *testing.T) {
+	orders := func(yield func(order) bool) {
+		for i := range 10 {
+			if !yield(order{ID: i, Amount: 10 * i}) {
+				return
+			}
+		}
+	}
+	payments := func(yield func(payment) bool) {
+		for i := 0; i < 10; i += 2 {
+			if !yield(payment{OrderID: i, Paid: 10 * i}) {
+				return
+			}
+		}
+	}
+
+	tests := []struct {
+		mode  JoinMode
+		wants []string
+	}{
+		{JoinInner, []string{"0: 0/0", "2: 20/20", "4: 40/40", "6: 60/60", "8: 80/80"}},
+		{JoinLeftOuter, []string{
+			"0: 0/0", "1: unpaid", "2: 20/20", "3: unpaid", "4: 40/40", "5: unpaid", "6: 60/60", "7: unpaid", "8: 80/80", "9: unpaid",
+		}},
+	}
+
+	for _, tt := range tests {
+		for _, batched := range []bool{false, true} {
+			cnv, _ := NewConveyor("join", 10)
+			if batched {
+				cnv.EnableBatching(4, time.Millisecond)
+			}
+			require.NoError(t, AddSource[order](cnv, SourceFromSeq("orders", orders), WorkerModeLoop))
+			require.NoError(t, AddBranchSource[payment](cnv, SourceFromSeq("payments", payments), WorkerModeLoop))
+			require.NoError(t, AddJoinJoint(cnv, paymentJoin(tt.mode, time.Minute, 0)))
+
+			var results []string
+			require.NoError(t, AddSinkAfterJoint[string](cnv, SinkFunc("snk", 1, func(ctx CnvContext, in string) error {
+				results = append(results, in)
+				return nil
+			}), WorkerModeTransaction))
+			require.NoError(t, startWithin(t, cnv, time.Second))
+
+			slices.Sort(results)
+			assert.Equal(t, tt.wants, results, "mode: %d, batched: %v", tt.mode, batched)
+		}
+	}
+}
+
+// TestJoin_Metadata verifies that the results of a join carry the metadata of their left item.
+func TestJoin_Metadata(t *testing.T) {
+	orders := func(yield func(order) bool) {
+		for i := range 10 {
+			if !yield(order{ID: i, Amount: 10 * i}) {
+				return
+			}
+		}
+	}
+	payments := func(yield func(payment) bool) {
+		for i := 9; i >= 0; i-- {
+			if !yield(payment{OrderID: i, Paid: 10 * i}) {
+				return
+			}
+		}
+	}
+
+	cnv, _ := NewConveyor("join_metadata", 10)
+	cnv.EnableMetadata()
+	require.NoError(t, AddSource[order](cnv, SourceFromSeq("orders", orders), WorkerModeLoop))
+	require.NoError(t, AddBranchSource[payment](cnv, SourceFromSeq("payments", payments), WorkerModeLoop))
+	require.NoError(t, AddJoinJoint(cnv, paymentJoin(JoinInner, time.Minute, 0)))
+
+	var results []string
+	require.NoError(t, AddSinkAfterJoint[string](cnv, SinkFunc("snk", 1, func(ctx CnvContext, in string) error {
+		md, ok := ItemMetadata(ctx)
+		assert.True(t, ok)
+		assert.True(t, strings.HasPrefix(in, md.ID+":"), "%s has the metadata of order %s", in, md.ID)
+		results = append(results, in)
+		return nil
+	}), WorkerModeTransaction))
+	require.NoError(t, startWithin(t, cnv, time.Second))
+	assert.Len(t, results, 10)
+}
+
+// joinChannels runs join over channels of its own, for items to be read in a given order
+func joinChannels(join *JoinJoint[order, payment, int, string]) (chan order, chan payment, chan string, chan error) {
+	left, right, out := make(chan order), make(chan payment), make(chan string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- join.executeLoop(newTestContext(), left, right, []chan string{out})
+		close(out)
+	}()
+	return left, right, out, done
+}
+
+// TestJoin_RetainCount verifies that only the last items of each side are kept, and that unmatched left items
+// are written once evicted, with JoinLeftOuter.
+func TestJoin_RetainCount(t *testing.T) {
+	left, right, out, done := joinChannels(paymentJoin(JoinLeftOuter, 0, 2))
+
+	left <- order{ID: 1, Amount: 10}
+	left <- order{ID: 2, Amount: 20}
+	left <- order{ID: 3, Amount: 30} // Evicts order 1
+	right <- payment{OrderID: 1, Paid: 10}
+	right <- payment{OrderID: 3, Paid: 30}
+	right <- payment{OrderID: 3, Paid: 5} // Payments are matched with every order kept, and the other way round
+	left <- order{ID: 3, Amount: 7}       // Matched, then evicts order 2
+	close(left)
+	close(right)
+	require.NoError(t, <-done)
+
+	var results []string
+	for r := range out {
+		results = append(results, r)
+	}
+	assert.Equal(t, []string{"1: unpaid", "3: 30/30", "3: 5/30", "3: 30/7", "3: 5/7", "2: unpaid"}, results)
+}
+
+// TestJoin_Retention verifies that items are only kept for so long, and that unmatched left items are written
+// once they expire, even if nothing else is read.
+func TestJoin_Retention(t *testing.T) {
+	left, right, out, done := joinChannels(paymentJoin(JoinLeftOuter, 20*time.Millisecond, 0))
+
+	right <- payment{OrderID: 1, Paid: 10}
+	left <- order{ID: 1, Amount: 10}
+	assert.Equal(t, "1: 10/10", <-out)
+
+	left <- order{ID: 2, Amount: 20}
+	select {
+	case r := <-out:
+		assert.Equal(t, "2: unpaid", r)
+	case <-time.After(time.Second):
+		t.Fatal("order 2 didn't expire")
+	}
+	right <- payment{OrderID: 2, Paid: 20} // Too late
+	close(left)
+	close(right)
+	require.NoError(t, <-done)
+	for r := range out {
+		t.Errorf("unexpected result: %s", r)
+	}
+}
+
+// TestJoin_Invalid verifies the join's config, and how the branches are built.
+func TestJoin_Invalid(t *testing.T) {
+	_, err := NewJoinJoint("match", JoinConfig[order, payment, int, string]{
+		LeftKey:  func(o order) int { return o.ID },
+		RightKey: func(p payment) int { return p.OrderID },
+		Join:     func(ctx CnvContext, o order, p *payment) (string, error) { return "", nil },
+	})
+	assert.ErrorIs(t, err, ErrInvalidJoin, "neither Retention nor RetainCount")
+
+	cnv, _ := NewConveyor("no_branch", 10)
+	require.NoError(t, AddSource[order](cnv, SourceFromSeq("orders", slices.Values([]order{{}})), WorkerModeLoop))
+	assert.ErrorIs(t, AddJoinJoint(cnv, paymentJoin(JoinInner, time.Second, 0)), ErrNoBranch)
+
+	require.NoError(t, AddBranchSource[int](cnv, SourceFromSeq("ints", upTo(3)), WorkerModeLoop))
+	assert.ErrorIs(t, AddBranchSource[int](cnv, SourceFromSeq("more", upTo(3)), WorkerModeLoop), ErrUnjoinedBranch)
+	assert.ErrorIs(t, AddJoinJoint(cnv, paymentJoin(JoinInner, time.Second, 0)), ErrTypeMismatch)
+	assert.ErrorIs(t, cnv.Start(), ErrUnjoinedBranch)
+
+	cnv, _ = NewConveyor("tracked", 10)
+	cnv.EnableAcks()
+	require.NoError(t, AddSource[int](cnv, newAckingSource(SourceFromSeq("src", upTo(3))), WorkerModeLoop))
+	assert.ErrorIs(t, AddBranchSource[int](cnv, SourceFromSeq("ints", upTo(3)), WorkerModeLoop), ErrTrackedJoint)
+}
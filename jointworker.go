@@ -3,92 +3,212 @@ package conveyor
 import (
 	"fmt"
 	"log"
+	"reflect"
 )
 
 // JointWorkerPool struct provides the worker pool infra for Joint interface, that act as connections between nodes
-type JointWorkerPool struct {
+type JointWorkerPool[TIn, TOut any] struct {
 	*ConcreteJointWorker
-	inputChannels  []chan any
-	outputChannels []chan any
+	exec           JointExecutor[TIn, TOut]
+	inputChannels  []chan TIn
+	outputChannels []chan TOut
+	inputBatches   []chan []TIn  // the inputs, once batching is enabled
+	outputBatches  []chan []TOut // the outputs, once batching is enabled
 }
 
 // NewJointWorkerPool creates a new OperationWorkerPool
-func NewJointWorkerPool(executor jointExecutor) JointWorker {
-	jwp := &JointWorkerPool{
+func NewJointWorkerPool[TIn, TOut any](exec JointExecutor[TIn, TOut]) JointWorker {
+	jwp := &JointWorkerPool[TIn, TOut]{
 		ConcreteJointWorker: &ConcreteJointWorker{
 			WPool: &WPool{
-				Name: executor.GetName() + "_worker",
+				Name: exec.GetName() + "_worker",
 			},
-			Executor: executor,
+			Executor: exec,
 		},
+		exec: exec,
 	}
 
 	return jwp
 }
 
 // CreateChannels creates channels for the joint worker
-func (jwp *JointWorkerPool) CreateChannels(buffer int) {
+func (jwp *JointWorkerPool[TIn, TOut]) CreateChannels(buffer int) {
 	for i := 0; i < jwp.Executor.InputCount(); i++ {
-		jwp.inputChannels = append(jwp.inputChannels, make(chan any, buffer))
+		var inlet any
+		if jwp.batch != nil {
+			ch := make(chan []TIn, buffer)
+			jwp.inputBatches = append(jwp.inputBatches, ch)
+			if jwp.edge.dropsItems() {
+				inlet = overflowInlet(jwp.WPool, jwp.edge, ch, dropChunk[TIn](jwp.edge))
+			}
+		} else {
+			ch := make(chan TIn, buffer)
+			jwp.inputChannels = append(jwp.inputChannels, ch)
+			if jwp.edge.dropsItems() {
+				inlet = overflowInlet(jwp.WPool, jwp.edge, ch, dropItem[TIn](jwp.edge))
+			}
+		}
+		jwp.inlets = append(jwp.inlets, inlet)
 	}
 }
 
-// GetInputChannels returns the input channel of Joint WorkerPool
-func (jwp *JointWorkerPool) GetInputChannels() ([]chan any, error) {
+// GetInputChannels returns the input channel of Joint WorkerPool, a []chan TIn, or a []chan []TIn once batching is enabled
+func (jwp *JointWorkerPool[TIn, TOut]) GetInputChannels() (any, error) {
+	if jwp.batch != nil {
+		return jwp.inputBatches, nil
+	}
 	return jwp.inputChannels, nil
 }
 
-// GetOutputChannels returns the output channel of Joint WorkerPool
-func (jwp *JointWorkerPool) GetOutputChannels() ([]chan any, error) {
+// GetInputChannel returns the input channel of Joint WorkerPool at index, a chan TIn, or a chan []TIn once batching is enabled
+func (jwp *JointWorkerPool[TIn, TOut]) GetInputChannel(index int) (any, error) {
+	if inlet, ok := jwp.inlet(index); ok {
+		return inlet, nil
+	}
+	if jwp.batch != nil {
+		if index < 0 || index >= len(jwp.inputBatches) {
+			return nil, ErrLessInputChannelsInJoint
+		}
+		return jwp.inputBatches[index], nil
+	}
+
+	if index < 0 || index >= len(jwp.inputChannels) {
+		return nil, ErrLessInputChannelsInJoint
+	}
+	return jwp.inputChannels[index], nil
+}
+
+// GetOutputChannels returns the output channel of Joint WorkerPool, a []chan TOut, or a []chan []TOut once batching is enabled
+func (jwp *JointWorkerPool[TIn, TOut]) GetOutputChannels() (any, error) {
+	if jwp.batch != nil {
+		return jwp.outputBatches, nil
+	}
 	return jwp.outputChannels, nil
 }
 
-// SetInputChannels updates the input channel of Joint WorkerPool
-func (jwp *JointWorkerPool) SetInputChannels(inChans []chan any) error {
-	jwp.inputChannels = inChans
+// SetInputChannels updates the input channel of Joint WorkerPool, which must be a []chan TIn,
+// or a []chan []TIn once batching is enabled
+func (jwp *JointWorkerPool[TIn, TOut]) SetInputChannels(inChans any) error {
+	jwp.clearInlets()
+	if jwp.batch != nil {
+		chans, ok := inChans.([]chan []TIn)
+		if !ok {
+			return fmt.Errorf("%w: expected %v but got %T", ErrTypeMismatch, reflect.TypeFor[[]chan []TIn](), inChans)
+		}
+		jwp.inputBatches = chans
+		return nil
+	}
+
+	chans, ok := inChans.([]chan TIn)
+	if !ok {
+		return fmt.Errorf("%w: expected %v but got %T", ErrTypeMismatch, reflect.TypeFor[[]chan TIn](), inChans)
+	}
+	jwp.inputChannels = chans
 	return nil
 }
 
-// SetOutputChannels updates the output channel of Joint WorkerPool
-func (jwp *JointWorkerPool) SetOutputChannels(outChans []chan any) error {
-	jwp.outputChannels = outChans
+// SetOutputChannels updates the output channel of Joint WorkerPool, which must be a []chan TOut,
+// or a []chan []TOut once batching is enabled
+func (jwp *JointWorkerPool[TIn, TOut]) SetOutputChannels(outChans any) error {
+	if jwp.batch != nil {
+		chans, ok := outChans.([]chan []TOut)
+		if !ok {
+			return fmt.Errorf("%w: expected %v but got %T", ErrTypeMismatch, reflect.TypeFor[[]chan []TOut](), outChans)
+		}
+		jwp.outputBatches = chans
+		return nil
+	}
+
+	chans, ok := outChans.([]chan TOut)
+	if !ok {
+		return fmt.Errorf("%w: expected %v but got %T", ErrTypeMismatch, reflect.TypeFor[[]chan TOut](), outChans)
+	}
+	jwp.outputChannels = chans
 	return nil
 }
 
-// AddInputChannel maps a slice of channels on the join't outupt channels
-func (jwp *JointWorkerPool) AddInputChannel(inChan chan any) error {
-	jwp.outputChannels = append(jwp.outputChannels, inChan)
+// AddInputChannel adds a channel, which must be a chan TIn, or a chan []TIn once batching is enabled,
+// to the joint's input channels
+func (jwp *JointWorkerPool[TIn, TOut]) AddInputChannel(inChan any) error {
+	if jwp.batch != nil {
+		ch, err := channelOf[[]TIn](inChan)
+		if err != nil {
+			return err
+		}
+		jwp.inputBatches = append(jwp.inputBatches, ch)
+		return nil
+	}
+
+	ch, err := channelOf[TIn](inChan)
+	if err != nil {
+		return err
+	}
+	jwp.inputChannels = append(jwp.inputChannels, ch)
 	return nil
 }
 
-// AddOutputChannel maps a slice of channels on the join't outupt channels
-func (jwp *JointWorkerPool) AddOutputChannel(outChan chan any) error {
-	jwp.outputChannels = append(jwp.outputChannels, outChan)
+// AddOutputChannel adds a channel, which must be a chan TOut, or a chan []TOut once batching is enabled,
+// to the joint's output channels
+func (jwp *JointWorkerPool[TIn, TOut]) AddOutputChannel(outChan any) error {
+	if jwp.batch != nil {
+		ch, err := channelOf[[]TOut](outChan)
+		if err != nil {
+			return err
+		}
+		jwp.outputBatches = append(jwp.outputBatches, ch)
+		return nil
+	}
+
+	ch, err := channelOf[TOut](outChan)
+	if err != nil {
+		return err
+	}
+	jwp.outputChannels = append(jwp.outputChannels, ch)
 	return nil
 }
 
 // Start JoinWorkerPool
-func (jwp *JointWorkerPool) Start(ctx CnvContext) error {
+func (jwp *JointWorkerPool[TIn, TOut]) Start(ctx CnvContext) error {
+	jwp.startInlets(ctx)
+
+	if jwp.batch != nil {
+		// The executor keeps its channels of single items, relayed from & to the chunks
+		jwp.inputChannels = make([]chan TIn, len(jwp.inputBatches))
+		for i, batches := range jwp.inputBatches {
+			jwp.inputChannels[i] = make(chan TIn, jwp.batch.size)
+			jwp.relay(func() { unchunkItems(batches, jwp.inputChannels[i]) })
+		}
+		jwp.outputChannels = make([]chan TOut, len(jwp.outputBatches))
+		for i, batches := range jwp.outputBatches {
+			jwp.outputChannels[i] = make(chan TOut, jwp.batch.size)
+			jwp.relay(func() { chunkItems(jwp.batch, jwp.outputChannels[i], batches, ctx.Done()) })
+		}
+	}
+
 	for i := 0; i < jwp.Executor.Count(); i++ {
 		jwp.Wg.Add(1)
-		go func() {
+		jwp.spawn(func() {
 			defer jwp.Wg.Done()
-			if err := jwp.Executor.executeLoopUntyped(ctx, jwp.inputChannels, jwp.outputChannels); err != nil {
+			if err := jwp.exec.ExecuteLoop(ctx, jwp.inputChannels, jwp.outputChannels); err != nil {
 				ctx.SendLog(0, fmt.Sprintf("Executor:[%s]", jwp.Executor.GetUniqueIdentifier()), err)
 				log.Fatalf("Improper setup of Executor[%s], ExecuteLoop() method is required", jwp.Executor.GetUniqueIdentifier())
 				return
 			}
-		}()
+		})
 	}
 	return nil
 }
 
 // WaitAndStop JointWorkerPool
-func (jwp *JointWorkerPool) WaitAndStop() error {
+func (jwp *JointWorkerPool[TIn, TOut]) WaitAndStop() error {
 	jwp.Wg.Wait()
 
+	for _, ch := range jwp.inputChannels {
+		drain(ch)
+	}
 	for _, ch := range jwp.outputChannels {
 		close(ch)
 	}
+	jwp.waitRelays()
 	return nil
 }
@@ -0,0 +1,174 @@
+package conveyor
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// joinInput is one of the two inputs of a joinWorkerPool: a chan T, or a chan []T once batching is enabled
+type joinInput[T any] struct {
+	items   chan T
+	batches chan []T
+}
+
+// create creates the input's channel, and returns the one the previous stage writes to
+func (ji *joinInput[T]) create(wp *WPool, buffer int) any {
+	if wp.batch != nil {
+		ji.batches = make(chan []T, buffer)
+		if wp.edge.dropsItems() {
+			return overflowInlet(wp, wp.edge, ji.batches, dropChunk[T](wp.edge))
+		}
+		return ji.batches
+	}
+	ji.items = make(chan T, buffer)
+	if wp.edge.dropsItems() {
+		return overflowInlet(wp, wp.edge, ji.items, dropItem[T](wp.edge))
+	}
+	return ji.items
+}
+
+// start relays the chunks of the input to a channel of single items, once batching is enabled
+func (ji *joinInput[T]) start(wp *WPool) {
+	if wp.batch == nil {
+		return
+	}
+	ji.items = make(chan T, wp.batch.size)
+	wp.relay(func() { unchunkItems(ji.batches, ji.items) })
+}
+
+// joinWorkerPool provides the worker pool infra for a JoinJoint, whose two inputs have types of their own
+type joinWorkerPool[L, R any, K comparable, Out any] struct {
+	*ConcreteJointWorker
+	exec           *JoinJoint[L, R, K, Out]
+	left           joinInput[L]
+	right          joinInput[R]
+	inputs         []any // the channels the previous stages write to, left then right
+	outputChannels []chan Out
+	outputBatches  []chan []Out // the outputs, once batching is enabled
+}
+
+func newJoinWorkerPool[L, R any, K comparable, Out any](exec *JoinJoint[L, R, K, Out]) *joinWorkerPool[L, R, K, Out] {
+	return &joinWorkerPool[L, R, K, Out]{
+		ConcreteJointWorker: &ConcreteJointWorker{
+			WPool: &WPool{
+				Name: exec.GetName() + "_worker",
+			},
+			Executor: exec,
+		},
+		exec: exec,
+	}
+}
+
+// CreateChannels creates the left & right input channels of the join worker
+func (jwp *joinWorkerPool[L, R, K, Out]) CreateChannels(buffer int) {
+	jwp.inputs = []any{jwp.left.create(jwp.WPool, buffer), jwp.right.create(jwp.WPool, buffer)}
+}
+
+// GetInputChannels returns the left & right input channels, as a []any
+func (jwp *joinWorkerPool[L, R, K, Out]) GetInputChannels() (any, error) {
+	return jwp.inputs, nil
+}
+
+// GetInputChannel returns the left input channel at index 0, and the right one at index 1
+func (jwp *joinWorkerPool[L, R, K, Out]) GetInputChannel(index int) (any, error) {
+	if index < 0 || index >= len(jwp.inputs) {
+		return nil, ErrLessInputChannelsInJoint
+	}
+	return jwp.inputs[index], nil
+}
+
+// SetInputChannels isn't supported, as the inputs have types of their own: they're only linked by index
+func (jwp *joinWorkerPool[L, R, K, Out]) SetInputChannels(inChans any) error {
+	return fmt.Errorf("%w: the inputs of join %s are linked one by one", ErrTypeMismatch, jwp.exec.GetName())
+}
+
+// AddInputChannel isn't supported, as a join has exactly two inputs
+func (jwp *joinWorkerPool[L, R, K, Out]) AddInputChannel(inChan any) error {
+	return fmt.Errorf("%w: join %s has two inputs already", ErrTypeMismatch, jwp.exec.GetName())
+}
+
+// GetOutputChannels returns the output channels of the join worker, a []chan Out, or a []chan []Out once batching is enabled
+func (jwp *joinWorkerPool[L, R, K, Out]) GetOutputChannels() (any, error) {
+	if jwp.batch != nil {
+		return jwp.outputBatches, nil
+	}
+	return jwp.outputChannels, nil
+}
+
+// SetOutputChannels updates the output channels of the join worker, which must be a []chan Out,
+// or a []chan []Out once batching is enabled
+func (jwp *joinWorkerPool[L, R, K, Out]) SetOutputChannels(outChans any) error {
+	if jwp.batch != nil {
+		chans, ok := outChans.([]chan []Out)
+		if !ok {
+			return fmt.Errorf("%w: expected %v but got %T", ErrTypeMismatch, reflect.TypeFor[[]chan []Out](), outChans)
+		}
+		jwp.outputBatches = chans
+		return nil
+	}
+
+	chans, ok := outChans.([]chan Out)
+	if !ok {
+		return fmt.Errorf("%w: expected %v but got %T", ErrTypeMismatch, reflect.TypeFor[[]chan Out](), outChans)
+	}
+	jwp.outputChannels = chans
+	return nil
+}
+
+// AddOutputChannel adds a channel, which must be a chan Out, or a chan []Out once batching is enabled,
+// to the join's output channels
+func (jwp *joinWorkerPool[L, R, K, Out]) AddOutputChannel(outChan any) error {
+	if jwp.batch != nil {
+		ch, err := channelOf[[]Out](outChan)
+		if err != nil {
+			return err
+		}
+		jwp.outputBatches = append(jwp.outputBatches, ch)
+		return nil
+	}
+
+	ch, err := channelOf[Out](outChan)
+	if err != nil {
+		return err
+	}
+	jwp.outputChannels = append(jwp.outputChannels, ch)
+	return nil
+}
+
+// Start the join worker
+func (jwp *joinWorkerPool[L, R, K, Out]) Start(ctx CnvContext) error {
+	jwp.startInlets(ctx)
+
+	jwp.left.start(jwp.WPool)
+	jwp.right.start(jwp.WPool)
+	if jwp.batch != nil {
+		jwp.outputChannels = make([]chan Out, len(jwp.outputBatches))
+		for i, batches := range jwp.outputBatches {
+			jwp.outputChannels[i] = make(chan Out, jwp.batch.size)
+			jwp.relay(func() { chunkItems(jwp.batch, jwp.outputChannels[i], batches, ctx.Done()) })
+		}
+	}
+
+	jwp.Wg.Add(1)
+	jwp.spawn(func() {
+		defer jwp.Wg.Done()
+		if err := jwp.exec.executeLoop(ctx, jwp.left.items, jwp.right.items, jwp.outputChannels); err != nil {
+			ctx.SendLog(0, fmt.Sprintf("Executor:[%s]", jwp.Executor.GetUniqueIdentifier()), err)
+			ctx.RecordError(jwp.Executor.GetName(), err)
+		}
+	})
+	return nil
+}
+
+// WaitAndStop joinWorkerPool
+func (jwp *joinWorkerPool[L, R, K, Out]) WaitAndStop() error {
+	jwp.Wg.Wait()
+
+	drain(jwp.left.items)
+	drain(jwp.right.items)
+	for _, ch := range jwp.outputChannels {
+		close(ch)
+	}
+	jwp.waitRelays()
+	return nil
+}
@@ -0,0 +1,100 @@
+package conveyor
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// goroutineTracker is implemented by worker pools, which count the goroutines they start through it
+type goroutineTracker interface {
+	spawn(f func())
+	liveGoroutines() (stage string, live int64)
+}
+
+// liveGoroutines returns the stage name, and the number of its goroutines still running
+func (cnw *ConcreteNodeWorker) liveGoroutines() (string, int64) {
+	return cnw.Executor.GetName(), cnw.live.Load()
+}
+
+// liveGoroutines returns the stage name, and the number of its goroutines still running
+func (wp *ConcreteJointWorker) liveGoroutines() (string, int64) {
+	return wp.Executor.GetName(), wp.live.Load()
+}
+
+// spawnWorker runs f in a new goroutine, counted against worker w if it keeps track of its goroutines
+func spawnWorker(w any, f func()) {
+	if tracker, ok := w.(goroutineTracker); ok {
+		tracker.spawn(f)
+		return
+	}
+	go f()
+}
+
+// EnableLeakCheck is a debug mode, verifying that every goroutine started by the conveyor has returned
+// by the time Start() does. Once the conveyor is done (stopped, timed out, or failed), Start() waits
+// at most grace for its workers. If some are still running, usually because an executor ignores ctx.Done(),
+// Start() returns ErrGoroutineLeak, listing the stages they belong to, instead of blocking forever.
+// Will have no effect, once you add your first node
+func (cnv *Conveyor) EnableLeakCheck(grace time.Duration) *Conveyor {
+	if !cnv.openForConfigChange {
+		return cnv
+	}
+	cnv.leakCheckGrace = grace
+	return cnv
+}
+
+// waitWorkers waits for every worker goroutine to return. With leak checking enabled, it gives up
+// once the conveyor's context has been done for longer than the grace period, and returns the stages
+// with goroutines still running.
+func (cnv *Conveyor) waitWorkers(wg *sync.WaitGroup) []string {
+	if cnv.leakCheckGrace <= 0 {
+		wg.Wait()
+		return nil
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-cnv.ctx.Done():
+	}
+
+	timer := time.NewTimer(cnv.leakCheckGrace)
+	defer timer.Stop()
+	select {
+	case <-finished:
+		return nil
+	case <-timer.C:
+		return cnv.leakedStages()
+	}
+}
+
+// leakedStages returns the stages that still have goroutines running, with their count, sorted by name
+func (cnv *Conveyor) leakedStages() []string {
+	var stages []string
+	count := func(w any) {
+		tracker, ok := w.(goroutineTracker)
+		if !ok {
+			return
+		}
+		if stage, live := tracker.liveGoroutines(); live > 0 {
+			stages = append(stages, fmt.Sprintf("%s (%d goroutines)", stage, live))
+		}
+	}
+
+	for _, nodeWorker := range cnv.workers {
+		count(nodeWorker)
+	}
+	for _, jointWorker := range cnv.joints {
+		count(jointWorker)
+	}
+	sort.Strings(stages)
+	return stages
+}
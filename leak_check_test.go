@@ -0,0 +1,191 @@
+package conveyor
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// firstOnlyOp forwards the first item it receives, and returns without reading the rest.
+type firstOnlyOp struct {
+	ConcreteOperationExecutor[int, int]
+}
+
+func (o *firstOnlyOp) ExecuteLoop(ctx CnvContext, in <-chan int, out chan<- int) error {
+	out <- <-in
+	return nil
+}
+
+// slowCountingSink takes a millisecond for every item, and counts them.
+type slowCountingSink struct {
+	ConcreteSinkExecutor[int]
+	received atomic.Int64
+}
+
+func (s *slowCountingSink) Count() int { return 4 }
+
+func (s *slowCountingSink) Execute(ctx CnvContext, in int) error {
+	time.Sleep(time.Millisecond)
+	s.received.Add(1)
+	return nil
+}
+
+// stuckOp never returns from Execute until released, whatever happens to ctx.
+type stuckOp struct {
+	ConcreteOperationExecutor[int, int]
+	release chan struct{}
+}
+
+func (o *stuckOp) Execute(ctx CnvContext, in int) (int, error) {
+	<-o.release
+	return in, nil
+}
+
+// expectNoGoroutinesAbove waits for the number of goroutines to go back to baseline.
+// Goroutines that have returned may take a moment to be accounted as exited, hence the retries.
+// assert.Eventually() isn't used, as it runs goroutines of its own.
+func expectNoGoroutinesAbove(t *testing.T, baseline int) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if runtime.NumGoroutine() <= baseline {
+			return
+		}
+	}
+	t.Errorf("%d goroutines left running after Start() returned", runtime.NumGoroutine()-baseline)
+}
+
+// startWithin runs cnv.Start(), failing the test if it doesn't return in time.
+func startWithin(t *testing.T, cnv *Conveyor, timeout time.Duration) error {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- cnv.Start() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		t.Fatal("Start() didn't return")
+		return nil
+	}
+}
+
+// TestLeak_LoopOperationReturnsEarly verifies that an operation that stops reading its input
+// doesn't leave the source blocked forever.
+func TestLeak_LoopOperationReturnsEarly(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+
+	cnv, _ := NewConveyor("early_return", 1)
+	cnv.EnableLeakCheck(time.Second)
+	values := make([]int, 100)
+	for i := range values {
+		values[i] = i + 1
+	}
+	require.NoError(t, AddSource[int](cnv, &loopSource{ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"}, values: values}, WorkerModeLoop))
+	require.NoError(t, AddOperation[int, int](cnv, &firstOnlyOp{ConcreteOperationExecutor[int, int]{Name: "op"}}, WorkerModeLoop))
+	snk := &loopCollectingSink{ConcreteSinkExecutor: ConcreteSinkExecutor[int]{Name: "snk"}}
+	require.NoError(t, AddSink[int](cnv, snk, WorkerModeLoop))
+
+	require.NoError(t, startWithin(t, cnv, time.Second))
+	assert.Equal(t, []int{1}, snk.collected)
+	assert.Equal(t, StateFinished, cnv.CurrentState())
+	expectNoGoroutinesAbove(t, baseline)
+}
+
+// TestLeak_StopTransactionMode verifies that stopping a busy transaction-mode conveyor
+// neither leaves transactions blocked on a full channel, nor sends on a closed one.
+func TestLeak_StopTransactionMode(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+
+	cnv, _ := NewConveyor("stop_busy", 2)
+	cnv.EnableLeakCheck(time.Second)
+	src := &countingSource{ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"}, limit: 1 << 30}
+	require.NoError(t, AddSource[int](cnv, src, WorkerModeTransaction))
+	require.NoError(t, AddOperation[int, int](cnv, &doublingOp{ConcreteOperationExecutor[int, int]{Name: "op"}}, WorkerModeTransaction))
+	snk := &slowCountingSink{ConcreteSinkExecutor: ConcreteSinkExecutor[int]{Name: "snk"}}
+	require.NoError(t, AddSink[int](cnv, snk, WorkerModeTransaction))
+
+	done := make(chan error, 1)
+	go func() { done <- cnv.Start() }()
+	require.Eventually(t, func() bool { return snk.received.Load() >= 10 }, time.Second, time.Millisecond)
+	cnv.Stop()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Start() didn't return after Stop()")
+	}
+	assert.Equal(t, StateKilled, cnv.CurrentState())
+	expectNoGoroutinesAbove(t, baseline)
+}
+
+// TestLeak_StopLoopMode verifies that loop-mode workers don't block once the conveyor is stopped.
+func TestLeak_StopLoopMode(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+
+	cnv, _ := NewConveyor("stop_loop", 1)
+	cnv.EnableLeakCheck(time.Second)
+	src := &tickingSource{ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"}}
+	require.NoError(t, AddSource[int](cnv, src, WorkerModeLoop))
+	require.NoError(t, AddOperation[int, int](cnv, &loopDoubleOp{ConcreteOperationExecutor[int, int]{Name: "op"}}, WorkerModeLoop))
+	require.NoError(t, AddSink[int](cnv, &slowCountingSink{ConcreteSinkExecutor: ConcreteSinkExecutor[int]{Name: "snk"}}, WorkerModeTransaction))
+
+	done := make(chan error, 1)
+	go func() { done <- cnv.Start() }()
+	require.Eventually(t, func() bool { return src.emitted.Load() >= 10 }, time.Second, time.Millisecond)
+	cnv.Stop()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Start() didn't return after Stop()")
+	}
+	expectNoGoroutinesAbove(t, baseline)
+}
+
+// TestLeak_JointWithEarlyReturningSink verifies that a joint doesn't block on a sink that stopped reading.
+func TestLeak_JointWithEarlyReturningSink(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+
+	cnv, _ := NewConveyor("joint_early", 1)
+	cnv.EnableLeakCheck(time.Second)
+	src := &countingSource{ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"}, limit: 50}
+	require.NoError(t, AddSource[int](cnv, src, WorkerModeTransaction))
+	joint, _ := NewReplicateJoint[int]("replicate", 2)
+	require.NoError(t, AddJointAfterNode[int, int](cnv, joint))
+	require.NoError(t, AddOperationAfterJoint[int, int](cnv, &firstOnlyOp{ConcreteOperationExecutor[int, int]{Name: "first"}}, WorkerModeLoop))
+	require.NoError(t, AddSink[int](cnv, &loopCollectingSink{ConcreteSinkExecutor: ConcreteSinkExecutor[int]{Name: "snk1"}}, WorkerModeLoop))
+	snk := &collectingSink{ConcreteSinkExecutor: ConcreteSinkExecutor[int]{Name: "snk2"}}
+	require.NoError(t, AddSinkAfterJoint[int](cnv, snk, WorkerModeTransaction))
+
+	require.NoError(t, startWithin(t, cnv, time.Second))
+	assert.Len(t, snk.collected, 51)
+	expectNoGoroutinesAbove(t, baseline)
+}
+
+// TestLeakCheck_ReportsStuckStage verifies that the leak check names the stage whose executor ignores ctx.
+func TestLeakCheck_ReportsStuckStage(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+
+	op := &stuckOp{ConcreteOperationExecutor: ConcreteOperationExecutor[int, int]{Name: "stuck"}, release: make(chan struct{})}
+	cnv, _ := NewConveyor("leaky", 10)
+	cnv.SetTimeout(20 * time.Millisecond).EnableLeakCheck(20 * time.Millisecond)
+	require.NoError(t, AddSource[int](cnv, &countingSource{ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"}, limit: 3}, WorkerModeTransaction))
+	require.NoError(t, AddOperation[int, int](cnv, op, WorkerModeTransaction))
+	require.NoError(t, AddSink[int](cnv, &collectingSink{ConcreteSinkExecutor: ConcreteSinkExecutor[int]{Name: "snk"}}, WorkerModeTransaction))
+
+	err := startWithin(t, cnv, time.Second)
+	require.True(t, errors.Is(err, ErrGoroutineLeak), "unexpected error: %v", err)
+	assert.True(t, strings.Contains(err.Error(), "stuck (2 goroutines)"), err.Error())
+	assert.False(t, strings.Contains(err.Error(), "src"), err.Error())
+
+	// Once the executor lets go, everything else returns
+	close(op.release)
+	expectNoGoroutinesAbove(t, baseline)
+}
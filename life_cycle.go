@@ -1,5 +1,7 @@
 package conveyor
 
+import "time"
+
 // Valid States for a Conveyor
 const (
 	// StatusPreparing status is used to mark a conveyor to be in "preparing" state
@@ -44,6 +46,29 @@ type LifeCycleHandler interface {
 	StateUpdater
 }
 
+// defaultLifeCycleSyncInterval is how often status and progress are mirrored into the LifeCycleHandler
+const defaultLifeCycleSyncInterval = time.Second
+
+// stateTransitions lists the states that a conveyor is allowed to move to, from each state.
+// The empty state is the one a conveyor is in, before anything has been marked.
+// Killed, finished & internalError are terminal states, with no way out.
+var stateTransitions = map[string][]string{
+	"":              {StatusPreparing, StateStarted, StateToKill, StateInternalError},
+	StatusPreparing: {StateStarted, StateToKill, StateInternalError},
+	StateStarted:    {StateToKill, StateFinished, StateInternalError},
+	StateToKill:     {StateKilled, StateInternalError},
+}
+
+// isValidTransition tells if a conveyor in state "from" can be marked as state "to"
+func isValidTransition(from, to string) bool {
+	for _, next := range stateTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
 func getStateMarker(state string, lch LifeCycleHandler) func() error {
 
 	switch state {
@@ -64,6 +89,3 @@ func getStateMarker(state string, lch LifeCycleHandler) func() error {
 	}
 
 }
-
-type LocalLifeCycleHandler struct {
-}
@@ -0,0 +1,255 @@
+package conveyor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// LifeCycleRecord is everything a LifeCycleHandler keeps about a conveyor run
+type LifeCycleRecord struct {
+	State     string    `json:"state"`
+	StatusMsg string    `json:"status_msg"`
+	Progress  string    `json:"progress"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LocalLifeCycleHandler is an in-memory LifeCycleHandler, for single server applications.
+// It is safe for concurrent use, but its data is lost when the process exits.
+// Use FileLifeCycleHandler if you need it to survive a restart.
+type LocalLifeCycleHandler struct {
+	mu     sync.RWMutex
+	record LifeCycleRecord
+}
+
+// NewLocalLifeCycleHandler creates a new in-memory LifeCycleHandler, with an empty state
+func NewLocalLifeCycleHandler() *LocalLifeCycleHandler {
+	return &LocalLifeCycleHandler{}
+}
+
+// Snapshot returns a copy of everything stored by the handler
+func (lch *LocalLifeCycleHandler) Snapshot() LifeCycleRecord {
+	lch.mu.RLock()
+	defer lch.mu.RUnlock()
+	return lch.record
+}
+
+// update applies fn to the record, under the write lock
+func (lch *LocalLifeCycleHandler) update(fn func(*LifeCycleRecord)) error {
+	lch.mu.Lock()
+	defer lch.mu.Unlock()
+	fn(&lch.record)
+	lch.record.UpdatedAt = time.Now()
+	return nil
+}
+
+func (lch *LocalLifeCycleHandler) setState(state string) error {
+	return lch.update(func(r *LifeCycleRecord) { r.State = state })
+}
+
+// GetState returns the last state marked on the handler
+func (lch *LocalLifeCycleHandler) GetState() (string, error) {
+	return lch.Snapshot().State, nil
+}
+
+// GetStatusMsg returns the last status message
+func (lch *LocalLifeCycleHandler) GetStatusMsg() (string, error) {
+	return lch.Snapshot().StatusMsg, nil
+}
+
+// UpdateStatusMsg stores a new status message
+func (lch *LocalLifeCycleHandler) UpdateStatusMsg(msg string) error {
+	return lch.update(func(r *LifeCycleRecord) { r.StatusMsg = msg })
+}
+
+// GetProgress returns the last progress
+func (lch *LocalLifeCycleHandler) GetProgress() (string, error) {
+	return lch.Snapshot().Progress, nil
+}
+
+// UpdateProgress stores a new progress
+func (lch *LocalLifeCycleHandler) UpdateProgress(progress string) error {
+	return lch.update(func(r *LifeCycleRecord) { r.Progress = progress })
+}
+
+// MarkPreparing marks the state as StatusPreparing
+func (lch *LocalLifeCycleHandler) MarkPreparing() error { return lch.setState(StatusPreparing) }
+
+// MarkStarted marks the state as StateStarted
+func (lch *LocalLifeCycleHandler) MarkStarted() error { return lch.setState(StateStarted) }
+
+// MarkToKill marks the state as StateToKill
+func (lch *LocalLifeCycleHandler) MarkToKill() error { return lch.setState(StateToKill) }
+
+// MarkKilled marks the state as StateKilled
+func (lch *LocalLifeCycleHandler) MarkKilled() error { return lch.setState(StateKilled) }
+
+// MarkFinished marks the state as StateFinished
+func (lch *LocalLifeCycleHandler) MarkFinished() error { return lch.setState(StateFinished) }
+
+// MarkError marks the state as StateInternalError
+func (lch *LocalLifeCycleHandler) MarkError() error { return lch.setState(StateInternalError) }
+
+// FileLifeCycleHandler is a LifeCycleHandler that persists its LifeCycleRecord as JSON in a file.
+// Every update is written to a temp file in the same directory, which is then renamed over the
+// original, so the file always holds a complete record, even if the process dies mid-write.
+// A restarted process can create a handler on the same path, to find out how far the previous run got.
+// The file is read again by the getters and before every update, so that another handler on the same path,
+// e.g. in another process, can mark the conveyor toKill.
+type FileLifeCycleHandler struct {
+	mu     sync.RWMutex
+	path   string
+	record LifeCycleRecord
+}
+
+// NewFileLifeCycleHandler creates a FileLifeCycleHandler backed by the file at path.
+// If the file already exists, the record from the previous run is loaded from it.
+func NewFileLifeCycleHandler(path string) (*FileLifeCycleHandler, error) {
+	flch := &FileLifeCycleHandler{path: path}
+	if err := flch.load(); err != nil {
+		return nil, err
+	}
+	return flch, nil
+}
+
+// load reads the record stored in the file, if there is one yet. Call it with mu held.
+func (flch *FileLifeCycleHandler) load() error {
+	data, err := os.ReadFile(flch.path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil
+	case err != nil:
+		return fmt.Errorf("reading life cycle file '%s': %w", flch.path, err)
+	}
+
+	var record LifeCycleRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return fmt.Errorf("decoding life cycle file '%s': %w", flch.path, err)
+	}
+	flch.record = record
+	return nil
+}
+
+// read returns the record as currently stored in the file
+func (flch *FileLifeCycleHandler) read() (LifeCycleRecord, error) {
+	flch.mu.Lock()
+	defer flch.mu.Unlock()
+	err := flch.load()
+	return flch.record, err
+}
+
+// Path returns the path of the file backing the handler
+func (flch *FileLifeCycleHandler) Path() string {
+	return flch.path
+}
+
+// Snapshot returns a copy of everything stored by the handler, as of its last read or write of the file
+func (flch *FileLifeCycleHandler) Snapshot() LifeCycleRecord {
+	flch.mu.RLock()
+	defer flch.mu.RUnlock()
+	return flch.record
+}
+
+// update applies fn to a copy of the record read from the file, and persists it. The in-memory record
+// is only replaced once the file has been written successfully.
+func (flch *FileLifeCycleHandler) update(fn func(*LifeCycleRecord)) error {
+	flch.mu.Lock()
+	defer flch.mu.Unlock()
+
+	if err := flch.load(); err != nil {
+		return err
+	}
+	record := flch.record
+	fn(&record)
+	record.UpdatedAt = time.Now()
+
+	if err := writeFileAtomic(flch.path, record); err != nil {
+		return err
+	}
+	flch.record = record
+	return nil
+}
+
+// writeFileAtomic writes v as JSON to a temp file next to path, and renames it to path
+func writeFileAtomic(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("creating temp file for '%s': %w", path, err)
+	}
+	tmpPath := tmp.Name()
+
+	// The file isn't fsync-ed: the rename is enough for other processes (or a restarted one)
+	// to never see a partial record, and progress updates are too frequent to pay for durability.
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("writing '%s': %w", path, err)
+	}
+	return nil
+}
+
+func (flch *FileLifeCycleHandler) setState(state string) error {
+	return flch.update(func(r *LifeCycleRecord) { r.State = state })
+}
+
+// GetState returns the last state marked in the file, by this handler or another one
+func (flch *FileLifeCycleHandler) GetState() (string, error) {
+	record, err := flch.read()
+	return record.State, err
+}
+
+// GetStatusMsg returns the last status message
+func (flch *FileLifeCycleHandler) GetStatusMsg() (string, error) {
+	record, err := flch.read()
+	return record.StatusMsg, err
+}
+
+// UpdateStatusMsg persists a new status message
+func (flch *FileLifeCycleHandler) UpdateStatusMsg(msg string) error {
+	return flch.update(func(r *LifeCycleRecord) { r.StatusMsg = msg })
+}
+
+// GetProgress returns the last progress
+func (flch *FileLifeCycleHandler) GetProgress() (string, error) {
+	record, err := flch.read()
+	return record.Progress, err
+}
+
+// UpdateProgress persists a new progress
+func (flch *FileLifeCycleHandler) UpdateProgress(progress string) error {
+	return flch.update(func(r *LifeCycleRecord) { r.Progress = progress })
+}
+
+// MarkPreparing marks the state as StatusPreparing
+func (flch *FileLifeCycleHandler) MarkPreparing() error { return flch.setState(StatusPreparing) }
+
+// MarkStarted marks the state as StateStarted
+func (flch *FileLifeCycleHandler) MarkStarted() error { return flch.setState(StateStarted) }
+
+// MarkToKill marks the state as StateToKill
+func (flch *FileLifeCycleHandler) MarkToKill() error { return flch.setState(StateToKill) }
+
+// MarkKilled marks the state as StateKilled
+func (flch *FileLifeCycleHandler) MarkKilled() error { return flch.setState(StateKilled) }
+
+// MarkFinished marks the state as StateFinished
+func (flch *FileLifeCycleHandler) MarkFinished() error { return flch.setState(StateFinished) }
+
+// MarkError marks the state as StateInternalError
+func (flch *FileLifeCycleHandler) MarkError() error { return flch.setState(StateInternalError) }
@@ -0,0 +1,117 @@
+package conveyor_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tushar2708/conveyor"
+	"github.com/tushar2708/conveyor/conveyortest"
+)
+
+// Handler tests live in an external test package, as conveyortest imports conveyor.
+
+func TestLocalLifeCycleHandler_Conformance(t *testing.T) {
+	conveyortest.TestLifeCycleHandler(t, func(t *testing.T) conveyor.LifeCycleHandler {
+		return conveyor.NewLocalLifeCycleHandler()
+	})
+}
+
+func TestFileLifeCycleHandler_Conformance(t *testing.T) {
+	conveyortest.TestLifeCycleHandler(t, func(t *testing.T) conveyor.LifeCycleHandler {
+		lch, err := conveyor.NewFileLifeCycleHandler(filepath.Join(t.TempDir(), "state.json"))
+		require.NoError(t, err)
+		return lch
+	})
+}
+
+// TestFileLifeCycleHandler_SurvivesRestart verifies that a new handler on the same file
+// sees what the previous one stored.
+func TestFileLifeCycleHandler_SurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	first, err := conveyor.NewFileLifeCycleHandler(path)
+	require.NoError(t, err)
+	require.NoError(t, first.MarkStarted())
+	require.NoError(t, first.UpdateStatusMsg("processed 5 batches"))
+	require.NoError(t, first.UpdateProgress("62.50"))
+
+	second, err := conveyor.NewFileLifeCycleHandler(path)
+	require.NoError(t, err)
+	record := second.Snapshot()
+	assert.Equal(t, conveyor.StateStarted, record.State)
+	assert.Equal(t, "processed 5 batches", record.StatusMsg)
+	assert.Equal(t, "62.50", record.Progress)
+	assert.Equal(t, first.Snapshot().UpdatedAt.UnixNano(), record.UpdatedAt.UnixNano())
+}
+
+// TestFileLifeCycleHandler_NoTempFilesLeft verifies that only the target file remains after updates.
+func TestFileLifeCycleHandler_NoTempFilesLeft(t *testing.T) {
+	dir := t.TempDir()
+	lch, err := conveyor.NewFileLifeCycleHandler(filepath.Join(dir, "state.json"))
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, lch.UpdateProgress("1"))
+	}
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "state.json", entries[0].Name())
+}
+
+// TestFileLifeCycleHandler_FailedWriteKeepsRecord verifies that a failed write leaves
+// both the in-memory record and the file untouched.
+func TestFileLifeCycleHandler_FailedWriteKeepsRecord(t *testing.T) {
+	dir := t.TempDir()
+	lch, err := conveyor.NewFileLifeCycleHandler(filepath.Join(dir, "state.json"))
+	require.NoError(t, err)
+	require.NoError(t, lch.UpdateStatusMsg("before"))
+
+	require.NoError(t, os.Chmod(dir, 0o500))
+	defer os.Chmod(dir, 0o700)
+	if f, err := os.CreateTemp(dir, "probe"); err == nil {
+		f.Close()
+		t.Skip("directory permissions are not enforced for this user")
+	}
+
+	assert.Error(t, lch.UpdateStatusMsg("after"))
+	msg, _ := lch.GetStatusMsg()
+	assert.Equal(t, "before", msg)
+}
+
+// TestFileLifeCycleHandler_SharedFile verifies that a handler sees what another one on the same file marks,
+// e.g. another process asking for the conveyor to be killed.
+func TestFileLifeCycleHandler_SharedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	first, err := conveyor.NewFileLifeCycleHandler(path)
+	require.NoError(t, err)
+	require.NoError(t, first.MarkStarted())
+
+	second, err := conveyor.NewFileLifeCycleHandler(path)
+	require.NoError(t, err)
+	require.NoError(t, second.MarkToKill())
+
+	state, err := first.GetState()
+	require.NoError(t, err)
+	assert.Equal(t, conveyor.StateToKill, state)
+
+	require.NoError(t, first.UpdateProgress("50.00"))
+	state, _ = second.GetState()
+	assert.Equal(t, conveyor.StateToKill, state, "an update keeps what the other handler marked")
+	progress, _ := second.GetProgress()
+	assert.Equal(t, "50.00", progress)
+}
+
+func TestFileLifeCycleHandler_CorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0o600))
+
+	_, err := conveyor.NewFileLifeCycleHandler(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,225 @@
+package conveyor
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingLifeCycle is a LifeCycleHandler that records every call made to it.
+type recordingLifeCycle struct {
+	mu        sync.Mutex
+	states    []string
+	statusMsg string
+	progress  string
+	failOn    string // state whose marker returns an error
+}
+
+func (r *recordingLifeCycle) mark(state string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if state == r.failOn {
+		return errors.New("marker failed")
+	}
+	r.states = append(r.states, state)
+	return nil
+}
+
+func (r *recordingLifeCycle) recordedStates() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.states...)
+}
+
+func (r *recordingLifeCycle) GetState() (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.states) == 0 {
+		return "", nil
+	}
+	return r.states[len(r.states)-1], nil
+}
+
+func (r *recordingLifeCycle) GetStatusMsg() (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.statusMsg, nil
+}
+
+func (r *recordingLifeCycle) UpdateStatusMsg(msg string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statusMsg = msg
+	return nil
+}
+
+func (r *recordingLifeCycle) GetProgress() (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.progress, nil
+}
+
+func (r *recordingLifeCycle) UpdateProgress(progress string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.progress = progress
+	return nil
+}
+
+func (r *recordingLifeCycle) MarkPreparing() error { return r.mark(StatusPreparing) }
+func (r *recordingLifeCycle) MarkStarted() error   { return r.mark(StateStarted) }
+func (r *recordingLifeCycle) MarkToKill() error    { return r.mark(StateToKill) }
+func (r *recordingLifeCycle) MarkKilled() error    { return r.mark(StateKilled) }
+func (r *recordingLifeCycle) MarkFinished() error  { return r.mark(StateFinished) }
+func (r *recordingLifeCycle) MarkError() error     { return r.mark(StateInternalError) }
+
+// blockingSource emits nothing and returns only once the context is done.
+type blockingSource struct {
+	ConcreteSourceExecutor[int]
+}
+
+func (s *blockingSource) ExecuteLoop(ctx CnvContext, out chan<- int) error {
+	ctx.SendStatus("waiting for data")
+	<-ctx.Done()
+	return nil
+}
+
+// newBlockingConveyor builds a blockingSource → sink conveyor with the given handler.
+func newBlockingConveyor(t *testing.T, lch LifeCycleHandler) *Conveyor {
+	cnv, _ := NewConveyor("blocking", 10)
+	cnv.SetLifeCycleHandler(lch)
+	require.NoError(t, AddSource[int](cnv, &blockingSource{ConcreteSourceExecutor[int]{Name: "src"}}, WorkerModeLoop))
+	require.NoError(t, AddSink[int](cnv, &loopCollectingSink{ConcreteSinkExecutor: ConcreteSinkExecutor[int]{Name: "snk"}}, WorkerModeLoop))
+	return cnv
+}
+
+// ---------------------------------------------------------------------------
+// State machine tests
+// ---------------------------------------------------------------------------
+
+func TestIsValidTransition(t *testing.T) {
+	assert.True(t, isValidTransition("", StatusPreparing))
+	assert.True(t, isValidTransition(StatusPreparing, StateStarted))
+	assert.True(t, isValidTransition(StateStarted, StateFinished))
+	assert.True(t, isValidTransition(StateStarted, StateToKill))
+	assert.True(t, isValidTransition(StateToKill, StateKilled))
+	assert.True(t, isValidTransition(StateStarted, StateInternalError))
+
+	assert.False(t, isValidTransition(StateFinished, StateStarted))
+	assert.False(t, isValidTransition(StateKilled, StateStarted))
+	assert.False(t, isValidTransition(StateStarted, StateKilled))
+	assert.False(t, isValidTransition(StateStarted, StatusPreparing))
+	assert.False(t, isValidTransition(StateInternalError, StateFinished))
+}
+
+// TestMarkCurrentState_RejectsIllegalTransition verifies that finished can't be followed by started,
+// and that the handler isn't called for a rejected transition.
+func TestMarkCurrentState_RejectsIllegalTransition(t *testing.T) {
+	lch := &recordingLifeCycle{}
+	cnv, _ := NewConveyor("states", 10)
+	cnv.SetLifeCycleHandler(lch)
+
+	require.NoError(t, cnv.MarkCurrentState(StatusPreparing))
+	require.NoError(t, cnv.MarkCurrentState(StateStarted))
+	require.NoError(t, cnv.MarkCurrentState(StateFinished))
+
+	err := cnv.MarkCurrentState(StateStarted)
+	assert.True(t, errors.Is(err, ErrIllegalStateTransition))
+	assert.Equal(t, StateFinished, cnv.CurrentState())
+	assert.Equal(t, []string{StatusPreparing, StateStarted, StateFinished}, lch.recordedStates())
+}
+
+// TestMarkCurrentState_HandlerFailureKeepsState verifies that a failing handler doesn't move the state.
+func TestMarkCurrentState_HandlerFailureKeepsState(t *testing.T) {
+	lch := &recordingLifeCycle{failOn: StateStarted}
+	cnv, _ := NewConveyor("states", 10)
+	cnv.SetLifeCycleHandler(lch)
+
+	require.NoError(t, cnv.MarkCurrentState(StatusPreparing))
+	assert.Error(t, cnv.MarkCurrentState(StateStarted))
+	assert.Equal(t, StatusPreparing, cnv.CurrentState())
+}
+
+func TestMarkCurrentState_NoHandler(t *testing.T) {
+	cnv, _ := NewConveyor("states", 10)
+	assert.Equal(t, ErrLifeCycleNotSupported, cnv.MarkCurrentState(StateStarted))
+}
+
+// ---------------------------------------------------------------------------
+// Automatic transitions
+// ---------------------------------------------------------------------------
+
+func TestLifeCycle_StartMarksFinished(t *testing.T) {
+	lch := &recordingLifeCycle{}
+	cnv, _ := NewConveyor("lifecycle", 10)
+	cnv.SetLifeCycleHandler(lch)
+
+	src := &countingSource{ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"}, limit: 3}
+	require.NoError(t, AddSource[int](cnv, src, WorkerModeTransaction))
+	require.NoError(t, AddSink[int](cnv, &collectingSink{ConcreteSinkExecutor: ConcreteSinkExecutor[int]{Name: "snk"}}, WorkerModeTransaction))
+
+	require.NoError(t, cnv.Start())
+	assert.Equal(t, []string{StatusPreparing, StateStarted, StateFinished}, lch.recordedStates())
+}
+
+func TestLifeCycle_StopMarksKilled(t *testing.T) {
+	lch := &recordingLifeCycle{}
+	cnv := newBlockingConveyor(t, lch)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, cnv.Start())
+	}()
+
+	require.Eventually(t, func() bool { return cnv.CurrentState() == StateStarted }, time.Second, time.Millisecond)
+	cnv.Stop()
+	<-done
+
+	assert.Equal(t, []string{StatusPreparing, StateStarted, StateToKill, StateKilled}, lch.recordedStates())
+}
+
+func TestLifeCycle_TimeoutMarksError(t *testing.T) {
+	lch := &recordingLifeCycle{}
+	cnv, _ := NewConveyor("timeout", 10)
+	cnv.SetTimeout(20 * time.Millisecond).SetLifeCycleHandler(lch)
+	require.NoError(t, AddSource[int](cnv, &blockingSource{ConcreteSourceExecutor[int]{Name: "src"}}, WorkerModeLoop))
+	require.NoError(t, AddSink[int](cnv, &loopCollectingSink{ConcreteSinkExecutor: ConcreteSinkExecutor[int]{Name: "snk"}}, WorkerModeLoop))
+
+	require.NoError(t, cnv.Start())
+	assert.Equal(t, []string{StatusPreparing, StateStarted, StateInternalError}, lch.recordedStates())
+}
+
+// TestLifeCycle_StartFailureMarksError verifies that a worker that can't start fails the conveyor.
+func TestLifeCycle_StartFailureMarksError(t *testing.T) {
+	lch := &recordingLifeCycle{}
+	cnv := newBlockingConveyor(t, lch)
+	cnv.workers[1].(*SinkWorkerPool[int]).Mode = WorkerMode(0)
+
+	require.NoError(t, cnv.Start())
+	assert.Equal(t, []string{StatusPreparing, StateStarted, StateInternalError}, lch.recordedStates())
+}
+
+// TestLifeCycle_MirrorsStatusAndProgress verifies that status messages and progress
+// reach the handler without anyone reading Status() or Progress().
+func TestLifeCycle_MirrorsStatusAndProgress(t *testing.T) {
+	lch := &recordingLifeCycle{}
+	cnv, _ := NewConveyor("mirror", 10)
+	cnv.SetTimeout(50 * time.Millisecond).SetLifeCycleHandler(lch).SetLifeCycleSyncInterval(time.Millisecond)
+	cnv.EnableProgress(100 * time.Millisecond)
+	cnv.tickProgress = time.Millisecond
+	require.NoError(t, AddSource[int](cnv, &blockingSource{ConcreteSourceExecutor[int]{Name: "src"}}, WorkerModeLoop))
+	require.NoError(t, AddSink[int](cnv, &loopCollectingSink{ConcreteSinkExecutor: ConcreteSinkExecutor[int]{Name: "snk"}}, WorkerModeLoop))
+
+	require.NoError(t, cnv.Start())
+
+	status, _ := lch.GetStatusMsg()
+	assert.Equal(t, "waiting for data", status)
+	progress, _ := lch.GetProgress()
+	assert.NotEmpty(t, progress)
+	assert.NotEqual(t, "0.00", progress)
+}
@@ -0,0 +1,428 @@
+package conveyor
+
+import (
+	"sync"
+	"time"
+)
+
+// RestartMode decides if a Manager starts a conveyor again, once a run is over
+type RestartMode uint8
+
+const (
+	// RestartNever runs a conveyor only once
+	RestartNever = RestartMode(iota)
+
+	// RestartOnFailure runs a conveyor again if it ended in StateInternalError (e.g. timed out),
+	// waiting longer after every consecutive failure
+	RestartOnFailure
+
+	// RestartAlways runs a conveyor again whenever it ends, unless it was stopped through the Manager
+	RestartAlways
+)
+
+// DefaultRestartBackoff is the Backoff of a RestartPolicy, if it's <= 0, so that a conveyor isn't restarted in a tight loop
+const DefaultRestartBackoff = time.Second
+
+// RestartPolicy tells a Manager when and how often to restart a conveyor
+type RestartPolicy struct {
+	Mode RestartMode
+
+	// MaxRestarts limits how many times a conveyor is restarted. 0 means no limit.
+	MaxRestarts int
+
+	// Backoff is the wait before a restart, DefaultRestartBackoff if it's <= 0. With RestartOnFailure,
+	// it's doubled after every consecutive failure, up to MaxBackoff.
+	Backoff time.Duration
+
+	// MaxBackoff caps the wait between restarts. 0 means no cap.
+	MaxBackoff time.Duration
+}
+
+// delay returns how long to wait before restarting, after the given number of consecutive failures
+func (rp RestartPolicy) delay(failures int) time.Duration {
+	delay := rp.Backoff
+	if delay <= 0 {
+		delay = DefaultRestartBackoff
+	}
+	for i := 1; i < failures; i++ {
+		delay *= 2
+		if rp.MaxBackoff > 0 && delay >= rp.MaxBackoff {
+			break
+		}
+	}
+	if rp.MaxBackoff > 0 && delay > rp.MaxBackoff {
+		delay = rp.MaxBackoff
+	}
+	return delay
+}
+
+// ConveyorFactory builds a new Conveyor, ready to be started. A Manager calls it for every restart,
+// as a Conveyor can only be run once.
+type ConveyorFactory func() (*Conveyor, error)
+
+// RunRecord describes one completed run of a conveyor managed by a Manager
+type RunRecord struct {
+	ID         string
+	Name       string
+	Attempt    int // 1 for the first run, 2 for the first restart, and so on
+	StartedAt  time.Time
+	FinishedAt time.Time
+	State      string // final state of the run, e.g. StateFinished or StateKilled
+	Errors     int64  // number of errors recorded by the pipeline during the run
+	Err        error  // error returned by the factory or by Start(), if any
+}
+
+// Failed tells if the run is considered a failure by RestartOnFailure
+func (rr RunRecord) Failed() bool {
+	return rr.Err != nil || rr.State == StateInternalError
+}
+
+// ConveyorInfo is a point-in-time view of a conveyor registered with a Manager
+type ConveyorInfo struct {
+	ID       string
+	Name     string
+	Queued   bool // waiting for a free slot to start
+	Running  bool
+	State    string
+	Progress ProgressReport
+	Errors   map[string]int64 // per-type error counts, as returned by ErrorStats.Snapshot()
+	Restarts int
+}
+
+// managedConveyor is the Manager's bookkeeping for one registered conveyor
+type managedConveyor struct {
+	id       string
+	factory  ConveyorFactory
+	policy   RestartPolicy
+	cnv      *Conveyor // current (or last) run
+	active   bool      // a run goroutine is alive, either queued, running or waiting to restart
+	queued   bool
+	running  bool
+	stopped  bool          // Stop() was called, so no more runs
+	stopCh   chan struct{} // closed by Stop(), to abort waiting for a slot or a restart
+	restarts int
+}
+
+// Manager is a registry and supervisor for many conveyors, keyed by their ID.
+// It starts them with a bounded global concurrency, lets you inspect and stop them,
+// restarts them according to their RestartPolicy, and keeps a bounded history of completed runs.
+// All methods are safe for concurrent use.
+type Manager struct {
+	mu           sync.Mutex
+	slots        chan struct{}
+	conveyors    map[string]*managedConveyor
+	history      []RunRecord
+	historyLimit int
+	wg           sync.WaitGroup
+}
+
+// NewManager creates a Manager that runs at most maxConcurrent conveyors at a time,
+// and remembers the last historyLimit completed runs. Non-positive values default to 10 and 100.
+func NewManager(maxConcurrent, historyLimit int) *Manager {
+	if maxConcurrent <= 0 {
+		maxConcurrent = 10
+	}
+	if historyLimit <= 0 {
+		historyLimit = 100
+	}
+	return &Manager{
+		slots:        make(chan struct{}, maxConcurrent),
+		conveyors:    make(map[string]*managedConveyor),
+		historyLimit: historyLimit,
+	}
+}
+
+// Register adds a ready-to-start conveyor under its ID. Such a conveyor is never restarted,
+// use RegisterFactory for that.
+func (m *Manager) Register(cnv *Conveyor) error {
+	factory := func() (*Conveyor, error) {
+		return nil, ErrConveyorNotRestartable
+	}
+	return m.register(cnv, factory, RestartPolicy{Mode: RestartNever})
+}
+
+// RegisterFactory builds a conveyor with factory and registers it under its ID.
+// The factory is called again for every restart allowed by policy, and the new
+// conveyors are tracked under the ID of the first one.
+func (m *Manager) RegisterFactory(factory ConveyorFactory, policy RestartPolicy) (string, error) {
+	cnv, err := factory()
+	if err != nil {
+		return "", err
+	}
+	return cnv.ID(), m.register(cnv, factory, policy)
+}
+
+func (m *Manager) register(cnv *Conveyor, factory ConveyorFactory, policy RestartPolicy) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.conveyors[cnv.ID()]; ok {
+		return ErrDuplicateConveyorID
+	}
+	m.conveyors[cnv.ID()] = &managedConveyor{
+		id:      cnv.ID(),
+		factory: factory,
+		policy:  policy,
+		cnv:     cnv,
+	}
+	return nil
+}
+
+// Remove unregisters a conveyor that isn't running. Its completed runs remain in the history.
+func (m *Manager) Remove(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mc, ok := m.conveyors[id]
+	if !ok {
+		return ErrConveyorNotFound
+	}
+	if mc.active {
+		return ErrConveyorAlreadyRunning
+	}
+	delete(m.conveyors, id)
+	return nil
+}
+
+// Start queues a registered conveyor to be started as soon as a slot is free. It doesn't wait for the run.
+func (m *Manager) Start(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mc, ok := m.conveyors[id]
+	if !ok {
+		return ErrConveyorNotFound
+	}
+	if mc.active {
+		return ErrConveyorAlreadyRunning
+	}
+	if mc.stopped || mc.cnv.CurrentState() != "" {
+		// Conveyors are one-shot, so a new one is needed
+		cnv, err := mc.factory()
+		if err != nil {
+			return err
+		}
+		mc.cnv = cnv
+	}
+
+	mc.active = true
+	mc.queued = true
+	mc.stopped = false
+	mc.stopCh = make(chan struct{})
+	m.wg.Add(1)
+	go m.supervise(mc)
+	return nil
+}
+
+// StartAll queues every registered conveyor that isn't already active
+func (m *Manager) StartAll() {
+	m.mu.Lock()
+	ids := make([]string, 0, len(m.conveyors))
+	for id, mc := range m.conveyors {
+		if !mc.active {
+			ids = append(ids, id)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, id := range ids {
+		_ = m.Start(id)
+	}
+}
+
+// Stop kills a conveyor, and cancels any queued run or pending restart of it
+func (m *Manager) Stop(id string) error {
+	m.mu.Lock()
+	mc, ok := m.conveyors[id]
+	if !ok {
+		m.mu.Unlock()
+		return ErrConveyorNotFound
+	}
+	if !mc.active || mc.stopped {
+		m.mu.Unlock()
+		return nil
+	}
+	mc.stopped = true
+	close(mc.stopCh)
+	cnv, running := mc.cnv, mc.running
+	m.mu.Unlock()
+
+	if running {
+		cnv.Stop()
+	}
+	return nil
+}
+
+// Shutdown stops every conveyor, and waits for all of them to return
+func (m *Manager) Shutdown() {
+	m.mu.Lock()
+	ids := make([]string, 0, len(m.conveyors))
+	for id := range m.conveyors {
+		ids = append(ids, id)
+	}
+	m.mu.Unlock()
+
+	for _, id := range ids {
+		_ = m.Stop(id)
+	}
+	m.Wait()
+}
+
+// Wait blocks until no conveyor is queued, running, or waiting to be restarted
+func (m *Manager) Wait() {
+	m.wg.Wait()
+}
+
+// Get returns a point-in-time view of a registered conveyor
+func (m *Manager) Get(id string) (ConveyorInfo, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mc, ok := m.conveyors[id]
+	if !ok {
+		return ConveyorInfo{}, false
+	}
+	return mc.info(), true
+}
+
+// List returns a point-in-time view of every registered conveyor
+func (m *Manager) List() []ConveyorInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	infos := make([]ConveyorInfo, 0, len(m.conveyors))
+	for _, mc := range m.conveyors {
+		infos = append(infos, mc.info())
+	}
+	return infos
+}
+
+// History returns the completed runs, oldest first
+func (m *Manager) History() []RunRecord {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]RunRecord(nil), m.history...)
+}
+
+// info must be called with the Manager's lock held
+func (mc *managedConveyor) info() ConveyorInfo {
+	return ConveyorInfo{
+		ID:       mc.id,
+		Name:     mc.cnv.Name,
+		Queued:   mc.queued,
+		Running:  mc.running,
+		State:    mc.cnv.CurrentState(),
+		Progress: mc.cnv.ProgressReport(),
+		Errors:   mc.cnv.Errors().Snapshot(),
+		Restarts: mc.restarts,
+	}
+}
+
+// supervise runs a conveyor, and keeps restarting it as long as its policy allows
+func (m *Manager) supervise(mc *managedConveyor) {
+	defer m.wg.Done()
+	defer func() {
+		m.mu.Lock()
+		mc.active, mc.queued, mc.running = false, false, false
+		m.mu.Unlock()
+	}()
+
+	failures := 0
+	for attempt := 1; ; attempt++ {
+		record, ok := m.runOnce(mc, attempt)
+		if !ok {
+			return // stopped before it could run
+		}
+
+		m.mu.Lock()
+		m.record(record)
+		stopped := mc.stopped
+		m.mu.Unlock()
+
+		if record.Failed() {
+			failures++
+		} else {
+			failures = 0
+		}
+
+		if stopped || !mc.shouldRestart(record, attempt) {
+			return
+		}
+
+		select {
+		case <-mc.stopCh:
+			return
+		case <-time.After(mc.policy.delay(failures)):
+		}
+
+		cnv, err := mc.factory()
+		if err != nil {
+			m.mu.Lock()
+			m.record(RunRecord{ID: mc.id, Attempt: attempt + 1, Err: err, StartedAt: time.Now(), FinishedAt: time.Now()})
+			m.mu.Unlock()
+			return
+		}
+
+		m.mu.Lock()
+		mc.cnv = cnv
+		mc.restarts++
+		mc.queued = true
+		m.mu.Unlock()
+	}
+}
+
+// record adds a completed run to the history, forgetting the oldest ones beyond historyLimit.
+// It must be called with the Manager's lock held.
+func (m *Manager) record(record RunRecord) {
+	m.history = append(m.history, record)
+	if len(m.history) > m.historyLimit {
+		m.history = m.history[len(m.history)-m.historyLimit:]
+	}
+}
+
+// shouldRestart applies the restart policy to a completed run
+func (mc *managedConveyor) shouldRestart(record RunRecord, attempt int) bool {
+	if mc.policy.MaxRestarts > 0 && attempt > mc.policy.MaxRestarts {
+		return false
+	}
+	switch mc.policy.Mode {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return record.Failed()
+	default:
+		return false
+	}
+}
+
+// runOnce waits for a free slot, and runs the current conveyor to completion.
+// Returns false if the conveyor was stopped while waiting for a slot.
+func (m *Manager) runOnce(mc *managedConveyor, attempt int) (RunRecord, bool) {
+	select {
+	case <-mc.stopCh:
+		return RunRecord{}, false
+	case m.slots <- struct{}{}:
+	}
+	defer func() { <-m.slots }()
+
+	m.mu.Lock()
+	if mc.stopped {
+		m.mu.Unlock()
+		return RunRecord{}, false
+	}
+	cnv := mc.cnv
+	mc.queued, mc.running = false, true
+	m.mu.Unlock()
+
+	record := RunRecord{ID: mc.id, Name: cnv.Name, Attempt: attempt, StartedAt: time.Now()}
+	record.Err = cnv.Start()
+	record.FinishedAt = time.Now()
+	record.State = cnv.CurrentState()
+	record.Errors = cnv.Errors().Total()
+
+	m.mu.Lock()
+	mc.running = false
+	m.mu.Unlock()
+
+	return record, true
+}
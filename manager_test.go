@@ -0,0 +1,279 @@
+package conveyor
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gaugeSink records the highest number of sinks running at the same time, across conveyors.
+type gaugeSink struct {
+	ConcreteSinkExecutor[int]
+	current *atomic.Int64
+	peak    *atomic.Int64
+}
+
+func (s *gaugeSink) Execute(ctx CnvContext, in int) error {
+	n := s.current.Add(1)
+	defer s.current.Add(-1)
+	for {
+		peak := s.peak.Load()
+		if n <= peak || s.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	return nil
+}
+
+// newFiniteConveyor builds a conveyor that emits a few items and finishes.
+func newFiniteConveyor(t *testing.T, id string) *Conveyor {
+	cnv, _ := NewConveyor("finite", 10)
+	cnv.SetID(id)
+	src := &countingSource{ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"}, limit: 2}
+	require.NoError(t, AddSource[int](cnv, src, WorkerModeTransaction))
+	require.NoError(t, AddSink[int](cnv, &collectingSink{ConcreteSinkExecutor: ConcreteSinkExecutor[int]{Name: "snk"}}, WorkerModeTransaction))
+	return cnv
+}
+
+// newIdleConveyor builds a conveyor that runs until stopped.
+func newIdleConveyor(t *testing.T, id string) *Conveyor {
+	cnv, _ := NewConveyor("idle", 10)
+	cnv.SetID(id)
+	require.NoError(t, AddSource[int](cnv, &blockingSource{ConcreteSourceExecutor[int]{Name: "src"}}, WorkerModeLoop))
+	require.NoError(t, AddSink[int](cnv, &loopCollectingSink{ConcreteSinkExecutor: ConcreteSinkExecutor[int]{Name: "snk"}}, WorkerModeLoop))
+	return cnv
+}
+
+// newTimingOutConveyor builds a conveyor that never finishes on its own, and times out.
+func newTimingOutConveyor(id string) (*Conveyor, error) {
+	cnv, _ := NewConveyor("timing_out", 10)
+	cnv.SetID(id).SetTimeout(5 * time.Millisecond)
+	if err := AddSource[int](cnv, &blockingSource{ConcreteSourceExecutor[int]{Name: "src"}}, WorkerModeLoop); err != nil {
+		return nil, err
+	}
+	err := AddSink[int](cnv, &loopCollectingSink{ConcreteSinkExecutor: ConcreteSinkExecutor[int]{Name: "snk"}}, WorkerModeLoop)
+	return cnv, err
+}
+
+func TestManager_RegisterDuplicate(t *testing.T) {
+	m := NewManager(1, 10)
+	require.NoError(t, m.Register(newFiniteConveyor(t, "a")))
+	assert.Equal(t, ErrDuplicateConveyorID, m.Register(newFiniteConveyor(t, "a")))
+}
+
+func TestManager_UnknownID(t *testing.T) {
+	m := NewManager(1, 10)
+	assert.Equal(t, ErrConveyorNotFound, m.Start("missing"))
+	assert.Equal(t, ErrConveyorNotFound, m.Stop("missing"))
+	assert.Equal(t, ErrConveyorNotFound, m.Remove("missing"))
+	_, ok := m.Get("missing")
+	assert.False(t, ok)
+}
+
+// TestManager_BoundedConcurrency verifies that no more than maxConcurrent conveyors run at once.
+func TestManager_BoundedConcurrency(t *testing.T) {
+	m := NewManager(2, 10)
+	var current, peak atomic.Int64
+
+	for i := 0; i < 5; i++ {
+		cnv, _ := NewConveyor("gauge", 10)
+		cnv.SetID(fmt.Sprintf("cnv-%d", i))
+		src := &countingSource{ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"}, limit: 2}
+		require.NoError(t, AddSource[int](cnv, src, WorkerModeTransaction))
+		snk := &gaugeSink{ConcreteSinkExecutor: ConcreteSinkExecutor[int]{Name: "snk"}, current: &current, peak: &peak}
+		require.NoError(t, AddSink[int](cnv, snk, WorkerModeTransaction))
+		require.NoError(t, m.Register(cnv))
+	}
+
+	m.StartAll()
+	m.Wait()
+
+	assert.LessOrEqual(t, peak.Load(), int64(2))
+	history := m.History()
+	assert.Len(t, history, 5)
+	for _, run := range history {
+		assert.Equal(t, StateFinished, run.State)
+		assert.False(t, run.Failed())
+	}
+	for _, info := range m.List() {
+		assert.False(t, info.Running)
+		assert.Equal(t, StateFinished, info.State)
+	}
+}
+
+func TestManager_StopRunning(t *testing.T) {
+	m := NewManager(1, 10)
+	cnv := newIdleConveyor(t, "idle")
+	require.NoError(t, m.Register(cnv))
+	require.NoError(t, m.Start(cnv.ID()))
+
+	require.Eventually(t, func() bool {
+		info, _ := m.Get(cnv.ID())
+		return info.Running && info.State == StateStarted
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, ErrConveyorAlreadyRunning, m.Start(cnv.ID()))
+	assert.Equal(t, ErrConveyorAlreadyRunning, m.Remove(cnv.ID()))
+
+	require.NoError(t, m.Stop(cnv.ID()))
+	m.Wait()
+
+	info, _ := m.Get(cnv.ID())
+	assert.Equal(t, StateKilled, info.State)
+	require.Len(t, m.History(), 1)
+	assert.Equal(t, StateKilled, m.History()[0].State)
+	require.NoError(t, m.Remove(cnv.ID()))
+}
+
+// TestManager_StopQueued verifies that stopping a queued conveyor means it never runs.
+func TestManager_StopQueued(t *testing.T) {
+	m := NewManager(1, 10)
+	running := newIdleConveyor(t, "running")
+	queued := newFiniteConveyor(t, "queued")
+	require.NoError(t, m.Register(running))
+	require.NoError(t, m.Register(queued))
+
+	require.NoError(t, m.Start("running"))
+	require.Eventually(t, func() bool {
+		info, _ := m.Get("running")
+		return info.Running
+	}, time.Second, time.Millisecond)
+	require.NoError(t, m.Start("queued"))
+
+	info, _ := m.Get("queued")
+	assert.True(t, info.Queued)
+
+	require.NoError(t, m.Stop("queued"))
+	m.Shutdown()
+
+	assert.Equal(t, "", queued.CurrentState())
+	require.Len(t, m.History(), 1)
+	assert.Equal(t, "running", m.History()[0].ID)
+}
+
+func TestManager_RegisteredConveyorIsOneShot(t *testing.T) {
+	m := NewManager(1, 10)
+	require.NoError(t, m.Register(newFiniteConveyor(t, "once")))
+	require.NoError(t, m.Start("once"))
+	m.Wait()
+	assert.Equal(t, ErrConveyorNotRestartable, m.Start("once"))
+}
+
+func TestManager_RestartOnFailure(t *testing.T) {
+	m := NewManager(1, 10)
+	builds := 0
+	id, err := m.RegisterFactory(func() (*Conveyor, error) {
+		builds++
+		return newTimingOutConveyor("flaky")
+	}, RestartPolicy{Mode: RestartOnFailure, MaxRestarts: 2, Backoff: time.Millisecond})
+	require.NoError(t, err)
+	assert.Equal(t, "flaky", id)
+
+	require.NoError(t, m.Start(id))
+	m.Wait()
+
+	history := m.History()
+	require.Len(t, history, 3)
+	for i, run := range history {
+		assert.Equal(t, i+1, run.Attempt)
+		assert.Equal(t, StateInternalError, run.State)
+		assert.True(t, run.Failed())
+	}
+	info, _ := m.Get(id)
+	assert.Equal(t, 2, info.Restarts)
+	assert.Equal(t, 3, builds)
+}
+
+// TestManager_RestartOnFailure_SuccessStops verifies that a successful run isn't restarted.
+func TestManager_RestartOnFailure_SuccessStops(t *testing.T) {
+	m := NewManager(1, 10)
+	id, err := m.RegisterFactory(func() (*Conveyor, error) {
+		return newFiniteConveyor(t, "stable"), nil
+	}, RestartPolicy{Mode: RestartOnFailure, Backoff: time.Millisecond})
+	require.NoError(t, err)
+
+	require.NoError(t, m.Start(id))
+	m.Wait()
+	assert.Len(t, m.History(), 1)
+}
+
+func TestManager_RestartAlways_BoundedHistory(t *testing.T) {
+	m := NewManager(1, 2)
+	id, err := m.RegisterFactory(func() (*Conveyor, error) {
+		return newFiniteConveyor(t, "always"), nil
+	}, RestartPolicy{Mode: RestartAlways, MaxRestarts: 3, Backoff: time.Millisecond})
+	require.NoError(t, err)
+
+	require.NoError(t, m.Start(id))
+	m.Wait()
+
+	history := m.History()
+	require.Len(t, history, 2)
+	assert.Equal(t, 3, history[0].Attempt)
+	assert.Equal(t, 4, history[1].Attempt)
+}
+
+// TestManager_FactoryErrorOnRestart verifies that a factory failing to rebuild a conveyor ends the restarts,
+// and that its failure is recorded in the bounded history.
+func TestManager_FactoryErrorOnRestart(t *testing.T) {
+	m := NewManager(1, 1)
+	factoryErr := errors.New("no database")
+	builds := 0
+	id, err := m.RegisterFactory(func() (*Conveyor, error) {
+		if builds++; builds > 1 {
+			return nil, factoryErr
+		}
+		return newTimingOutConveyor("rebuild")
+	}, RestartPolicy{Mode: RestartOnFailure, Backoff: time.Millisecond})
+	require.NoError(t, err)
+
+	require.NoError(t, m.Start(id))
+	m.Wait()
+
+	history := m.History()
+	require.Len(t, history, 1)
+	assert.Equal(t, 2, history[0].Attempt)
+	assert.Equal(t, factoryErr, history[0].Err)
+	assert.Equal(t, 2, builds)
+}
+
+// TestManager_StopCancelsRestart verifies that Stop() aborts the wait before a restart.
+func TestManager_StopCancelsRestart(t *testing.T) {
+	m := NewManager(1, 10)
+	id, err := m.RegisterFactory(func() (*Conveyor, error) {
+		return newTimingOutConveyor("backoff")
+	}, RestartPolicy{Mode: RestartOnFailure, Backoff: time.Hour})
+	require.NoError(t, err)
+
+	require.NoError(t, m.Start(id))
+	require.Eventually(t, func() bool { return len(m.History()) == 1 }, time.Second, time.Millisecond)
+	require.NoError(t, m.Stop(id))
+	m.Wait()
+	assert.Len(t, m.History(), 1)
+}
+
+func TestManager_FactoryError(t *testing.T) {
+	m := NewManager(1, 10)
+	factoryErr := errors.New("no database")
+	_, err := m.RegisterFactory(func() (*Conveyor, error) { return nil, factoryErr }, RestartPolicy{})
+	assert.Equal(t, factoryErr, err)
+}
+
+func TestRestartPolicy_Delay(t *testing.T) {
+	rp := RestartPolicy{Backoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}
+	assert.Equal(t, 10*time.Millisecond, rp.delay(0))
+	assert.Equal(t, 10*time.Millisecond, rp.delay(1))
+	assert.Equal(t, 20*time.Millisecond, rp.delay(2))
+	assert.Equal(t, 40*time.Millisecond, rp.delay(3))
+	assert.Equal(t, 50*time.Millisecond, rp.delay(4))
+	assert.Equal(t, 50*time.Millisecond, rp.delay(100))
+
+	rp = RestartPolicy{Mode: RestartAlways}
+	assert.Equal(t, DefaultRestartBackoff, rp.delay(0), "no tight restart loop without a Backoff")
+	assert.Equal(t, 2*DefaultRestartBackoff, rp.delay(2))
+}
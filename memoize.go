@@ -0,0 +1,236 @@
+package conveyor
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CacheConfig sets how a Memoized operation caches its results
+type CacheConfig[TIn any, K comparable] struct {
+	// Key returns the key the result of an item is cached by
+	Key func(item TIn) K
+	// TTL is how long a result is cached, forever if 0
+	TTL time.Duration
+	// MaxSize is how many results are cached, the least recently used being evicted first
+	MaxSize int
+}
+
+// CacheStats counts the items of a Memoized operation
+type CacheStats struct {
+	Hits      int64 // items whose result was cached
+	Misses    int64 // items the decorated executor was called for
+	Coalesced int64 // items that waited for the result of another item with the same key
+	Evictions int64 // results evicted to stay within MaxSize
+}
+
+// HitRate returns the share of items that didn't call the decorated executor, coalesced ones included
+func (cs CacheStats) HitRate() float64 {
+	total := cs.Hits + cs.Misses + cs.Coalesced
+	if total == 0 {
+		return 0
+	}
+	return float64(cs.Hits+cs.Coalesced) / float64(total)
+}
+
+// Caches gives the CacheStats of the conveyor's Memoized operations, per stage
+func (cnv *Conveyor) Caches() *CacheStatsByStage {
+	return cnv.caches
+}
+
+// CacheStatsByStage gives the CacheStats of the Memoized operations of a conveyor, by their name.
+// All methods are safe for concurrent use.
+type CacheStatsByStage struct {
+	byStage sync.Map // key: stage name → cached
+}
+
+// cached is implemented by operations with a cache, like Memoized
+type cached interface {
+	CacheStats() CacheStats
+}
+
+// register adds exec if it has a cache
+func (cs *CacheStatsByStage) register(exec nodeExecutor) {
+	if c, ok := exec.(cached); ok {
+		cs.byStage.Store(exec.GetName(), c)
+	}
+}
+
+// Stage returns the CacheStats of the operation named stage, zero if it has no cache
+func (cs *CacheStatsByStage) Stage(stage string) CacheStats {
+	c, ok := cs.byStage.Load(stage)
+	if !ok {
+		return CacheStats{}
+	}
+	return c.(cached).CacheStats()
+}
+
+// Snapshot returns the CacheStats of every operation with a cache, by stage
+func (cs *CacheStatsByStage) Snapshot() map[string]CacheStats {
+	result := make(map[string]CacheStats)
+	cs.byStage.Range(func(k, v any) bool {
+		result[k.(string)] = v.(cached).CacheStats()
+		return true
+	})
+	return result
+}
+
+// Memoized decorates an OperationExecutor, to cache its results by key. Items with the same key,
+// processed at the same time, are coalesced: the decorated Execute() only runs for one of them, and the others
+// get its result. Errors aren't cached, but coalesced items get them too, matching ErrCoalesced, so that
+// a failure is only recorded once.
+//
+// In WorkerModeLoop, items are handed to the decorated Execute() one by one, so it must implement it.
+type Memoized[TIn, TOut any, K comparable] struct {
+	OperationExecutor[TIn, TOut]
+	cfg CacheConfig[TIn, K]
+
+	mu       sync.Mutex
+	recent   *list.List            // *cachedResult, the most recently used first
+	results  map[K]*list.Element   // the elements of recent, by key
+	inFlight map[K]*memoCall[TOut] // items being processed, by key
+
+	hits, misses, coalesced, evictions atomic.Int64
+}
+
+type cachedResult[TOut any, K comparable] struct {
+	key     K
+	out     TOut
+	expires time.Time // zero if it doesn't
+}
+
+// memoCall is the item being processed for a key, that coalesced items wait for
+type memoCall[TOut any] struct {
+	done chan struct{}
+	out  TOut
+	err  error
+}
+
+// NewMemoized decorates exec with a cache of up to cfg.MaxSize results
+func NewMemoized[TIn, TOut any, K comparable](exec OperationExecutor[TIn, TOut], cfg CacheConfig[TIn, K]) (*Memoized[TIn, TOut, K], error) {
+	if cfg.Key == nil || cfg.TTL < 0 || cfg.MaxSize <= 0 {
+		return nil, ErrInvalidCache
+	}
+	return &Memoized[TIn, TOut, K]{
+		OperationExecutor: exec,
+		cfg:               cfg,
+		recent:            list.New(),
+		results:           make(map[K]*list.Element),
+		inFlight:          make(map[K]*memoCall[TOut]),
+	}, nil
+}
+
+// CacheStats returns the number of hits, misses, coalesced items & evictions so far
+func (m *Memoized[TIn, TOut, K]) CacheStats() CacheStats {
+	return CacheStats{
+		Hits:      m.hits.Load(),
+		Misses:    m.misses.Load(),
+		Coalesced: m.coalesced.Load(),
+		Evictions: m.evictions.Load(),
+	}
+}
+
+// Execute returns the cached result of inData's key, or waits for the item being processed with it,
+// or calls the decorated executor
+func (m *Memoized[TIn, TOut, K]) Execute(ctx CnvContext, inData TIn) (TOut, error) {
+	key := m.cfg.Key(inData)
+	now := time.Now()
+
+	m.mu.Lock()
+	if el, ok := m.results[key]; ok {
+		cached := el.Value.(*cachedResult[TOut, K])
+		if cached.expires.IsZero() || now.Before(cached.expires) {
+			m.recent.MoveToFront(el)
+			m.mu.Unlock()
+			m.hits.Add(1)
+			return cached.out, nil
+		}
+		m.evict(el)
+	}
+	if call, ok := m.inFlight[key]; ok {
+		m.mu.Unlock()
+		m.coalesced.Add(1)
+		select {
+		case <-ctx.Done():
+			var zero TOut
+			return zero, ctx.Err()
+		case <-call.done:
+			if call.err != nil {
+				return call.out, fmt.Errorf("%w: %w", ErrCoalesced, call.err)
+			}
+			return call.out, nil
+		}
+	}
+	call := &memoCall[TOut]{done: make(chan struct{})}
+	m.inFlight[key] = call
+	m.mu.Unlock()
+
+	m.misses.Add(1)
+	return m.call(ctx, key, inData, call)
+}
+
+// call runs the decorated executor for inData, and hands its result to the coalesced items, even if it panics
+func (m *Memoized[TIn, TOut, K]) call(ctx CnvContext, key K, inData TIn, call *memoCall[TOut]) (TOut, error) {
+	defer func() {
+		if r := recover(); r != nil {
+			call.err = &PanicError{Value: r}
+			m.finish(key, call)
+			panic(r)
+		}
+		m.finish(key, call)
+	}()
+
+	call.out, call.err = m.OperationExecutor.Execute(ctx, inData)
+	return call.out, call.err
+}
+
+// finish caches the result of call, unless it failed, and releases the items waiting for it
+func (m *Memoized[TIn, TOut, K]) finish(key K, call *memoCall[TOut]) {
+	m.mu.Lock()
+	delete(m.inFlight, key)
+	if call.err == nil {
+		cached := &cachedResult[TOut, K]{key: key, out: call.out}
+		if m.cfg.TTL > 0 {
+			cached.expires = time.Now().Add(m.cfg.TTL)
+		}
+		m.results[key] = m.recent.PushFront(cached)
+		if m.recent.Len() > m.cfg.MaxSize {
+			m.evict(m.recent.Back())
+			m.evictions.Add(1)
+		}
+	}
+	m.mu.Unlock()
+	close(call.done)
+}
+
+func (m *Memoized[TIn, TOut, K]) evict(el *list.Element) {
+	m.recent.Remove(el)
+	delete(m.results, el.Value.(*cachedResult[TOut, K]).key)
+}
+
+// ExecuteLoop calls Execute() for every item from inChan
+func (m *Memoized[TIn, TOut, K]) ExecuteLoop(ctx CnvContext, inChan <-chan TIn, outChan chan<- TOut) error {
+	for in := range inChan {
+		out, err := m.Execute(ctx, in)
+		if errors.Is(err, ErrSkipItem) {
+			skipItem(ctx, in)
+			continue
+		}
+		if errors.Is(err, ErrCoalesced) {
+			continue
+		}
+		if err != nil {
+			ctx.RecordError(m.GetName(), err)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case outChan <- out:
+		}
+	}
+	return nil
+}
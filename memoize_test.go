@@ -0,0 +1,152 @@
+package conveyor
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lookup squares its items, counting its calls
+func lookup(calls *atomic.Int64) OperationExecutor[int, int] {
+	return OperationFunc("lookup", 4, func(ctx CnvContext, in int) (int, error) {
+		calls.Add(1)
+		return in * in, nil
+	})
+}
+
+func modFive(n int) int { return n % 5 }
+
+// TestMemoized_Pipeline verifies that the decorated executor only runs once per key, in both modes.
+func TestMemoized_Pipeline(t *testing.T) {
+	for _, mode := range []WorkerMode{WorkerModeTransaction, WorkerModeLoop} {
+		var calls atomic.Int64
+		memo, err := NewMemoized(lookup(&calls), CacheConfig[int, int]{Key: modFive, MaxSize: 10})
+		require.NoError(t, err)
+
+		cnv, _ := NewConveyor("memoized", 10)
+		require.NoError(t, AddSource[int](cnv, SourceFromSeq("src", slices.Values(slices.Repeat([]int{0, 1, 2, 3, 4}, 20))), WorkerModeLoop))
+		require.NoError(t, AddOperation[int, int](cnv, memo, mode))
+
+		results, err := Collect[int](context.Background(), cnv)
+		require.NoError(t, err)
+		assert.Len(t, results, 100)
+		assert.Equal(t, int64(5), calls.Load(), "mode: %d", mode)
+
+		stats := memo.CacheStats()
+		assert.Equal(t, int64(5), stats.Misses)
+		assert.Equal(t, int64(95), stats.Hits+stats.Coalesced)
+		assert.InDelta(t, 0.95, stats.HitRate(), 1e-9)
+		assert.Equal(t, stats, cnv.Caches().Stage("lookup"))
+		assert.Equal(t, map[string]CacheStats{"lookup": stats}, cnv.Caches().Snapshot())
+	}
+}
+
+// TestMemoized_Coalesced verifies that items with the same key wait for the one being processed.
+func TestMemoized_Coalesced(t *testing.T) {
+	var calls atomic.Int64
+	release := make(chan struct{})
+	memo, _ := NewMemoized(OperationFunc("slow", 10, func(ctx CnvContext, in int) (int, error) {
+		calls.Add(1)
+		<-release
+		return in * in, nil
+	}), CacheConfig[int, int]{Key: modFive, MaxSize: 10})
+
+	ctx := newTestContext()
+	var wg sync.WaitGroup
+	results := make([]int, 10)
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = memo.Execute(ctx, 3)
+		}()
+	}
+	require.Eventually(t, func() bool { return memo.CacheStats().Coalesced == 9 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, slices.Repeat([]int{9}, 10), results)
+	assert.Equal(t, int64(1), calls.Load())
+}
+
+// TestMemoized_CoalescedErrors verifies that a failure is recorded once, not for every item coalesced with it.
+func TestMemoized_CoalescedErrors(t *testing.T) {
+	errFailed := errors.New("failed")
+	for _, panics := range []bool{false, true} {
+		release := make(chan struct{})
+		memo, _ := NewMemoized(OperationFunc("failing", 10, func(ctx CnvContext, in int) (int, error) {
+			<-release
+			if panics {
+				panic(errFailed)
+			}
+			return 0, errFailed
+		}), CacheConfig[int, int]{Key: identity, MaxSize: 10})
+
+		cnv, _ := NewConveyor("coalesced_errors", 10)
+		require.NoError(t, AddSource[int](cnv, SourceFromSeq("src", slices.Values(slices.Repeat([]int{3}, 10))), WorkerModeLoop))
+		require.NoError(t, AddOperation[int, int](cnv, memo, WorkerModeTransaction))
+		go func() {
+			assert.Eventually(t, func() bool { return memo.CacheStats().Coalesced == 9 }, time.Second, time.Millisecond)
+			close(release)
+		}()
+
+		results, err := Collect[int](context.Background(), cnv)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+		assert.Equal(t, int64(1), cnv.Errors().Total(), "panics: %v", panics)
+	}
+
+	memo, _ := NewMemoized(OperationFunc("failing", 1, func(ctx CnvContext, in int) (int, error) {
+		return 0, errFailed
+	}), CacheConfig[int, int]{Key: identity, MaxSize: 10})
+	call := &memoCall[int]{done: make(chan struct{})}
+	memo.inFlight[3] = call
+	go func() {
+		call.err = errFailed
+		memo.finish(3, call)
+	}()
+	_, err := memo.Execute(newTestContext(), 3)
+	assert.ErrorIs(t, err, ErrCoalesced)
+	assert.ErrorIs(t, err, errFailed, "a coalesced item still gets the error")
+}
+
+// TestMemoized_Evictions verifies that results are evicted once expired, or beyond MaxSize, and that errors aren't cached.
+func TestMemoized_Evictions(t *testing.T) {
+	var calls atomic.Int64
+	errOdd := errors.New("odd")
+	memo, _ := NewMemoized(OperationFunc("evens", 1, func(ctx CnvContext, in int) (int, error) {
+		calls.Add(1)
+		if in%2 == 1 {
+			return 0, errOdd
+		}
+		return in * in, nil
+	}), CacheConfig[int, int]{Key: identity, MaxSize: 2, TTL: 50 * time.Millisecond})
+	ctx := newTestContext()
+
+	for _, in := range []int{2, 4, 2, 6, 4} { // 6 evicts 4, the least recently used, then 4 evicts 2
+		_, _ = memo.Execute(ctx, in)
+	}
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 4, Evictions: 2}, memo.CacheStats())
+
+	for range 2 {
+		_, err := memo.Execute(ctx, 1)
+		assert.ErrorIs(t, err, errOdd)
+	}
+	assert.Equal(t, int64(6), calls.Load(), "errors aren't cached")
+
+	time.Sleep(60 * time.Millisecond)
+	out, err := memo.Execute(ctx, 6)
+	require.NoError(t, err)
+	assert.Equal(t, 36, out)
+	assert.Equal(t, int64(7), calls.Load(), "6 has expired")
+
+	_, err = NewMemoized(lookup(&calls), CacheConfig[int, int]{Key: identity})
+	assert.ErrorIs(t, err, ErrInvalidCache)
+}
@@ -0,0 +1,162 @@
+package conveyor
+
+import (
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Metadata travels with an item, from the source through every stage, without being part of its type
+type Metadata struct {
+	// ID identifies the item, the number of items the source produced before it, unless the source sets it
+	ID string
+	// CreatedAt is when the source produced the item
+	CreatedAt time.Time
+	// Headers are set by the source, e.g. trace headers, and must not be changed afterwards
+	Headers map[string]string
+}
+
+// Age returns how long ago the source produced the item, e.g. a sink's end-to-end latency
+func (md Metadata) Age() time.Duration {
+	return time.Since(md.CreatedAt)
+}
+
+// Described is implemented by sources that set the metadata of their items, e.g. to carry a correlation ID
+// or trace headers. Describe() is called once the source has produced item, with md's ID & CreatedAt already set.
+type Described[TOut any] interface {
+	Describe(item TOut, md *Metadata)
+}
+
+// EnableMetadata makes every item of the source carry its Metadata, through the operations & joints,
+// for executors to read with ItemMetadata(ctx). Sources may implement Described to set it.
+//
+// Operations in WorkerModeTransaction hand the metadata of their input over to their output, and ReplicateJoint
+// to every copy. Loop-mode operations & other joints run on their own, so what they write carries the metadata
+// of the last item they read, and a JoinJoint's results that of their left item. Loop-mode sinks still get the items,
+// but their context isn't any item's, so it has no metadata.
+// Will have no effect, once you add your first node
+func (cnv *Conveyor) EnableMetadata() *Conveyor {
+	if !cnv.openForConfigChange {
+		return cnv
+	}
+	cnv.metadata = true
+	return cnv
+}
+
+// ItemMetadata returns the metadata of the item being processed with ctx, once EnableMetadata() is set
+func ItemMetadata(ctx CnvContext) (Metadata, bool) {
+	for {
+		switch c := ctx.(type) {
+		case *itemContext:
+			if c.tok.meta == nil {
+				return Metadata{}, false
+			}
+			return *c.tok.meta, true
+		case *nestedContext:
+			ctx = c.CnvContext
+		default:
+			return Metadata{}, false
+		}
+	}
+}
+
+// describe sets the metadata of the n-th item of the source
+func (ts *trackedSource[TOut]) describe(tok *token, n uint64, out TOut) {
+	tok.meta = &Metadata{ID: strconv.FormatUint(n, 10), CreatedAt: time.Now()}
+	if ts.described != nil {
+		ts.described.Describe(out, tok.meta)
+	}
+}
+
+// carrying returns the token of an item derived from others, that only carries meta
+func (tr *itemTracker) carrying(meta *Metadata) *token {
+	tok := &token{tracker: tr, meta: meta}
+	tok.refs.Store(1)
+	return tok
+}
+
+// relayMetadata runs run, the ExecuteLoop() of an operation or a joint, with plain channels in place of inChans
+// & outChans. Each item it writes carries the metadata of the last item it read, none before the first one.
+// A single go-routine relays every channel, so that it sees the reads & writes in the order run made them.
+// Only used when items merely carry their metadata, as what run writes can't tell when the items it read are done.
+func relayMetadata[TIn, TOut any](ctx CnvContext, tracker *itemTracker, inChans []<-chan tracked[TIn], outChans []chan<- tracked[TOut],
+	run func(items []chan TIn, outs []chan TOut) error) error {
+	items := make([]chan TIn, len(inChans))
+	for i := range items {
+		items[i] = make(chan TIn)
+	}
+	outs := make([]chan TOut, len(outChans))
+	for i := range outs {
+		outs[i] = make(chan TOut)
+	}
+
+	stopped := make(chan struct{})
+	var relay sync.WaitGroup
+	relay.Add(1)
+	go func() {
+		defer relay.Done()
+		relayLatest(pipelineContext(ctx).Done(), stopped, tracker, inChans, outChans, items, outs)
+	}()
+
+	// Once run returns, the pool drains whatever is left of the inputs
+	err := run(items, outs)
+	close(stopped)
+	relay.Wait()
+	return err
+}
+
+// relayLatest hands the items of inChans over to items, and writes those of outs to outChans, until stopped
+func relayLatest[TIn, TOut any](done, stopped <-chan struct{}, tracker *itemTracker, inChans []<-chan tracked[TIn],
+	outChans []chan<- tracked[TOut], items []chan TIn, outs []chan TOut) {
+	pending := make([]*tracked[TIn], len(inChans)) // read from inChans[i], to be handed over to items[i]
+	closed := make([]bool, len(inChans))
+	var latest *Metadata
+
+	cases := make([]reflect.SelectCase, 0, 1+len(inChans)+len(outs))
+	of := make([]int, 0, len(inChans)+len(outs)) // what each case but the first one is: input i, or output -(i+1)
+	for {
+		cases = append(cases[:0], reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(stopped)})
+		of = of[:0]
+		for i := range inChans {
+			switch {
+			case pending[i] != nil:
+				cases = append(cases, reflect.SelectCase{Dir: reflect.SelectSend, Chan: reflect.ValueOf(items[i]),
+					Send: reflect.ValueOf(&pending[i].item).Elem()})
+			case !closed[i]:
+				cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(inChans[i])})
+			default:
+				continue
+			}
+			of = append(of, i)
+		}
+		for i := range outs {
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(outs[i])})
+			of = append(of, -(i + 1))
+		}
+
+		chosen, v, ok := reflect.Select(cases)
+		if chosen == 0 {
+			return
+		}
+		switch i := of[chosen-1]; {
+		case i < 0:
+			out, _ := v.Interface().(TOut)
+			// Once the conveyor is done, the next stage may not read anymore
+			select {
+			case <-done:
+			case outChans[-(i + 1)] <- tracked[TOut]{item: out, tok: tracker.carrying(latest)}:
+			}
+		case pending[i] != nil:
+			latest = pending[i].tok.meta
+			pending[i].tok.done()
+			pending[i] = nil
+		case !ok:
+			closed[i] = true
+			close(items[i])
+		default:
+			in := v.Interface().(tracked[TIn])
+			pending[i] = &in
+		}
+	}
+}
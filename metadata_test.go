@@ -0,0 +1,154 @@
+package conveyor
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// describedSource sets a correlation ID & a trace header on its items
+type describedSource struct {
+	SourceExecutor[int]
+}
+
+func (ds *describedSource) Describe(item int, md *Metadata) {
+	md.ID = "msg-" + strconv.Itoa(item)
+	md.Headers = map[string]string{"trace": "t" + strconv.Itoa(item)}
+}
+
+// TestMetadata_ThroughStages verifies that operations, nested ones included, and every branch of a joint
+// see the metadata set by the source.
+func TestMetadata_ThroughStages(t *testing.T) {
+	var mu sync.Mutex
+	seen := make(map[string][]Metadata)
+	record := func(stage string) func(ctx CnvContext, in int) error {
+		return func(ctx CnvContext, in int) error {
+			md, ok := ItemMetadata(ctx)
+			assert.True(t, ok)
+			mu.Lock()
+			seen[stage] = append(seen[stage], md)
+			mu.Unlock()
+			return nil
+		}
+	}
+	check := OperationFunc("check", 2, func(ctx CnvContext, in int) (int, error) {
+		md, ok := ItemMetadata(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, "msg-"+strconv.Itoa(in), md.ID)
+		return in * 10, nil
+	})
+
+	cnv, _ := NewConveyor("metadata", 10)
+	cnv.EnableMetadata()
+	require.NoError(t, AddSource[int](cnv, &describedSource{SourceFromSeq("src", upTo(20))}, WorkerModeLoop))
+	require.NoError(t, AddOperation[int, int](cnv, NewSubPipeline[int, int]("sub", 1, check), WorkerModeTransaction))
+	joint, _ := NewReplicateJoint[int]("replicate", 2)
+	require.NoError(t, AddJointAfterNode[int, int](cnv, joint))
+	require.NoError(t, AddSinkAfterJoint[int](cnv, SinkFunc("a", 1, record("a")), WorkerModeTransaction))
+	require.NoError(t, AddSinkAfterJoint[int](cnv, SinkFunc("b", 2, record("b")), WorkerModeTransaction))
+	require.NoError(t, startWithin(t, cnv, time.Second))
+
+	for _, stage := range []string{"a", "b"} {
+		require.Len(t, seen[stage], 20, stage)
+		for _, md := range seen[stage] {
+			n, _ := strconv.Atoi(md.ID[len("msg-"):])
+			assert.Equal(t, "t"+strconv.Itoa(n), md.Headers["trace"])
+			assert.False(t, md.CreatedAt.IsZero())
+			assert.Positive(t, md.Age())
+		}
+	}
+}
+
+// roundRobin is a joint writing its items to each of its outputs in turn
+type roundRobin struct {
+	ConcreteJointExecutor[int, int]
+}
+
+func (rr *roundRobin) OutputCount() int {
+	return 2
+}
+
+func (rr *roundRobin) ExecuteLoop(ctx CnvContext, inChans []chan int, outChans []chan int) error {
+	i := 0
+	for in := range inChans[0] {
+		outChans[i%len(outChans)] <- in
+		i++
+	}
+	return nil
+}
+
+// TestMetadata_LoopMode verifies that what loop-mode operations & joints write carries the metadata
+// of the last item they read.
+func TestMetadata_LoopMode(t *testing.T) {
+	var mu sync.Mutex
+	var ids []string
+	check := func(ctx CnvContext, in int) error {
+		md, ok := ItemMetadata(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, "msg-"+strconv.Itoa(in/10), md.ID)
+		mu.Lock()
+		ids = append(ids, md.ID)
+		mu.Unlock()
+		return nil
+	}
+
+	cnv, _ := NewConveyor("loop_metadata", 10)
+	cnv.EnableMetadata()
+	require.NoError(t, AddSource[int](cnv, &describedSource{SourceFromSeq("src", upTo(50))}, WorkerModeLoop))
+	require.NoError(t, AddOperation[int, int](cnv, OperationLoopFunc("times_ten", 2, func(ctx CnvContext, inChan <-chan int, outChan chan<- int) error {
+		for in := range inChan {
+			outChan <- in * 10
+		}
+		return nil
+	}), WorkerModeLoop))
+	require.NoError(t, AddJointAfterNode[int, int](cnv, &roundRobin{ConcreteJointExecutor[int, int]{Name: "rr"}}))
+	require.NoError(t, AddSinkAfterJoint[int](cnv, SinkFunc("a", 1, check), WorkerModeTransaction))
+	require.NoError(t, AddSinkAfterJoint[int](cnv, SinkFunc("b", 2, check), WorkerModeTransaction))
+	require.NoError(t, startWithin(t, cnv, time.Second))
+	assert.Len(t, ids, 50)
+
+	var sums []Metadata
+	cnv, _ = NewConveyor("window_metadata", 10)
+	cnv.EnableMetadata()
+	require.NoError(t, AddSource[int](cnv, &describedSource{SourceFromSeq("src", upTo(50))}, WorkerModeLoop))
+	require.NoError(t, AddWindow(cnv, "sum", WindowConfig[int]{Size: time.Hour}, func(ctx CnvContext, w Window, items []int) (int, error) {
+		return len(items), nil
+	}))
+	require.NoError(t, AddSink[int](cnv, SinkFunc("snk", 1, func(ctx CnvContext, in int) error {
+		md, _ := ItemMetadata(ctx)
+		sums = append(sums, md)
+		return nil
+	}), WorkerModeTransaction))
+	require.NoError(t, startWithin(t, cnv, time.Second))
+	require.NotEmpty(t, sums)
+	assert.Equal(t, "msg-49", sums[len(sums)-1].ID, "the last window is flushed once the last item is read")
+}
+
+// TestMetadata_DefaultID verifies that items are numbered in the order the source produced them, unless it sets
+// their ID, and that there's no metadata unless enabled.
+func TestMetadata_DefaultID(t *testing.T) {
+	var ids []string
+	cnv, _ := NewConveyor("default_ids", 10)
+	cnv.EnableMetadata()
+	require.NoError(t, AddSource[int](cnv, SourceFromSeq("src", upTo(3)), WorkerModeTransaction))
+	require.NoError(t, AddSink[int](cnv, SinkFunc("snk", 1, func(ctx CnvContext, in int) error {
+		md, _ := ItemMetadata(ctx)
+		ids = append(ids, md.ID)
+		return nil
+	}), WorkerModeTransaction))
+	require.NoError(t, startWithin(t, cnv, time.Second))
+	assert.Equal(t, []string{"0", "1", "2"}, ids)
+
+	cnv, _ = NewConveyor("no_metadata", 10)
+	require.NoError(t, AddSource[int](cnv, SourceFromSeq("src", upTo(3)), WorkerModeTransaction))
+	require.NoError(t, AddSink[int](cnv, SinkFunc("snk", 1, func(ctx CnvContext, in int) error {
+		_, ok := ItemMetadata(ctx)
+		assert.False(t, ok)
+		return nil
+	}), WorkerModeTransaction))
+	require.NoError(t, startWithin(t, cnv, time.Second))
+}
@@ -1,6 +1,7 @@
 package conveyor
 
 import (
+	"errors"
 	"fmt"
 	"log"
 
@@ -8,57 +9,113 @@ import (
 )
 
 // OperationWorkerPool struct provides the worker pool infra for Operation interface
-type OperationWorkerPool struct {
+type OperationWorkerPool[TIn, TOut any] struct {
 	*ConcreteNodeWorker
-	inputChannel  chan any
-	outputChannel chan any
+	exec          OperationExecutor[TIn, TOut]
+	inputChannel  chan TIn
+	outputChannel chan TOut
+	inputBatches  chan []TIn  // the input, once batching is enabled
+	outputBatches chan []TOut // the output, once batching is enabled
 }
 
 // OperationNode structue
-type OperationNode struct {
-	Pool *OperationWorkerPool
+type OperationNode[TIn, TOut any] struct {
+	Pool *OperationWorkerPool[TIn, TOut]
 }
 
 // NewOperationWorkerPool creates a new OperationWorkerPool
-func NewOperationWorkerPool(executor nodeExecutor, mode WorkerMode) NodeWorker {
+func NewOperationWorkerPool[TIn, TOut any](exec OperationExecutor[TIn, TOut], mode WorkerMode) NodeWorker {
 
-	cnw := newConcreteNodeWorker(executor, mode)
-	fwp := &OperationWorkerPool{ConcreteNodeWorker: cnw}
+	cnw := newConcreteNodeWorker(exec, mode)
+	fwp := &OperationWorkerPool[TIn, TOut]{ConcreteNodeWorker: cnw, exec: exec}
 
 	return fwp
 }
 
 // CreateChannels creates channels for the Operation WorkerPool
-func (fwp *OperationWorkerPool) CreateChannels(buffer int) {
-	fwp.inputChannel = make(chan any, buffer)
+func (fwp *OperationWorkerPool[TIn, TOut]) CreateChannels(buffer int) {
+	if fwp.batch != nil {
+		fwp.inputBatches = make(chan []TIn, buffer)
+		if fwp.edge.dropsItems() {
+			fwp.inlets = []any{overflowInlet(fwp.WPool, fwp.edge, fwp.inputBatches, dropChunk[TIn](fwp.edge))}
+		}
+		return
+	}
+	fwp.inputChannel = make(chan TIn, buffer)
+	if fwp.edge.dropsItems() {
+		fwp.inlets = []any{overflowInlet(fwp.WPool, fwp.edge, fwp.inputChannel, dropItem[TIn](fwp.edge))}
+	}
 }
 
-// GetInputChannel returns the input channel of Operation WorkerPool
-func (fwp *OperationWorkerPool) GetInputChannel() (chan any, error) {
+// GetInputChannel returns the input channel of Operation WorkerPool, a chan TIn, or a chan []TIn once batching is enabled
+func (fwp *OperationWorkerPool[TIn, TOut]) GetInputChannel() (any, error) {
+	if inlet, ok := fwp.inlet(0); ok {
+		return inlet, nil
+	}
+	if fwp.batch != nil {
+		return fwp.inputBatches, nil
+	}
 	return fwp.inputChannel, nil
 }
 
-// GetOutputChannel returns the output channel of Operation WorkerPool
-func (fwp *OperationWorkerPool) GetOutputChannel() (chan any, error) {
+// GetOutputChannel returns the output channel of Operation WorkerPool, a chan TOut, or a chan []TOut once batching is enabled
+func (fwp *OperationWorkerPool[TIn, TOut]) GetOutputChannel() (any, error) {
+	if fwp.batch != nil {
+		return fwp.outputBatches, nil
+	}
 	return fwp.outputChannel, nil
 }
 
-// SetInputChannel updates the input channel of Operation WorkerPool
-func (fwp *OperationWorkerPool) SetInputChannel(inChan chan any) error {
-	fwp.inputChannel = inChan
+// SetInputChannel updates the input channel of Operation WorkerPool, which must be a chan TIn,
+// or a chan []TIn once batching is enabled
+func (fwp *OperationWorkerPool[TIn, TOut]) SetInputChannel(inChan any) error {
+	fwp.clearInlets()
+	if fwp.batch != nil {
+		ch, err := channelOf[[]TIn](inChan)
+		if err != nil {
+			return err
+		}
+		fwp.inputBatches = ch
+		return nil
+	}
+
+	ch, err := channelOf[TIn](inChan)
+	if err != nil {
+		return err
+	}
+	fwp.inputChannel = ch
 	return nil
 }
 
-// SetOutputChannel updates the output channel of Operation WorkerPool
-func (fwp *OperationWorkerPool) SetOutputChannel(outChan chan any) error {
-	fwp.outputChannel = outChan
+// SetOutputChannel updates the output channel of Operation WorkerPool, which must be a chan TOut,
+// or a chan []TOut once batching is enabled
+func (fwp *OperationWorkerPool[TIn, TOut]) SetOutputChannel(outChan any) error {
+	if fwp.batch != nil {
+		ch, err := channelOf[[]TOut](outChan)
+		if err != nil {
+			return err
+		}
+		fwp.outputBatches = ch
+		return nil
+	}
+
+	ch, err := channelOf[TOut](outChan)
+	if err != nil {
+		return err
+	}
+	fwp.outputChannel = ch
 	return nil
 }
 
 // Start Operation Worker Pool
-func (fwp *OperationWorkerPool) Start(ctx CnvContext) error {
+func (fwp *OperationWorkerPool[TIn, TOut]) Start(ctx CnvContext) error {
+	fwp.startInlets(ctx)
+
 	switch fwp.Mode {
 	case WorkerModeTransaction:
+		if fwp.batch != nil {
+			return fwp.startBatchTransactionMode(ctx)
+		}
 		return fwp.startTransactionMode(ctx)
 	case WorkerModeLoop:
 		return fwp.startLoopMode(ctx)
@@ -68,14 +125,24 @@ func (fwp *OperationWorkerPool) Start(ctx CnvContext) error {
 }
 
 // startLoopMode OperationWorkerPool
-func (fwp *OperationWorkerPool) startLoopMode(ctx CnvContext) error {
+func (fwp *OperationWorkerPool[TIn, TOut]) startLoopMode(ctx CnvContext) error {
 
-	return fwp.ConcreteNodeWorker.startLoopMode(ctx, fwp.inputChannel, fwp.outputChannel)
+	if fwp.batch != nil {
+		// The executor keeps its channels of single items, relayed from & to the chunks
+		fwp.inputChannel = make(chan TIn, fwp.batch.size)
+		fwp.outputChannel = make(chan TOut, fwp.batch.size)
+		fwp.relay(func() { unchunkItems(fwp.inputBatches, fwp.inputChannel) })
+		fwp.relay(func() { chunkItems(fwp.batch, fwp.outputChannel, fwp.outputBatches, ctx.Done()) })
+	}
+
+	return fwp.ConcreteNodeWorker.startLoopMode(ctx, func() error {
+		return fwp.exec.ExecuteLoop(ctx, fwp.inputChannel, fwp.outputChannel)
+	})
 
 }
 
 // startTransactionMode starts OperationWorkerPool in transaction mode
-func (fwp *OperationWorkerPool) startTransactionMode(ctx CnvContext) error {
+func (fwp *OperationWorkerPool[TIn, TOut]) startTransactionMode(ctx CnvContext) error {
 
 	fwp.sem = semaphore.NewWeighted(int64(fwp.WorkerCount))
 
@@ -88,7 +155,13 @@ workerLoop:
 		default:
 		}
 
-		inData, ok := <-fwp.inputChannel
+		var inData TIn
+		var ok bool
+		select {
+		case <-ctx.Done():
+			break workerLoop
+		case inData, ok = <-fwp.inputChannel:
+		}
 		if !ok {
 			ctx.SendLog(0, fmt.Sprintf("Executor:[%s] Operation's input channel closed", fwp.Executor.GetUniqueIdentifier()), nil)
 			break workerLoop
@@ -99,20 +172,24 @@ workerLoop:
 			break workerLoop
 		}
 
-		go func(data any) {
+		fwp.spawn(func() {
 			defer fwp.recovery(ctx, "OperationWorkerPool")
 			defer fwp.sem.Release(1)
 
-			out, err := fwp.Executor.executeUntyped(ctx, data)
-			switch err {
-			case nil:
+			out, err := fwp.exec.Execute(ctx, inData)
+			switch {
+			case err == nil:
+				// Once ctx is done, the next node may not read anymore
 				select {
 				case <-ctx.Done():
-					return
-				default:
+				case fwp.outputChannel <- out:
 				}
-				fwp.outputChannel <- out
-			case ErrExecuteNotImplemented:
+			case errors.Is(err, ErrSkipItem):
+				// Dropped on purpose, not a failure
+				skipItem(ctx, inData)
+			case errors.Is(err, ErrCoalesced):
+				// Recorded once already, for the item it was coalesced with
+			case err == ErrExecuteNotImplemented:
 				ctx.SendLog(0, fmt.Sprintf("Executor:[%s]", fwp.Executor.GetUniqueIdentifier()), err)
 				log.Fatalf("Improper setup of Executor[%s], Execute() method is required", fwp.Executor.GetUniqueIdentifier())
 			default:
@@ -120,23 +197,113 @@ workerLoop:
 					fwp.Name, fwp.Executor.GetUniqueIdentifier()), err)
 				ctx.RecordError(fwp.Executor.GetName(), err)
 			}
-		}(inData)
+		})
 
 	}
 
 	return nil
 }
 
+// startBatchTransactionMode starts OperationWorkerPool in transaction mode, with a transaction per chunk
+func (fwp *OperationWorkerPool[TIn, TOut]) startBatchTransactionMode(ctx CnvContext) error {
+
+	fwp.sem = semaphore.NewWeighted(int64(fwp.WorkerCount))
+
+workerLoop:
+	for {
+
+		var chunk []TIn
+		var ok bool
+		select {
+		case <-ctx.Done():
+			break workerLoop
+		case chunk, ok = <-fwp.inputBatches:
+		}
+		if !ok {
+			ctx.SendLog(0, fmt.Sprintf("Executor:[%s] Operation's input channel closed", fwp.Executor.GetUniqueIdentifier()), nil)
+			break workerLoop
+		}
+
+		if err := fwp.sem.Acquire(ctx, 1); err != nil {
+			ctx.SendLog(0, fmt.Sprintf("Executor:[%s], sem acquire failed", fwp.Executor.GetUniqueIdentifier()), err)
+			break workerLoop
+		}
+
+		fwp.spawn(func() {
+			defer fwp.sem.Release(1)
+
+			outChunk := make([]TOut, 0, len(chunk))
+			for _, inData := range chunk {
+				if out, ok := fwp.executeOne(ctx, inData); ok {
+					outChunk = append(outChunk, out)
+				}
+			}
+			if len(outChunk) == 0 {
+				return
+			}
+
+			// Once ctx is done, the next node may not read anymore
+			select {
+			case <-ctx.Done():
+			case fwp.outputBatches <- outChunk:
+			}
+		})
+
+	}
+
+	return nil
+}
+
+// executeOne runs a single item of a chunk, so that a panic only loses that item
+func (fwp *OperationWorkerPool[TIn, TOut]) executeOne(ctx CnvContext, inData TIn) (out TOut, ok bool) {
+	defer fwp.recovery(ctx, "OperationWorkerPool")
+
+	out, err := fwp.exec.Execute(ctx, inData)
+	switch {
+	case err == nil:
+		return out, true
+	case errors.Is(err, ErrSkipItem):
+		// Dropped on purpose, not a failure
+		skipItem(ctx, inData)
+	case errors.Is(err, ErrCoalesced):
+		// Recorded once already, for the item it was coalesced with
+	case err == ErrExecuteNotImplemented:
+		ctx.SendLog(0, fmt.Sprintf("Executor:[%s]", fwp.Executor.GetUniqueIdentifier()), err)
+		log.Fatalf("Improper setup of Executor[%s], Execute() method is required", fwp.Executor.GetUniqueIdentifier())
+	default:
+		ctx.SendLog(2, fmt.Sprintf("Worker:[%s] for Executor:[%s] Execute() Call Failed.",
+			fwp.Name, fwp.Executor.GetUniqueIdentifier()), err)
+		ctx.RecordError(fwp.Executor.GetName(), err)
+	}
+	return out, false
+}
+
 // WorkerType returns the type of worker
-func (fwp *OperationWorkerPool) WorkerType() string {
+func (fwp *OperationWorkerPool[TIn, TOut]) WorkerType() string {
 	return WorkerTypeOperation
 }
 
 // WaitAndStop OperationWorkerPool
-func (fwp *OperationWorkerPool) WaitAndStop(ctx CnvContext) error {
+func (fwp *OperationWorkerPool[TIn, TOut]) WaitAndStop(ctx CnvContext) error {
 
 	_ = fwp.ConcreteNodeWorker.WaitAndStop(ctx)
 
-	close(fwp.outputChannel)
+	if fwp.batch == nil {
+		drain(fwp.inputChannel)
+		close(fwp.outputChannel)
+		fwp.waitRelays()
+		return nil
+	}
+
+	if fwp.Mode == WorkerModeLoop {
+		// The input relay closes the executor's input once it has been through every chunk
+		drain(fwp.inputChannel)
+		close(fwp.outputChannel)
+		fwp.waitRelays()
+		return nil
+	}
+	drain(fwp.inputBatches)
+	close(fwp.outputBatches)
+	fwp.waitRelays()
 	return nil
 }
@@ -0,0 +1,82 @@
+package conveyor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// outputSink forwards everything it receives to a channel, which is closed once the conveyor is done
+type outputSink[T any] struct {
+	ConcreteSinkExecutor[T]
+	out       chan T
+	closeOnce sync.Once
+}
+
+func (snk *outputSink[T]) ExecuteLoop(ctx CnvContext, inChan <-chan T) error {
+	for v := range inChan {
+		select {
+		case <-ctx.Done():
+			return nil
+		case snk.out <- v:
+		}
+	}
+	return nil
+}
+
+// CleanUp is called once the sink's worker pool is done, so nothing sends on the channel anymore
+func (snk *outputSink[T]) CleanUp() error {
+	snk.closeOnce.Do(func() { close(snk.out) })
+	return nil
+}
+
+// OutputChannel attaches a sink after the last node of cnv, and returns a channel with what it receives.
+// T must match the output type of the last node, the same way it must for AddSink().
+// ErrNoOutputNode is returned when there is no such node, e.g. the conveyor already ends with a sink or a joint.
+//
+// The channel is closed once the conveyor is done. Start() is left to the caller, who must keep reading
+// from the channel until it's closed, or stop the conveyor.
+func OutputChannel[T any](cnv *Conveyor) (<-chan T, error) {
+	if cnv.lastNodeOutType == nil {
+		return nil, ErrNoOutputNode
+	}
+
+	snk := &outputSink[T]{
+		ConcreteSinkExecutor: ConcreteSinkExecutor[T]{Name: fmt.Sprintf("%s_output", cnv.Name)},
+		out:                  make(chan T, cnv.bufferLen),
+	}
+	if err := AddSink[T](cnv, snk, WorkerModeLoop); err != nil {
+		return nil, err
+	}
+	return snk.out, nil
+}
+
+// Collect attaches a sink after the last node of cnv, runs the conveyor, and returns everything the sink received.
+// T is validated the same way as for OutputChannel().
+//
+// If ctx is done before the conveyor, the conveyor is stopped, and the results collected so far
+// are returned along with ctx.Err(). Otherwise, the error is the one returned by Start().
+func Collect[T any](ctx context.Context, cnv *Conveyor) ([]T, error) {
+	out, err := OutputChannel[T](cnv)
+	if err != nil {
+		return nil, err
+	}
+
+	startErr := make(chan error, 1)
+	go func() { startErr <- cnv.Start() }()
+
+	var results []T
+	for {
+		select {
+		case v, ok := <-out:
+			if !ok {
+				return results, <-startErr
+			}
+			results = append(results, v)
+		case <-ctx.Done():
+			cnv.Stop()
+			<-startErr
+			return results, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package conveyor
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func itoa(ctx CnvContext, in int) (string, error) { return strconv.Itoa(in), nil }
+
+func TestCollect(t *testing.T) {
+	cnv, err := NewConveyor("collect", 10)
+	require.NoError(t, err)
+	require.NoError(t, AddSource[int](cnv, SourceFromSeq("src", slices.Values([]int{1, 2, 3})), WorkerModeTransaction))
+	require.NoError(t, AddOperation[int, string](cnv, OperationFunc("itoa", 2, itoa), WorkerModeTransaction))
+
+	results, err := Collect[string](context.Background(), cnv)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"1", "2", "3"}, results)
+	assert.Equal(t, StateFinished, cnv.CurrentState())
+}
+
+func TestCollect_TypeMismatch(t *testing.T) {
+	cnv, _ := NewConveyor("collect_mismatch", 10)
+	require.NoError(t, AddSource[int](cnv, SourceFromSeq("src", slices.Values([]int{1})), WorkerModeTransaction))
+
+	_, err := Collect[string](context.Background(), cnv)
+	assert.ErrorIs(t, err, ErrTypeMismatch)
+}
+
+func TestCollect_ContextDone(t *testing.T) {
+	cnv, _ := NewConveyor("collect_endless", 10)
+	require.NoError(t, AddSource[int](cnv, SourceFromSeq("src", naturals), WorkerModeLoop))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	results, err := Collect[int](ctx, cnv)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotEmpty(t, results)
+	assert.Equal(t, StateKilled, cnv.CurrentState())
+}
+
+func TestOutputChannel(t *testing.T) {
+	cnv, _ := NewConveyor("output", 10)
+	require.NoError(t, AddSource[int](cnv, SourceFromSeq("src", slices.Values([]int{1, 2, 3})), WorkerModeLoop))
+
+	out, err := OutputChannel[int](cnv)
+	require.NoError(t, err)
+
+	go func() { _ = cnv.Start() }()
+	var got []int
+	for v := range out {
+		got = append(got, v)
+	}
+	assert.Equal(t, []int{1, 2, 3}, got)
+
+	// The conveyor now ends with a sink
+	_, err = OutputChannel[int](cnv)
+	assert.ErrorIs(t, err, ErrNoOutputNode)
+}
@@ -0,0 +1,172 @@
+package conveyor
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// throughputWindow is the number of progress ticks kept to estimate the recent
+// throughput that the ETA is derived from.
+const throughputWindow = 10
+
+// Sized is an optional interface that a SourceExecutor can implement to report
+// the total amount of work it is going to produce. The unit is up to the source:
+// it may be an item count, or a byte count when the items flowing into the
+// sinks implement Weighted.
+//
+// When the conveyor's source implements Sized, Progress() reports the share of
+// that total which has reached the sinks, instead of the wall-clock estimate
+// configured through EnableProgress(). Size is polled on every progress tick, so
+// a source may refine its estimate while it runs. A non-positive value makes the
+// conveyor fall back to the time-based estimate for that tick.
+type Sized interface {
+	Size() int64
+}
+
+// Weighted is an optional interface for items that reach a sink. When an item
+// implements it, the item counts as Weight() units of progress instead of one.
+// Use it together with a Sized source that reports bytes instead of items.
+type Weighted interface {
+	Weight() int64
+}
+
+// ProgressReport is a point-in-time view of a conveyor's progress.
+type ProgressReport struct {
+	// Percent is the completed share of the work, between 0 and 100.
+	Percent float64
+	// Done is the number of units that have reached the sinks. It is 0 when
+	// progress is time-based.
+	Done int64
+	// Total is the number of units reported by the Sized source. It is 0 when
+	// progress is time-based.
+	Total int64
+	// Elapsed is the time since the conveyor was started.
+	Elapsed time.Duration
+	// ETA is the estimated time remaining. For item-based progress, it is
+	// derived from the throughput over the last few progress ticks.
+	ETA time.Duration
+	// ItemBased reports whether Percent was computed from item counts (true)
+	// or from the expected duration given to EnableProgress (false).
+	ItemBased bool
+}
+
+// sinkCounter is implemented by sink worker pools that count the units
+// that reached them.
+type sinkCounter interface {
+	delivered() int64
+}
+
+// deliveryCounter counts the units of work that reached a sink.
+type deliveryCounter struct {
+	count atomic.Int64
+}
+
+// add records one item that reached the sink, weighted if it implements Weighted.
+func (dc *deliveryCounter) add(item any) {
+	dc.count.Add(weightOf(item))
+}
+
+// weightOf returns the progress units of item: its Weight() if it implements Weighted, 1 otherwise
+func weightOf(item any) int64 {
+	if w, ok := item.(Weighted); ok {
+		return w.Weight()
+	}
+	return 1
+}
+
+// units is a number of progress units, e.g. those of the items merged into a single one
+type units int64
+
+func (u units) Weight() int64 {
+	return int64(u)
+}
+
+func (dc *deliveryCounter) delivered() int64 {
+	return dc.count.Load()
+}
+
+// progressSample is one observation of the delivered count, used to compute throughput.
+type progressSample struct {
+	at   time.Time
+	done int64
+}
+
+// progressTracker computes ProgressReports for a conveyor. It is updated from the
+// progress goroutine and read from any goroutine.
+type progressTracker struct {
+	mu      sync.RWMutex
+	start   time.Time
+	samples []progressSample
+	last    ProgressReport
+}
+
+// delivered returns the number of units that reached every sink. With several
+// sink branches (e.g. behind a ReplicateJoint), an item only counts as done once
+// the slowest branch has processed it.
+func delivered(sinks []sinkCounter) int64 {
+	if len(sinks) == 0 {
+		return 0
+	}
+	lowest := sinks[0].delivered()
+	for _, s := range sinks[1:] {
+		if d := s.delivered(); d < lowest {
+			lowest = d
+		}
+	}
+	return lowest
+}
+
+// update records a new observation and returns the resulting report. total <= 0
+// means that item-based progress is unavailable and the expected duration is used.
+func (pt *progressTracker) update(now time.Time, done, total int64, expected time.Duration) ProgressReport {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	if pt.start.IsZero() {
+		pt.start = now
+	}
+	report := ProgressReport{Elapsed: now.Sub(pt.start)}
+
+	if total <= 0 {
+		report.Percent = (report.Elapsed.Seconds() / expected.Seconds()) * 100
+		// if estimate is incorrect, don't overflow progress end
+		if report.Percent > 100 {
+			report.Percent = 99.0
+		}
+		if remaining := expected - report.Elapsed; remaining > 0 {
+			report.ETA = remaining
+		}
+		pt.last = report
+		return report
+	}
+
+	pt.samples = append(pt.samples, progressSample{at: now, done: done})
+	if len(pt.samples) > throughputWindow {
+		pt.samples = pt.samples[len(pt.samples)-throughputWindow:]
+	}
+
+	report.ItemBased = true
+	report.Done = done
+	report.Total = total
+	report.Percent = float64(done) / float64(total) * 100
+	if report.Percent > 100 {
+		report.Percent = 100
+	}
+
+	oldest := pt.samples[0]
+	if window := now.Sub(oldest.at); window > 0 && done > oldest.done && done < total {
+		rate := float64(done-oldest.done) / window.Seconds()
+		report.ETA = time.Duration(float64(total-done) / rate * float64(time.Second))
+	}
+
+	pt.last = report
+	return report
+}
+
+// report returns the most recently computed ProgressReport.
+func (pt *progressTracker) report() ProgressReport {
+	pt.mu.RLock()
+	defer pt.mu.RUnlock()
+	return pt.last
+}
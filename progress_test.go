@@ -0,0 +1,218 @@
+package conveyor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sizedCountingSource is a countingSource that reports how many items it will emit.
+type sizedCountingSource struct {
+	countingSource
+}
+
+func (s *sizedCountingSource) Size() int64 { return int64(s.limit + 1) }
+
+// weightedItem counts as its byte length towards progress.
+type weightedItem []byte
+
+func (w weightedItem) Weight() int64 { return int64(len(w)) }
+
+// ---------------------------------------------------------------------------
+// progressTracker tests
+// ---------------------------------------------------------------------------
+
+// TestProgressTracker_TimeBasedFallback verifies that without a total the
+// percentage is derived from the expected duration, capped at 99.
+func TestProgressTracker_TimeBasedFallback(t *testing.T) {
+	var pt progressTracker
+	start := time.Now()
+	pt.update(start, 0, 0, 10*time.Second)
+
+	report := pt.update(start.Add(5*time.Second), 3, 0, 10*time.Second)
+	assert.False(t, report.ItemBased)
+	assert.InDelta(t, 50.0, report.Percent, 0.001)
+	assert.Equal(t, 5*time.Second, report.ETA)
+
+	report = pt.update(start.Add(20*time.Second), 3, 0, 10*time.Second)
+	assert.Equal(t, 99.0, report.Percent)
+	assert.Equal(t, time.Duration(0), report.ETA)
+}
+
+// TestProgressTracker_ItemBased verifies the item-based percentage and the ETA
+// computed from the throughput observed across samples.
+func TestProgressTracker_ItemBased(t *testing.T) {
+	var pt progressTracker
+	start := time.Now()
+	pt.update(start, 0, 0, time.Hour)
+
+	pt.update(start.Add(time.Second), 10, 100, time.Hour)
+	report := pt.update(start.Add(2*time.Second), 20, 100, time.Hour)
+
+	assert.True(t, report.ItemBased)
+	assert.Equal(t, int64(20), report.Done)
+	assert.Equal(t, int64(100), report.Total)
+	assert.InDelta(t, 20.0, report.Percent, 0.001)
+	// 10 items/s over the window, 80 items left.
+	assert.Equal(t, 8*time.Second, report.ETA)
+	assert.Equal(t, report, pt.report())
+}
+
+// TestProgressTracker_ThroughputWindow verifies that only recent samples are
+// used, so a change in throughput is reflected in the ETA.
+func TestProgressTracker_ThroughputWindow(t *testing.T) {
+	var pt progressTracker
+	start := time.Now()
+	done := int64(0)
+	// A slow phase of 1 item/s, longer than the window...
+	for i := 0; i <= throughputWindow*2; i++ {
+		pt.update(start.Add(time.Duration(i)*time.Second), done, 1000, time.Hour)
+		done++
+	}
+	// ...followed by a fast phase of 10 items/s.
+	var report ProgressReport
+	for i := 1; i <= throughputWindow; i++ {
+		done += 10
+		report = pt.update(start.Add(time.Duration(throughputWindow*2+i)*time.Second), done, 1000, time.Hour)
+	}
+	expected := time.Duration(float64(1000-done) / 10 * float64(time.Second))
+	assert.InDelta(t, float64(expected), float64(report.ETA), float64(time.Second))
+}
+
+// TestProgressTracker_CappedAtHundred verifies that over-delivery (e.g. an
+// underestimated size) never reports more than 100%.
+func TestProgressTracker_CappedAtHundred(t *testing.T) {
+	var pt progressTracker
+	report := pt.update(time.Now(), 150, 100, time.Hour)
+	assert.Equal(t, 100.0, report.Percent)
+	assert.Equal(t, time.Duration(0), report.ETA)
+}
+
+// TestDelivered_SlowestBranch verifies that with several sinks, only items
+// that reached all of them count as done.
+func TestDelivered_SlowestBranch(t *testing.T) {
+	a, b := &deliveryCounter{}, &deliveryCounter{}
+	for i := 0; i < 5; i++ {
+		a.add(i)
+	}
+	b.add(0)
+	b.add(weightedItem("abc"))
+
+	assert.Equal(t, int64(0), delivered(nil))
+	assert.Equal(t, int64(5), delivered([]sinkCounter{a}))
+	assert.Equal(t, int64(4), delivered([]sinkCounter{a, b}))
+}
+
+// ---------------------------------------------------------------------------
+// Conveyor integration tests
+// ---------------------------------------------------------------------------
+
+// TestConveyor_ItemBasedProgress runs a pipeline with a Sized source and checks
+// that the final report accounts for every item that reached the sink.
+func TestConveyor_ItemBasedProgress(t *testing.T) {
+	cnv, _ := NewConveyor("progress", 10)
+	cnv.EnableProgress(time.Hour)
+	cnv.tickProgress = time.Millisecond
+
+	src := &sizedCountingSource{countingSource{
+		ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"},
+		limit:                  99,
+	}}
+	require.NoError(t, AddSource[int](cnv, src, WorkerModeTransaction))
+
+	snk := &slowSink{delay: 100 * time.Microsecond}
+	snk.Name = "snk"
+	require.NoError(t, AddSink[int](cnv, snk, WorkerModeTransaction))
+
+	var last float64
+	progressDone := make(chan struct{})
+	go func() {
+		defer close(progressDone)
+		for p := range cnv.Progress() {
+			assert.GreaterOrEqual(t, p, last, "item-based progress must not go backwards")
+			last = p
+		}
+	}()
+
+	require.NoError(t, cnv.Start())
+	<-progressDone
+
+	assert.Equal(t, int64(100), delivered(cnv.sinks))
+	report := cnv.ProgressReport()
+	assert.True(t, report.ItemBased)
+	assert.Equal(t, int64(100), report.Total)
+}
+
+// TestConveyor_FinalProgress verifies that a last report is sent once the conveyor is done,
+// even if no tick came after the last item.
+func TestConveyor_FinalProgress(t *testing.T) {
+	cnv, _ := NewConveyor("progress_final", 10)
+	cnv.EnableProgress(time.Hour)
+	cnv.tickProgress = time.Hour
+
+	src := &sizedCountingSource{countingSource{
+		ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"},
+		limit:                  49,
+	}}
+	require.NoError(t, AddSource[int](cnv, src, WorkerModeTransaction))
+	require.NoError(t, AddSink[int](cnv, SinkFunc("snk", 2, func(ctx CnvContext, in int) error { return nil }), WorkerModeTransaction))
+
+	var reports []float64
+	progressDone := make(chan struct{})
+	go func() {
+		defer close(progressDone)
+		for p := range cnv.Progress() {
+			reports = append(reports, p)
+		}
+	}()
+
+	require.NoError(t, cnv.Start())
+	<-progressDone
+
+	assert.Equal(t, []float64{100}, reports)
+	report := cnv.ProgressReport()
+	assert.Equal(t, int64(50), report.Done)
+	assert.Equal(t, time.Duration(0), report.ETA)
+}
+
+// TestConveyor_ProgressFallsBackToDuration verifies that a source without Sized
+// keeps the time-based estimate.
+func TestConveyor_ProgressFallsBackToDuration(t *testing.T) {
+	cnv, _ := NewConveyor("progress_time", 10)
+	cnv.EnableProgress(time.Hour)
+	cnv.tickProgress = time.Millisecond
+
+	src := &countingSource{
+		ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"},
+		limit:                  20,
+	}
+	require.NoError(t, AddSource[int](cnv, src, WorkerModeTransaction))
+
+	snk := &slowSink{delay: time.Millisecond}
+	snk.Name = "snk"
+	require.NoError(t, AddSink[int](cnv, snk, WorkerModeTransaction))
+
+	go func() {
+		for range cnv.Progress() {
+		}
+	}()
+	require.NoError(t, cnv.Start())
+
+	report := cnv.ProgressReport()
+	assert.False(t, report.ItemBased)
+	assert.Less(t, report.Percent, 1.0)
+}
+
+// slowSink sleeps for delay on every item, so that progress ticks observe the
+// pipeline while it runs.
+type slowSink struct {
+	ConcreteSinkExecutor[int]
+	delay time.Duration
+}
+
+func (s *slowSink) Execute(ctx CnvContext, in int) error {
+	time.Sleep(s.delay)
+	return nil
+}
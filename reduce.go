@@ -0,0 +1,134 @@
+package conveyor
+
+import "sync"
+
+// Reducer sets how a ReduceSink folds its items into one accumulator per key
+type Reducer[T any, K comparable, A any] struct {
+	// Key returns the key of an item
+	Key func(item T) K
+	// Init returns the accumulator of a key, before its first item
+	Init func() A
+	// Add folds an item into the accumulator of its key, and returns the result
+	Add func(acc A, item T) A
+	// Merge combines two accumulators of the same key, and returns the result. It's optional, but once set,
+	// each worker folds its items into accumulators of its own, without any lock, and merges them at the end
+	Merge func(a, b A) A
+}
+
+// ReduceSink is a SinkExecutor folding its items into one accumulator per key, across all its workers.
+// Result() returns them once the conveyor is done.
+type ReduceSink[T any, K comparable, A any] struct {
+	ConcreteSinkExecutor[T]
+	concurrency int
+	reducer     Reducer[T, K, A]
+	accs        sync.Map // K -> *reduceAcc[A]
+}
+
+// reduceAcc is the accumulator of a key, with a lock of its own
+type reduceAcc[A any] struct {
+	mu  sync.Mutex
+	acc A
+}
+
+// NewReduceSink returns a ReduceSink running concurrency workers, in either mode
+func NewReduceSink[T any, K comparable, A any](name string, concurrency int, reducer Reducer[T, K, A]) (*ReduceSink[T, K, A], error) {
+	if reducer.Key == nil || reducer.Init == nil || reducer.Add == nil {
+		return nil, ErrInvalidReducer
+	}
+	return &ReduceSink[T, K, A]{
+		ConcreteSinkExecutor: ConcreteSinkExecutor[T]{Name: name},
+		concurrency:          workerCount(concurrency),
+		reducer:              reducer,
+	}, nil
+}
+
+// AddReduce adds a ReduceSink after the last node, in WorkerModeLoop, and returns it,
+// so that its Result() can be read once Start() has returned
+func AddReduce[T any, K comparable, A any](cnv *Conveyor, name string, concurrency int,
+	reducer Reducer[T, K, A]) (*ReduceSink[T, K, A], error) {
+	rs, err := NewReduceSink(name, concurrency, reducer)
+	if err != nil {
+		return nil, err
+	}
+	if err := AddSink[T](cnv, rs, WorkerModeLoop); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// Result returns the accumulator of every key.
+// It's final once the conveyor is done, and a snapshot while it's running.
+func (rs *ReduceSink[T, K, A]) Result() map[K]A {
+	result := make(map[K]A)
+	rs.accs.Range(func(key, value any) bool {
+		ra := value.(*reduceAcc[A])
+		ra.mu.Lock()
+		result[key.(K)] = ra.acc
+		ra.mu.Unlock()
+		return true
+	})
+	return result
+}
+
+func (rs *ReduceSink[T, K, A]) Count() int {
+	return rs.concurrency
+}
+
+func (rs *ReduceSink[T, K, A]) Execute(ctx CnvContext, inData T) error {
+	rs.update(rs.reducer.Key(inData), func(acc A) A {
+		return rs.reducer.Add(acc, inData)
+	})
+	return nil
+}
+
+func (rs *ReduceSink[T, K, A]) ExecuteLoop(ctx CnvContext, inChan <-chan T) error {
+	if rs.reducer.Merge == nil {
+		for in := range inChan {
+			_ = rs.Execute(ctx, in)
+		}
+		return nil
+	}
+
+	// The worker's own accumulators are merged even if Add() panics, so that no key is lost
+	partial := make(map[K]A)
+	defer func() {
+		for key, acc := range partial {
+			rs.merge(key, acc)
+		}
+	}()
+
+	for in := range inChan {
+		key := rs.reducer.Key(in)
+		acc, ok := partial[key]
+		if !ok {
+			acc = rs.reducer.Init()
+		}
+		partial[key] = rs.reducer.Add(acc, in)
+	}
+	return nil
+}
+
+// merge merges a worker's accumulator of key into the shared one, or stores it if key has none yet,
+// so that Init() is only applied once per key
+func (rs *ReduceSink[T, K, A]) merge(key K, acc A) {
+	value, loaded := rs.accs.LoadOrStore(key, &reduceAcc[A]{acc: acc})
+	if !loaded {
+		return
+	}
+	ra := value.(*reduceAcc[A])
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	ra.acc = rs.reducer.Merge(ra.acc, acc)
+}
+
+// update replaces the accumulator of key with fn's result, holding the key's lock only
+func (rs *ReduceSink[T, K, A]) update(key K, fn func(acc A) A) {
+	value, ok := rs.accs.Load(key)
+	if !ok {
+		value, _ = rs.accs.LoadOrStore(key, &reduceAcc[A]{acc: rs.reducer.Init()})
+	}
+	ra := value.(*reduceAcc[A])
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	ra.acc = fn(ra.acc)
+}
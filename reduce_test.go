@@ -0,0 +1,84 @@
+package conveyor
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wordCount counts words, with or without merging the accumulators of each worker
+func wordCount(merge bool) Reducer[string, string, int] {
+	r := Reducer[string, string, int]{
+		Key:  func(word string) string { return word },
+		Init: func() int { return 0 },
+		Add:  func(acc int, word string) int { return acc + 1 },
+	}
+	if merge {
+		r.Merge = func(a, b int) int { return a + b }
+	}
+	return r
+}
+
+// TestReduce_WordCount verifies that concurrent workers count every word, whether they share accumulators or merge them.
+func TestReduce_WordCount(t *testing.T) {
+	words := strings.Fields(strings.Repeat("the quick brown fox jumps over the lazy dog ", 100))
+
+	for _, merge := range []bool{false, true} {
+		cnv, _ := NewConveyor("word_count", 10)
+		require.NoError(t, AddSource[string](cnv, SourceFromSeq("src", slices.Values(words)), WorkerModeLoop))
+		counts, err := AddReduce(cnv, "count", 4, wordCount(merge))
+		require.NoError(t, err)
+		require.NoError(t, startWithin(t, cnv, time.Second))
+
+		assert.Equal(t, map[string]int{
+			"the": 200, "quick": 100, "brown": 100, "fox": 100, "jumps": 100, "over": 100, "lazy": 100, "dog": 100,
+		}, counts.Result(), "merge: %v", merge)
+	}
+}
+
+// TestReduce_MergeInit verifies that merging the accumulators of a worker doesn't apply Init() twice to a key.
+func TestReduce_MergeInit(t *testing.T) {
+	cnv, _ := NewConveyor("smoothed_count", 10)
+	require.NoError(t, AddSource[string](cnv, SourceFromSeq("src", slices.Values([]string{"a", "b", "a"})), WorkerModeLoop))
+	counts, err := AddReduce(cnv, "count", 1, Reducer[string, string, int]{
+		Key:   func(word string) string { return word },
+		Init:  func() int { return 1 }, // Add-one smoothing
+		Add:   func(acc int, word string) int { return acc + 1 },
+		Merge: func(a, b int) int { return a + b },
+	})
+	require.NoError(t, err)
+	require.NoError(t, startWithin(t, cnv, time.Second))
+	assert.Equal(t, map[string]int{"a": 3, "b": 2}, counts.Result())
+}
+
+// TestReduce_AfterJoint verifies that a ReduceSink runs in transaction mode too, on a branch of a joint.
+func TestReduce_AfterJoint(t *testing.T) {
+	parity, err := NewReduceSink("parity", 3, Reducer[int, bool, int]{
+		Key:  func(n int) bool { return n%2 == 0 },
+		Init: func() int { return 0 },
+		Add:  func(sum int, n int) int { return sum + n },
+	})
+	require.NoError(t, err)
+
+	cnv, _ := NewConveyor("sums", 10)
+	require.NoError(t, AddSource[int](cnv, SourceFromSeq("src", upTo(100)), WorkerModeLoop))
+	joint, _ := NewReplicateJoint[int]("replicate", 2)
+	require.NoError(t, AddJointAfterNode[int, int](cnv, joint))
+	require.NoError(t, AddSinkAfterJoint[int](cnv, parity, WorkerModeTransaction))
+	require.NoError(t, AddSinkAfterJoint[int](cnv, SinkFunc("discard", 1, func(ctx CnvContext, in int) error {
+		return nil
+	}), WorkerModeTransaction))
+	require.NoError(t, startWithin(t, cnv, time.Second))
+
+	assert.Equal(t, map[bool]int{true: 2450, false: 2500}, parity.Result())
+}
+
+// TestReduce_Invalid verifies that Key, Init & Add are required.
+func TestReduce_Invalid(t *testing.T) {
+	_, err := NewReduceSink("count", 1, Reducer[string, string, int]{Key: func(word string) string { return word }})
+	assert.ErrorIs(t, err, ErrInvalidReducer)
+}
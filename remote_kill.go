@@ -0,0 +1,72 @@
+package conveyor
+
+import (
+	"log"
+	"time"
+)
+
+// StopMode decides how a conveyor is stopped, when it's asked to stop from outside
+type StopMode uint8
+
+const (
+	// StopModeKill stops the conveyor right away, like Stop() does. Items in the pipeline are dropped.
+	StopModeKill = StopMode(iota + 1)
+
+	// StopModeDrain stops the sources, and lets the items already in the pipeline reach the sinks, like Drain() does.
+	StopModeDrain
+)
+
+// EnableRemoteKill makes the conveyor poll its LifeCycleHandler's GetState() every interval, while it runs.
+// Once another process marks the conveyor as StateToKill, it's stopped according to mode,
+// and marked as StateKilled. Has no effect without a LifeCycleHandler, or with a non-positive interval.
+// Will have no effect, once you add your first node
+func (cnv *Conveyor) EnableRemoteKill(interval time.Duration, mode StopMode) *Conveyor {
+	if !cnv.openForConfigChange {
+		return cnv
+	}
+	if mode != StopModeDrain {
+		mode = StopModeKill
+	}
+	cnv.remoteKillInterval = interval
+	cnv.remoteKillMode = mode
+	return cnv
+}
+
+// watchRemoteKill polls the LifeCycleHandler for StateToKill, until the conveyor is done,
+// or has been asked to stop. It closes "done" before returning.
+func (cnv *Conveyor) watchRemoteKill(done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(cnv.remoteKillInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-cnv.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		state, err := cnv.lifeCycle.GetState()
+		if err != nil {
+			log.Printf("Conveyor:[%s] unable to get state: Error:[%v]\n", cnv.Name, err)
+			continue
+		}
+		if state != StateToKill {
+			continue
+		}
+
+		// Already on its way out, because of this conveyor's own Stop() or Drain()
+		if cnv.stopped.Load() || cnv.draining.Load() {
+			return
+		}
+
+		cnv.ctx.SendLog(1, "Conveyor marked as 'toKill' remotely, stopping it", nil)
+		if cnv.remoteKillMode == StopModeDrain {
+			cnv.Drain()
+		} else {
+			cnv.Stop()
+		}
+		return
+	}
+}
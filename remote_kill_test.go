@@ -0,0 +1,133 @@
+package conveyor
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tickingSource emits increasing integers until its context is done.
+type tickingSource struct {
+	ConcreteSourceExecutor[int]
+	emitted atomic.Int64
+}
+
+func (s *tickingSource) ExecuteLoop(ctx CnvContext, out chan<- int) error {
+	for i := 0; ; i++ {
+		select {
+		case <-ctx.Done():
+			return nil
+		case out <- i:
+			s.emitted.Add(1)
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+// startInBackground runs cnv.Start() in a goroutine, and returns a channel closed once it returns.
+func startInBackground(t *testing.T, cnv *Conveyor) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, cnv.Start())
+	}()
+	return done
+}
+
+// waitForStart waits until the conveyor has been marked as started.
+func waitForStart(t *testing.T, cnv *Conveyor) {
+	require.Eventually(t, func() bool { return cnv.CurrentState() == StateStarted }, time.Second, time.Millisecond)
+}
+
+// TestRemoteKill_Kill verifies that marking the handler as toKill from outside stops the conveyor.
+func TestRemoteKill_Kill(t *testing.T) {
+	lch := &recordingLifeCycle{}
+	cnv, _ := NewConveyor("kill", 10)
+	cnv.SetLifeCycleHandler(lch).EnableRemoteKill(time.Millisecond, StopModeKill)
+	require.NoError(t, AddSource[int](cnv, &blockingSource{ConcreteSourceExecutor[int]{Name: "src"}}, WorkerModeLoop))
+	require.NoError(t, AddSink[int](cnv, &loopCollectingSink{ConcreteSinkExecutor: ConcreteSinkExecutor[int]{Name: "snk"}}, WorkerModeLoop))
+
+	done := startInBackground(t, cnv)
+	waitForStart(t, cnv)
+
+	// Another server marks the conveyor as toKill.
+	require.NoError(t, lch.MarkToKill())
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("conveyor wasn't stopped after being marked as toKill")
+	}
+	assert.True(t, cnv.stopped.Load())
+	assert.Equal(t, StateKilled, cnv.CurrentState())
+	state, _ := lch.GetState()
+	assert.Equal(t, StateKilled, state)
+}
+
+// TestRemoteKill_Drain verifies that in drain mode, every item emitted by the source reaches the sink.
+func TestRemoteKill_Drain(t *testing.T) {
+	lch := &recordingLifeCycle{}
+	cnv, _ := NewConveyor("drain", 10)
+	cnv.SetLifeCycleHandler(lch).EnableRemoteKill(time.Millisecond, StopModeDrain)
+
+	src := &tickingSource{ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"}}
+	require.NoError(t, AddSource[int](cnv, src, WorkerModeLoop))
+	require.NoError(t, AddOperation[int, int](cnv, &doublingOp{ConcreteOperationExecutor: ConcreteOperationExecutor[int, int]{Name: "op"}}, WorkerModeTransaction))
+	snk := &loopCollectingSink{ConcreteSinkExecutor: ConcreteSinkExecutor[int]{Name: "snk"}}
+	require.NoError(t, AddSink[int](cnv, snk, WorkerModeLoop))
+
+	done := startInBackground(t, cnv)
+	waitForStart(t, cnv)
+	require.Eventually(t, func() bool { return src.emitted.Load() >= 5 }, time.Second, time.Millisecond)
+
+	require.NoError(t, lch.MarkToKill())
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("conveyor wasn't drained after being marked as toKill")
+	}
+
+	assert.False(t, cnv.stopped.Load())
+	assert.True(t, cnv.draining.Load())
+	snk.mu.Lock()
+	assert.Equal(t, int(src.emitted.Load()), len(snk.collected), "every emitted item must reach the sink")
+	snk.mu.Unlock()
+	assert.Equal(t, []string{StatusPreparing, StateStarted, StateToKill, StateToKill, StateKilled}, lch.recordedStates())
+}
+
+// TestRemoteKill_Disabled verifies that GetState isn't acted upon unless remote kill is enabled.
+func TestRemoteKill_Disabled(t *testing.T) {
+	lch := &recordingLifeCycle{}
+	cnv := newBlockingConveyor(t, lch)
+
+	done := startInBackground(t, cnv)
+	waitForStart(t, cnv)
+	require.NoError(t, lch.MarkToKill())
+
+	select {
+	case <-done:
+		t.Fatal("conveyor must not watch for toKill unless EnableRemoteKill() is called")
+	case <-time.After(20 * time.Millisecond):
+	}
+	cnv.Stop()
+	<-done
+}
+
+// TestDrain_BeforeStart verifies that a conveyor drained before starting finishes right away.
+func TestDrain_BeforeStart(t *testing.T) {
+	cnv, _ := NewConveyor("drain_early", 10)
+	src := &tickingSource{ConcreteSourceExecutor: ConcreteSourceExecutor[int]{Name: "src"}}
+	require.NoError(t, AddSource[int](cnv, src, WorkerModeLoop))
+	require.NoError(t, AddSink[int](cnv, &loopCollectingSink{ConcreteSinkExecutor: ConcreteSinkExecutor[int]{Name: "snk"}}, WorkerModeLoop))
+
+	cnv.Drain()
+	select {
+	case <-startInBackground(t, cnv):
+	case <-time.After(time.Second):
+		t.Fatal("drained conveyor didn't finish")
+	}
+}
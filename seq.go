@@ -0,0 +1,118 @@
+package conveyor
+
+import (
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+// seqSource is a SourceExecutor reading from an iterator. It runs on a single goroutine,
+// as iterators aren't meant to be consumed concurrently.
+type seqSource[T any] struct {
+	ConcreteSourceExecutor[T]
+	seq iter.Seq2[T, error]
+
+	mu   sync.Mutex // guards next & stop, for Execute()
+	next func() (T, error, bool)
+	stop func()
+}
+
+// SourceFromSeq returns a SourceExecutor producing the values of seq, in either worker mode
+func SourceFromSeq[T any](name string, seq iter.Seq[T]) SourceExecutor[T] {
+	return SourceFromSeq2(name, func(yield func(T, error) bool) {
+		for v := range seq {
+			if !yield(v, nil) {
+				return
+			}
+		}
+	})
+}
+
+// SourceFromSeq2 returns a SourceExecutor producing the values of seq, in either worker mode.
+// Values paired with a non-nil error are skipped, and the error is recorded in the conveyor's ErrorStats.
+func SourceFromSeq2[T any](name string, seq iter.Seq2[T, error]) SourceExecutor[T] {
+	return &seqSource[T]{
+		ConcreteSourceExecutor: ConcreteSourceExecutor[T]{Name: name},
+		seq:                    seq,
+	}
+}
+
+func (ss *seqSource[T]) Count() int {
+	return 1
+}
+
+// Execute pulls the next value from the iterator
+func (ss *seqSource[T]) Execute(ctx CnvContext) (T, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if ss.next == nil {
+		ss.next, ss.stop = iter.Pull2(ss.seq)
+	}
+	v, err, ok := ss.next()
+	if !ok {
+		var zero T
+		return zero, ErrSourceExhausted
+	}
+	return v, err
+}
+
+// ExecuteLoop ranges over the iterator, and stops it once ctx is done
+func (ss *seqSource[T]) ExecuteLoop(ctx CnvContext, outChan chan<- T) error {
+	for v, err := range ss.seq {
+		if err != nil {
+			ctx.RecordError(ss.Name, err)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case outChan <- v:
+		}
+	}
+	return nil
+}
+
+// CleanUp stops the iterator, if Execute() didn't consume it entirely
+func (ss *seqSource[T]) CleanUp() error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if ss.stop != nil {
+		ss.stop()
+	}
+	return nil
+}
+
+// Results attaches a sink after the last node of cnv, and returns an iterator over what it receives.
+// T must match the output type of the last node, the same way it must for AddSink().
+//
+// The conveyor is started by ranging over the iterator, and Start() has returned by the time the loop is over.
+// Breaking out of the loop early stops the conveyor. The iterator can only be used once.
+func Results[T any](cnv *Conveyor) (iter.Seq[T], error) {
+	out, err := OutputChannel[T](cnv)
+	if err != nil {
+		return nil, err
+	}
+
+	var used atomic.Bool
+	return func(yield func(T) bool) {
+		if !used.CompareAndSwap(false, true) {
+			return
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_ = cnv.Start()
+		}()
+		defer func() { <-done }()
+
+		for v := range out {
+			if !yield(v) {
+				cnv.Stop()
+				return
+			}
+		}
+	}, nil
+}
@@ -0,0 +1,128 @@
+package conveyor
+
+import (
+	"errors"
+	"iter"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errBadValue = errors.New("bad value")
+
+// withErrors pairs every value of seq with errBadValue when it's a multiple of 5
+func withErrors(seq iter.Seq[int]) iter.Seq2[int, error] {
+	return func(yield func(int, error) bool) {
+		for v := range seq {
+			var err error
+			if v%5 == 0 {
+				err = errBadValue
+			}
+			if !yield(v, err) {
+				return
+			}
+		}
+	}
+}
+
+// naturals yields 1, 2, 3... until the consumer stops
+func naturals(yield func(int) bool) {
+	for i := 1; yield(i); i++ {
+	}
+}
+
+func TestSourceFromSeq_BothModes(t *testing.T) {
+	for _, mode := range []WorkerMode{WorkerModeTransaction, WorkerModeLoop} {
+		cnv, err := NewConveyor("from_seq", 10)
+		require.NoError(t, err)
+		require.NoError(t, AddSource[int](cnv, SourceFromSeq("src", slices.Values([]int{1, 2, 3, 4})), mode))
+
+		results, err := Results[int](cnv)
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3, 4}, slices.Collect(results), "mode %v", mode)
+		assert.Equal(t, StateFinished, cnv.CurrentState())
+	}
+}
+
+func TestSourceFromSeq2_RecordsErrors(t *testing.T) {
+	for _, mode := range []WorkerMode{WorkerModeTransaction, WorkerModeLoop} {
+		cnv, err := NewConveyor("from_seq2", 10)
+		require.NoError(t, err)
+		src := SourceFromSeq2("src", withErrors(slices.Values([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})))
+		require.NoError(t, AddSource[int](cnv, src, mode))
+		require.NoError(t, AddOperation[int, int](cnv, OperationFunc("double", 1, func(ctx CnvContext, in int) (int, error) {
+			return 2 * in, nil
+		}), WorkerModeTransaction))
+
+		results, err := Results[int](cnv)
+		require.NoError(t, err)
+		assert.Equal(t, []int{2, 4, 6, 8, 12, 14, 16, 18}, slices.Collect(results), "mode %v", mode)
+		assert.Equal(t, int64(2), cnv.Errors().Count("src", errBadValue), "mode %v", mode)
+	}
+}
+
+// TestResults_Break verifies that breaking out of the loop stops an endless conveyor, and leaves nothing running.
+func TestResults_Break(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+
+	for _, mode := range []WorkerMode{WorkerModeTransaction, WorkerModeLoop} {
+		cnv, err := NewConveyor("endless", 10)
+		require.NoError(t, err)
+		require.NoError(t, AddSource[int](cnv, SourceFromSeq("src", naturals), mode))
+
+		results, err := Results[int](cnv)
+		require.NoError(t, err)
+
+		var got []int
+		for v := range results {
+			got = append(got, v)
+			if len(got) == 5 {
+				break
+			}
+		}
+		assert.Len(t, got, 5)
+		assert.Equal(t, StateKilled, cnv.CurrentState())
+
+		// Used up
+		assert.Empty(t, slices.Collect(results))
+	}
+	expectNoGoroutinesAbove(t, baseline)
+}
+
+func TestResults_Validation(t *testing.T) {
+	cnv, _ := NewConveyor("empty", 10)
+	_, err := Results[int](cnv)
+	assert.ErrorIs(t, err, ErrNoOutputNode)
+
+	cnv, _ = NewConveyor("typed", 10)
+	require.NoError(t, AddSource[int](cnv, SourceFromSeq("src", slices.Values([]int{1})), WorkerModeLoop))
+	_, err = Results[string](cnv)
+	assert.ErrorIs(t, err, ErrTypeMismatch)
+
+	require.NoError(t, AddSink[int](cnv, SinkFunc("snk", 1, func(ctx CnvContext, in int) error { return nil }), WorkerModeLoop))
+	_, err = Results[int](cnv)
+	assert.ErrorIs(t, err, ErrNoOutputNode)
+}
+
+func TestSourceFromSeq_CleanUpStopsIterator(t *testing.T) {
+	stopped := make(chan struct{})
+	src := SourceFromSeq[int]("src", func(yield func(int) bool) {
+		defer close(stopped)
+		naturals(yield)
+	})
+
+	v, err := src.Execute(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+	require.NoError(t, src.CleanUp())
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("iterator wasn't stopped")
+	}
+}
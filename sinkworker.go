@@ -3,34 +3,77 @@ package conveyor
 import (
 	"fmt"
 	"log"
+	"reflect"
 
 	"golang.org/x/sync/semaphore"
 )
 
 // SinkWorkerPool struct provides the worker pool infra for Sink interface
-type SinkWorkerPool struct {
+type SinkWorkerPool[TIn any] struct {
 	*ConcreteNodeWorker
-	inputChannel chan any
+	deliveryCounter
+	exec         SinkExecutor[TIn]
+	inputChannel chan TIn
+	inputBatches chan []TIn // the input, once batching is enabled
+
+	// trackDelivery makes a loop-mode sink count the items it receives, for item-based progress.
+	// It takes an extra hop between the input channel and the executor, so it's only set when progress is enabled.
+	trackDelivery bool
+	// weighted is set when items may implement Weighted, and have to be checked one by one
+	weighted bool
 }
 
 // NewSinkWorkerPool creates a new SinkWorkerPool
-func NewSinkWorkerPool(executor nodeExecutor, mode WorkerMode) NodeWorker {
+func NewSinkWorkerPool[TIn any](exec SinkExecutor[TIn], mode WorkerMode) NodeWorker {
+	return newSinkWorkerPool(exec, mode)
+}
 
-	cnw := newConcreteNodeWorker(executor, mode)
-	swp := &SinkWorkerPool{ConcreteNodeWorker: cnw}
+func newSinkWorkerPool[TIn any](exec SinkExecutor[TIn], mode WorkerMode) *SinkWorkerPool[TIn] {
+
+	cnw := newConcreteNodeWorker(exec, mode)
+	inType := reflect.TypeFor[TIn]()
+	swp := &SinkWorkerPool[TIn]{
+		ConcreteNodeWorker: cnw,
+		exec:               exec,
+		weighted:           inType.Kind() == reflect.Interface || inType.Implements(reflect.TypeFor[Weighted]()),
+	}
 
 	return swp
 }
 
 // CreateChannels creates channels for the sink worker
-func (swp *SinkWorkerPool) CreateChannels(buffer int) {
-	swp.inputChannel = make(chan any, buffer)
+func (swp *SinkWorkerPool[TIn]) CreateChannels(buffer int) {
+	if swp.batch != nil {
+		swp.inputBatches = make(chan []TIn, buffer)
+		if swp.edge.dropsItems() {
+			swp.inlets = []any{overflowInlet(swp.WPool, swp.edge, swp.inputBatches, dropChunk[TIn](swp.edge))}
+		}
+		return
+	}
+	swp.inputChannel = make(chan TIn, buffer)
+	if swp.edge.dropsItems() {
+		swp.inlets = []any{overflowInlet(swp.WPool, swp.edge, swp.inputChannel, dropItem[TIn](swp.edge))}
+	}
+}
+
+// countDelivered records an item that reached the sink
+func (swp *SinkWorkerPool[TIn]) countDelivered(item TIn) {
+	if swp.weighted {
+		swp.add(item)
+		return
+	}
+	swp.count.Add(1)
 }
 
 // Start Sink Worker Pool
-func (swp *SinkWorkerPool) Start(ctx CnvContext) error {
+func (swp *SinkWorkerPool[TIn]) Start(ctx CnvContext) error {
+	swp.startInlets(ctx)
+
 	switch swp.Mode {
 	case WorkerModeTransaction:
+		if swp.batch != nil {
+			return swp.startBatchTransactionMode(ctx)
+		}
 		return swp.startTransactionMode(ctx)
 	case WorkerModeLoop:
 		return swp.startLoopMode(ctx)
@@ -40,14 +83,48 @@ func (swp *SinkWorkerPool) Start(ctx CnvContext) error {
 }
 
 // startLoopMode SinkWorkerPool
-func (swp *SinkWorkerPool) startLoopMode(ctx CnvContext) error {
+func (swp *SinkWorkerPool[TIn]) startLoopMode(ctx CnvContext) error {
+
+	if swp.batch != nil {
+		// The executor keeps its channel of single items, relayed from the chunks
+		swp.inputChannel = make(chan TIn, swp.batch.size)
+		swp.relay(func() { unchunkItems(swp.inputBatches, swp.inputChannel) })
+	}
+
+	if !swp.trackDelivery {
+		return swp.ConcreteNodeWorker.startLoopMode(ctx, func() error {
+			return swp.exec.ExecuteLoop(ctx, swp.inputChannel)
+		})
+	}
+
+	// Every worker gets its items through a channel of its own, so that they are counted as they are handed over
+	return swp.ConcreteNodeWorker.startLoopMode(ctx, func() error {
+		counted := make(chan TIn)
+		execDone := make(chan struct{})
+		relayDone := make(chan struct{})
+		go func() {
+			defer close(relayDone)
+			defer close(counted)
+			for v := range swp.inputChannel {
+				select {
+				case counted <- v:
+					swp.countDelivered(v)
+				case <-execDone:
+					return
+				}
+			}
+		}()
 
-	return swp.ConcreteNodeWorker.startLoopMode(ctx, swp.inputChannel, nil)
+		err := swp.exec.ExecuteLoop(ctx, counted)
+		close(execDone)
+		<-relayDone
+		return err
+	})
 
 }
 
 // startTransactionMode starts SourceWorkerPool in transaction mode
-func (swp *SinkWorkerPool) startTransactionMode(ctx CnvContext) error {
+func (swp *SinkWorkerPool[TIn]) startTransactionMode(ctx CnvContext) error {
 
 	swp.sem = semaphore.NewWeighted(int64(swp.WorkerCount))
 
@@ -60,7 +137,13 @@ workerLoop:
 		default:
 		}
 
-		in, ok := <-swp.inputChannel
+		var in TIn
+		var ok bool
+		select {
+		case <-ctx.Done():
+			break workerLoop
+		case in, ok = <-swp.inputChannel:
+		}
 		if !ok {
 			ctx.SendLog(0, fmt.Sprintf("Executor:[%s] sink's input channel closed", swp.Executor.GetUniqueIdentifier()), nil)
 			break workerLoop
@@ -68,60 +151,135 @@ workerLoop:
 
 		if err := swp.sem.Acquire(ctx, 1); err != nil {
 			ctx.SendLog(0, fmt.Sprintf("Worker:[%s] for Executor:[%s] Failed to acquire semaphore", swp.Name, swp.Executor.GetUniqueIdentifier()), err)
-			break
+			break workerLoop
 		}
 
-		go func(data any) {
-			defer swp.recovery(ctx, "SinkWorkerPool")
+		swp.spawn(func() {
 			defer swp.sem.Release(1)
-			if ok {
-				_, err := swp.Executor.executeUntyped(ctx, data)
-				if err == ErrExecuteNotImplemented {
-					ctx.SendLog(0, fmt.Sprintf("Executor:[%s]", swp.Executor.GetUniqueIdentifier()), err)
-					log.Fatalf("Improper setup of Executor[%s], Execute() method is required", swp.Executor.GetUniqueIdentifier())
-				}
-				if err != nil {
-					ctx.SendLog(2, fmt.Sprintf("Worker:[%s] for Executor:[%s] Execute() Call Failed.",
-						swp.Name, swp.Executor.GetUniqueIdentifier()), err)
-					ctx.RecordError(swp.Executor.GetName(), err)
-				}
+			swp.executeOne(ctx, in)
+		})
+	}
+
+	return nil
+}
+
+// startBatchTransactionMode starts SinkWorkerPool in transaction mode, with a transaction per chunk
+func (swp *SinkWorkerPool[TIn]) startBatchTransactionMode(ctx CnvContext) error {
+
+	swp.sem = semaphore.NewWeighted(int64(swp.WorkerCount))
+
+workerLoop:
+	for {
+
+		var chunk []TIn
+		var ok bool
+		select {
+		case <-ctx.Done():
+			break workerLoop
+		case chunk, ok = <-swp.inputBatches:
+		}
+		if !ok {
+			ctx.SendLog(0, fmt.Sprintf("Executor:[%s] sink's input channel closed", swp.Executor.GetUniqueIdentifier()), nil)
+			break workerLoop
+		}
+
+		if err := swp.sem.Acquire(ctx, 1); err != nil {
+			ctx.SendLog(0, fmt.Sprintf("Worker:[%s] for Executor:[%s] Failed to acquire semaphore", swp.Name, swp.Executor.GetUniqueIdentifier()), err)
+			break workerLoop
+		}
+
+		swp.spawn(func() {
+			defer swp.sem.Release(1)
+
+			for _, in := range chunk {
+				swp.executeOne(ctx, in)
 			}
-		}(in)
+		})
 	}
 
 	return nil
 }
 
+// executeOne runs Execute() for a single item
+func (swp *SinkWorkerPool[TIn]) executeOne(ctx CnvContext, in TIn) {
+	defer swp.recovery(ctx, "SinkWorkerPool")
+	// The item counts towards progress whether or not Execute succeeds, as it has reached the end of the pipeline
+	defer swp.countDelivered(in)
+
+	err := swp.exec.Execute(ctx, in)
+	if err == ErrExecuteNotImplemented {
+		ctx.SendLog(0, fmt.Sprintf("Executor:[%s]", swp.Executor.GetUniqueIdentifier()), err)
+		log.Fatalf("Improper setup of Executor[%s], Execute() method is required", swp.Executor.GetUniqueIdentifier())
+	}
+	if err != nil {
+		ctx.SendLog(2, fmt.Sprintf("Worker:[%s] for Executor:[%s] Execute() Call Failed.",
+			swp.Name, swp.Executor.GetUniqueIdentifier()), err)
+		ctx.RecordError(swp.Executor.GetName(), err)
+	}
+}
+
 // GetOutputChannel returns the output channel of Sink WorkerPool
-func (swp *SinkWorkerPool) GetOutputChannel() (chan any, error) {
+func (swp *SinkWorkerPool[TIn]) GetOutputChannel() (any, error) {
 	return nil, ErrOutputChanDoesNotExist
 }
 
-// GetInputChannel returns the input channel of Sink WorkerPool
-func (swp *SinkWorkerPool) GetInputChannel() (chan any, error) {
+// GetInputChannel returns the input channel of Sink WorkerPool, a chan TIn, or a chan []TIn once batching is enabled
+func (swp *SinkWorkerPool[TIn]) GetInputChannel() (any, error) {
+	if inlet, ok := swp.inlet(0); ok {
+		return inlet, nil
+	}
+	if swp.batch != nil {
+		return swp.inputBatches, nil
+	}
 	return swp.inputChannel, nil
 }
 
-// SetInputChannel updates the input channel of Sink WorkerPool
-func (swp *SinkWorkerPool) SetInputChannel(inChan chan any) error {
-	swp.inputChannel = inChan
+// SetInputChannel updates the input channel of Sink WorkerPool, which must be a chan TIn,
+// or a chan []TIn once batching is enabled
+func (swp *SinkWorkerPool[TIn]) SetInputChannel(inChan any) error {
+	swp.clearInlets()
+	if swp.batch != nil {
+		ch, err := channelOf[[]TIn](inChan)
+		if err != nil {
+			return err
+		}
+		swp.inputBatches = ch
+		return nil
+	}
+
+	ch, err := channelOf[TIn](inChan)
+	if err != nil {
+		return err
+	}
+	swp.inputChannel = ch
 	return nil
 }
 
 // SetOutputChannel updates the output channel of Sink WorkerPool
-func (swp *SinkWorkerPool) SetOutputChannel(outChan chan any) error {
+func (swp *SinkWorkerPool[TIn]) SetOutputChannel(outChan any) error {
 	return ErrOutputChanDoesNotExist
 }
 
 // WorkerType returns the type of worker
-func (swp *SinkWorkerPool) WorkerType() string {
+func (swp *SinkWorkerPool[TIn]) WorkerType() string {
 	return WorkerTypeSink
 }
 
 // WaitAndStop SinkWorkerPool
-func (swp *SinkWorkerPool) WaitAndStop(ctx CnvContext) error {
+func (swp *SinkWorkerPool[TIn]) WaitAndStop(ctx CnvContext) error {
 
 	_ = swp.ConcreteNodeWorker.WaitAndStop(ctx)
 
+	switch {
+	case swp.batch == nil:
+		drain(swp.inputChannel)
+	case swp.Mode == WorkerModeLoop:
+		// The input relay closes the executor's input once it has been through every chunk
+		drain(swp.inputChannel)
+	default:
+		drain(swp.inputBatches)
+	}
+	swp.waitRelays()
+
 	return nil
 }
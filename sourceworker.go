@@ -9,43 +9,67 @@ import (
 )
 
 // SourceWorkerPool struct provides the worker pool infra for Source interface
-type SourceWorkerPool struct {
+type SourceWorkerPool[TOut any] struct {
 	*ConcreteNodeWorker
-	outputChannel chan any
+	exec          SourceExecutor[TOut]
+	outputChannel chan TOut
+	outputBatches chan []TOut // the output, once batching is enabled
+	chunks        *chunker[TOut]
 }
 
 // NewSourceWorkerPool creates a new SourceWorkerPool
-func NewSourceWorkerPool(executor nodeExecutor, mode WorkerMode) NodeWorker {
+func NewSourceWorkerPool[TOut any](exec SourceExecutor[TOut], mode WorkerMode) NodeWorker {
 
-	cnw := newConcreteNodeWorker(executor, mode)
-	swp := &SourceWorkerPool{ConcreteNodeWorker: cnw}
+	cnw := newConcreteNodeWorker(exec, mode)
+	swp := &SourceWorkerPool[TOut]{ConcreteNodeWorker: cnw, exec: exec}
 
 	return swp
 }
 
-// GetOutputChannel returns the output channel of Source WorkerPool
-func (swp *SourceWorkerPool) GetOutputChannel() (chan any, error) {
+// GetOutputChannel returns the output channel of Source WorkerPool, a chan TOut, or a chan []TOut once batching is enabled
+func (swp *SourceWorkerPool[TOut]) GetOutputChannel() (any, error) {
+	if swp.batch != nil {
+		return swp.outputBatches, nil
+	}
 	return swp.outputChannel, nil
 }
 
 // GetInputChannel returns the input channel of Source WorkerPool
-func (swp *SourceWorkerPool) GetInputChannel() (chan any, error) {
+func (swp *SourceWorkerPool[TOut]) GetInputChannel() (any, error) {
 	return nil, ErrInputChanDoesNotExist
 }
 
 // SetInputChannel updates the input channel of Source WorkerPool
-func (swp *SourceWorkerPool) SetInputChannel(inChan chan any) error {
+func (swp *SourceWorkerPool[TOut]) SetInputChannel(inChan any) error {
 	return ErrInputChanDoesNotExist
 }
 
-// SetOutputChannel updates the output channel of Source WorkerPool
-func (swp *SourceWorkerPool) SetOutputChannel(outChan chan any) error {
-	swp.outputChannel = outChan
+// SetOutputChannel updates the output channel of Source WorkerPool, which must be a chan TOut,
+// or a chan []TOut once batching is enabled
+func (swp *SourceWorkerPool[TOut]) SetOutputChannel(outChan any) error {
+	if swp.batch != nil {
+		ch, err := channelOf[[]TOut](outChan)
+		if err != nil {
+			return err
+		}
+		swp.outputBatches = ch
+		return nil
+	}
+
+	ch, err := channelOf[TOut](outChan)
+	if err != nil {
+		return err
+	}
+	swp.outputChannel = ch
 	return nil
 }
 
 // Start Source Worker Pool
-func (swp *SourceWorkerPool) Start(ctx CnvContext) error {
+func (swp *SourceWorkerPool[TOut]) Start(ctx CnvContext) error {
+	if swp.batch != nil {
+		swp.chunks = newChunker(swp.batch, swp.outputBatches, pipelineContext(ctx).Done())
+	}
+
 	switch swp.Mode {
 	case WorkerModeTransaction:
 		return swp.startTransactionMode(ctx)
@@ -57,14 +81,25 @@ func (swp *SourceWorkerPool) Start(ctx CnvContext) error {
 }
 
 // startLoopMode SourceWorkerPool
-func (swp *SourceWorkerPool) startLoopMode(ctx CnvContext) error {
+func (swp *SourceWorkerPool[TOut]) startLoopMode(ctx CnvContext) error {
 
-	return swp.ConcreteNodeWorker.startLoopMode(ctx, nil, swp.outputChannel)
+	if swp.batch != nil {
+		swp.outputChannel = make(chan TOut, swp.batch.size)
+		swp.relay(func() {
+			for v := range swp.outputChannel {
+				swp.chunks.add(v)
+			}
+		})
+	}
+
+	return swp.ConcreteNodeWorker.startLoopMode(ctx, func() error {
+		return swp.exec.ExecuteLoop(ctx, swp.outputChannel)
+	})
 
 }
 
 // startTransactionMode starts SourceWorkerPool in transaction mode
-func (swp *SourceWorkerPool) startTransactionMode(ctx CnvContext) error {
+func (swp *SourceWorkerPool[TOut]) startTransactionMode(ctx CnvContext) error {
 
 	swp.sem = semaphore.NewWeighted(int64(swp.WorkerCount))
 
@@ -92,50 +127,84 @@ workerLoop:
 			break workerLoop
 		}
 
-		go func() {
+		swp.spawn(func() {
 			defer swp.recovery(ctx, "SourceWorkerPool")
 			defer swp.sem.Release(1)
-			outData, err := swp.Executor.executeUntyped(ctx, nil)
-			switch err {
-			case nil:
-				select {
-				case <-ctx.Done():
+
+			// With batching, a transaction fetches up to a whole chunk
+			for i := 0; i < swp.transactionSize(); i++ {
+				outData, err := swp.exec.Execute(ctx)
+				switch err {
+				case nil:
+					select {
+					case <-ctx.Done():
+						return
+					default:
+					}
+					swp.send(ctx, outData)
+				case ErrExecuteNotImplemented:
+					ctx.SendLog(0, fmt.Sprintf("Executor:[%s]", swp.Executor.GetUniqueIdentifier()), err)
+					log.Fatalf("Improper setup of Executor[%s], Execute() method is required",
+						swp.Executor.GetUniqueIdentifier())
+				case ErrSourceExhausted:
+					ctx.SendLog(0, fmt.Sprintf("Executor:[%s]", swp.Executor.GetUniqueIdentifier()), err)
+					doneMutex.Lock()
+					workerDone = true
+					doneMutex.Unlock()
 					return
 				default:
+					ctx.SendLog(2, fmt.Sprintf("Worker:[%s] for Executor:[%s] Execute() Call Failed.",
+						swp.Name, swp.Executor.GetUniqueIdentifier()), err)
+					ctx.RecordError(swp.Executor.GetName(), err)
 				}
-				swp.outputChannel <- outData
-			case ErrExecuteNotImplemented:
-				ctx.SendLog(0, fmt.Sprintf("Executor:[%s]", swp.Executor.GetUniqueIdentifier()), err)
-				log.Fatalf("Improper setup of Executor[%s], Execute() method is required",
-					swp.Executor.GetUniqueIdentifier())
-			case ErrSourceExhausted:
-				ctx.SendLog(0, fmt.Sprintf("Executor:[%s]", swp.Executor.GetUniqueIdentifier()), err)
-				doneMutex.Lock()
-				workerDone = true
-				doneMutex.Unlock()
-				return
-			default:
-				ctx.SendLog(2, fmt.Sprintf("Worker:[%s] for Executor:[%s] Execute() Call Failed.",
-					swp.Name, swp.Executor.GetUniqueIdentifier()), err)
-				ctx.RecordError(swp.Executor.GetName(), err)
 			}
-		}()
+		})
 
 	}
 
 	return nil
 }
 
+// transactionSize is the number of Execute() calls made by a transaction
+func (swp *SourceWorkerPool[TOut]) transactionSize() int {
+	if swp.batch != nil {
+		return swp.batch.size
+	}
+	return 1
+}
+
+// send hands outData over to the next node
+func (swp *SourceWorkerPool[TOut]) send(ctx CnvContext, outData TOut) {
+	if swp.chunks != nil {
+		swp.chunks.add(outData)
+		return
+	}
+	// Once drained, the next node still reads, so only the whole conveyor being done can block this
+	select {
+	case <-pipelineContext(ctx).Done():
+	case swp.outputChannel <- outData:
+	}
+}
+
 // WorkerType returns the type of worker
-func (swp *SourceWorkerPool) WorkerType() string {
+func (swp *SourceWorkerPool[TOut]) WorkerType() string {
 	return WorkerTypeSource
 }
 
 // WaitAndStop SourceWorkerPool
-func (swp *SourceWorkerPool) WaitAndStop(ctx CnvContext) error {
+func (swp *SourceWorkerPool[TOut]) WaitAndStop(ctx CnvContext) error {
 
 	_ = swp.ConcreteNodeWorker.WaitAndStop(ctx)
 
-	close(swp.outputChannel)
+	if swp.batch == nil {
+		close(swp.outputChannel)
+		return nil
+	}
+
+	if swp.Mode == WorkerModeLoop {
+		close(swp.outputChannel)
+		swp.waitRelays()
+	}
+	swp.chunks.close()
 	return nil
 }
@@ -0,0 +1,253 @@
+package conveyor
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// PipelineSpec is a reusable template of a conveyor: its topology, along with factories creating the executors.
+// Types are validated once, while the spec is built, so that New() only has to create executors and link them.
+//
+// Build a spec with NewPipelineSpec() and the Spec*() functions, which mirror AddSource(), AddOperation() and the
+// other builders, then call New() for every run. A spec must not be changed once it's being used to create conveyors,
+// but New() can then be called from any number of goroutines. As every conveyor gets executors of its own, Stage()
+// finds them in it, e.g. to read the Result() of a ReduceSink, and Caches() gives the stats of its Memoized operations.
+type PipelineSpec struct {
+	Name      string
+	bufferLen int
+
+	configs []func(cnv *Conveyor)
+	steps   []func(cnv *Conveyor) error
+
+	// Shape of the pipeline built so far, to validate the next step against
+	lastNodeOutType  reflect.Type
+	lastJointOutType reflect.Type
+	branchOutType    reflect.Type // output type of the left branch, once SpecBranchSource() has started a right one
+	nodeCount        int
+	jointCount       int
+}
+
+// NewPipelineSpec creates an empty PipelineSpec. Conveyors created from it get the given name and bufferLen.
+func NewPipelineSpec(name string, bufferLen int) *PipelineSpec {
+	return &PipelineSpec{Name: name, bufferLen: bufferLen}
+}
+
+// Configure registers a function that sets up every new conveyor, before any node is added to it.
+// This is where to call SetTimeout(), EnableProgress(), SetLifeCycleHandler() and the like.
+func (spec *PipelineSpec) Configure(config func(cnv *Conveyor)) *PipelineSpec {
+	spec.configs = append(spec.configs, config)
+	return spec
+}
+
+// New creates a fresh conveyor from the spec, with its own executors, ready to be started
+func (spec *PipelineSpec) New() (*Conveyor, error) {
+	return spec.NewWithID("")
+}
+
+// NewWithID is like New(), but sets the ID of the conveyor, unless id is empty
+func (spec *PipelineSpec) NewWithID(id string) (*Conveyor, error) {
+	if spec.nodeCount == 0 {
+		return nil, ErrEmptyConveyor
+	}
+
+	cnv, err := NewConveyor(spec.Name, spec.bufferLen)
+	if err != nil {
+		return nil, err
+	}
+	if id != "" {
+		cnv.SetID(id)
+	}
+	for _, config := range spec.configs {
+		config(cnv)
+	}
+
+	for _, step := range spec.steps {
+		if err := step(cnv); err != nil {
+			return nil, err
+		}
+	}
+	return cnv, nil
+}
+
+// checkInput validates expectedIn against the type that the previous node or joint produces
+func checkInput(produced reflect.Type, expectedIn reflect.Type) error {
+	if produced != nil && produced != expectedIn {
+		return fmt.Errorf("%w: expected input type %v but got %v", ErrTypeMismatch, produced, expectedIn)
+	}
+	return nil
+}
+
+// SpecSource adds a source to the spec, like AddSource() does to a conveyor.
+// newExec is called by every New(), to create the source of that conveyor.
+func SpecSource[TOut any](spec *PipelineSpec, newExec func() SourceExecutor[TOut], mode WorkerMode) error {
+	spec.steps = append(spec.steps, func(cnv *Conveyor) error {
+		return AddSource[TOut](cnv, newExec(), mode)
+	})
+	spec.nodeCount++
+	spec.lastNodeOutType = reflect.TypeFor[TOut]()
+	return nil
+}
+
+// SpecOperation adds an operation to the spec, like AddOperation() does to a conveyor
+func SpecOperation[TIn, TOut any](spec *PipelineSpec, newExec func() OperationExecutor[TIn, TOut], mode WorkerMode) error {
+	if err := checkInput(spec.lastNodeOutType, reflect.TypeFor[TIn]()); err != nil {
+		return err
+	}
+
+	spec.steps = append(spec.steps, func(cnv *Conveyor) error {
+		return addOperation[TIn, TOut](cnv, newExec(), mode)
+	})
+	spec.nodeCount++
+	spec.lastNodeOutType = reflect.TypeFor[TOut]()
+	return nil
+}
+
+// SpecSink adds a sink to the spec, like AddSink() does to a conveyor
+func SpecSink[TIn any](spec *PipelineSpec, newExec func() SinkExecutor[TIn], mode WorkerMode) error {
+	if err := checkInput(spec.lastNodeOutType, reflect.TypeFor[TIn]()); err != nil {
+		return err
+	}
+
+	spec.steps = append(spec.steps, func(cnv *Conveyor) error {
+		return addSink[TIn](cnv, newExec(), mode)
+	})
+	spec.nodeCount++
+	spec.lastNodeOutType = nil
+	return nil
+}
+
+// SpecJointAfterNode adds a joint to the spec, like AddJointAfterNode() does to a conveyor
+func SpecJointAfterNode[TIn, TOut any](spec *PipelineSpec, newExec func() JointExecutor[TIn, TOut]) error {
+	if err := checkInput(spec.lastNodeOutType, reflect.TypeFor[TIn]()); err != nil {
+		return err
+	}
+	if spec.nodeCount == 0 {
+		return ErrNoNodesAvailable
+	}
+
+	spec.steps = append(spec.steps, func(cnv *Conveyor) error {
+		return addJointAfterNode[TIn, TOut](cnv, newExec())
+	})
+	spec.jointCount++
+	spec.lastNodeOutType = nil
+	spec.lastJointOutType = reflect.TypeFor[TOut]()
+	return nil
+}
+
+// SpecSinkAfterJoint adds a sink after the last joint of the spec, like AddSinkAfterJoint() does to a conveyor
+func SpecSinkAfterJoint[TIn any](spec *PipelineSpec, newExec func() SinkExecutor[TIn], mode WorkerMode) error {
+	if err := checkInput(spec.lastJointOutType, reflect.TypeFor[TIn]()); err != nil {
+		return err
+	}
+	if spec.jointCount == 0 {
+		return ErrNoJointsAvailable
+	}
+
+	spec.steps = append(spec.steps, func(cnv *Conveyor) error {
+		return addSinkAfterJoint[TIn](cnv, newExec(), mode)
+	})
+	spec.nodeCount++
+	return nil
+}
+
+// SpecOperationAfterJoint adds an operation after the last joint of the spec, like AddOperationAfterJoint()
+// does to a conveyor
+func SpecOperationAfterJoint[TIn, TOut any](spec *PipelineSpec, newExec func() OperationExecutor[TIn, TOut], mode WorkerMode) error {
+	if err := checkInput(spec.lastJointOutType, reflect.TypeFor[TIn]()); err != nil {
+		return err
+	}
+	if spec.jointCount == 0 {
+		return ErrNoJointsAvailable
+	}
+
+	spec.steps = append(spec.steps, func(cnv *Conveyor) error {
+		return addOperationAfterJoint[TIn, TOut](cnv, newExec(), mode)
+	})
+	spec.nodeCount++
+	spec.lastNodeOutType = reflect.TypeFor[TOut]()
+	return nil
+}
+
+// SpecBranchSource starts a second branch in the spec, like AddBranchSource() does in a conveyor
+func SpecBranchSource[TOut any](spec *PipelineSpec, newExec func() SourceExecutor[TOut], mode WorkerMode) error {
+	if spec.lastNodeOutType == nil {
+		return ErrNoOutputNode
+	}
+	if spec.branchOutType != nil {
+		return ErrUnjoinedBranch
+	}
+
+	spec.steps = append(spec.steps, func(cnv *Conveyor) error {
+		return AddBranchSource[TOut](cnv, newExec(), mode)
+	})
+	spec.nodeCount++
+	spec.branchOutType = spec.lastNodeOutType
+	spec.lastNodeOutType = reflect.TypeFor[TOut]()
+	return nil
+}
+
+// SpecJoinJoint joins the two branches of the spec, like AddJoinJoint() does in a conveyor.
+// Every New() creates a JoinJoint of its own, with the given name & config.
+func SpecJoinJoint[L, R any, K comparable, Out any](spec *PipelineSpec, name string, cfg JoinConfig[L, R, K, Out]) error {
+	if spec.branchOutType == nil {
+		return ErrNoBranch
+	}
+	if expectedIn := reflect.TypeFor[L](); spec.branchOutType != expectedIn {
+		return fmt.Errorf("%w: expected left input type %v but got %v", ErrTypeMismatch, spec.branchOutType, expectedIn)
+	}
+	if expectedIn := reflect.TypeFor[R](); spec.lastNodeOutType != expectedIn {
+		return fmt.Errorf("%w: expected right input type %v but got %v", ErrTypeMismatch, spec.lastNodeOutType, expectedIn)
+	}
+	if _, err := NewJoinJoint(name, cfg); err != nil {
+		return err
+	}
+
+	spec.steps = append(spec.steps, func(cnv *Conveyor) error {
+		join, err := NewJoinJoint(name, cfg)
+		if err != nil {
+			return err
+		}
+		return AddJoinJoint(cnv, join)
+	})
+	spec.jointCount++
+	spec.branchOutType = nil
+	spec.lastNodeOutType = nil
+	spec.lastJointOutType = reflect.TypeFor[Out]()
+	return nil
+}
+
+// SpecWindow adds a window operation to the spec, like AddWindow() does to a conveyor
+func SpecWindow[TIn, TOut any](spec *PipelineSpec, name string, cfg WindowConfig[TIn],
+	aggregate func(ctx CnvContext, w Window, items []TIn) (TOut, error)) error {
+	if _, err := NewWindow(name, cfg, aggregate); err != nil {
+		return err
+	}
+	return SpecOperation(spec, func() OperationExecutor[TIn, TOut] {
+		exec, _ := NewWindow(name, cfg, aggregate)
+		return exec
+	}, WorkerModeLoop)
+}
+
+// SpecReduce adds a ReduceSink to the spec, like AddReduce() does to a conveyor.
+// Once a conveyor created by the spec is done, Stage() finds its ReduceSink by name, to read its Result().
+func SpecReduce[T any, K comparable, A any](spec *PipelineSpec, name string, concurrency int, reducer Reducer[T, K, A]) error {
+	if _, err := NewReduceSink(name, concurrency, reducer); err != nil {
+		return err
+	}
+	return SpecSink(spec, func() SinkExecutor[T] {
+		rs, _ := NewReduceSink(name, concurrency, reducer)
+		return rs
+	}, WorkerModeLoop)
+}
+
+// SpecDedupe adds a Dedupe to the spec, like AddDedupe() does to a conveyor.
+// Every New() creates a Dedupe of its own, which Stage() finds by name in the conveyor.
+func SpecDedupe[T any, K comparable](spec *PipelineSpec, name string, concurrency int, cfg DedupeConfig[T, K]) error {
+	if _, err := NewDedupe(name, concurrency, cfg); err != nil {
+		return err
+	}
+	return SpecOperation(spec, func() OperationExecutor[T, T] {
+		dd, _ := NewDedupe(name, concurrency, cfg)
+		return dd
+	}, WorkerModeTransaction)
+}
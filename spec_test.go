@@ -0,0 +1,164 @@
+package conveyor
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPipelineSpec_ManyRuns verifies that a spec can be instantiated concurrently, each run getting its own executors.
+func TestPipelineSpec_ManyRuns(t *testing.T) {
+	var sources atomic.Int64
+	spec := NewPipelineSpec("per_request", 10)
+	require.NoError(t, SpecSource(spec, func() SourceExecutor[int] {
+		sources.Add(1)
+		return SourceFromSeq("src", slices.Values([]int{1, 2, 3}))
+	}, WorkerModeTransaction))
+	require.NoError(t, SpecOperation(spec, func() OperationExecutor[int, string] {
+		return OperationFunc("itoa", 2, itoa)
+	}, WorkerModeTransaction))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cnv, err := spec.New()
+			if !assert.NoError(t, err) {
+				return
+			}
+			results, err := Collect[string](context.Background(), cnv)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, []string{"1", "2", "3"}, results)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(20), sources.Load())
+}
+
+func TestPipelineSpec_Validation(t *testing.T) {
+	spec := NewPipelineSpec("invalid", 10)
+	_, err := spec.New()
+	assert.ErrorIs(t, err, ErrEmptyConveyor)
+
+	assert.ErrorIs(t, SpecJointAfterNode(spec, func() JointExecutor[int, int] {
+		joint, _ := NewReplicateJoint[int]("replicate", 2)
+		return joint
+	}), ErrNoNodesAvailable)
+
+	require.NoError(t, SpecSource(spec, func() SourceExecutor[int] {
+		return SourceFromSeq("src", slices.Values([]int{1}))
+	}, WorkerModeLoop))
+	assert.ErrorIs(t, SpecSink(spec, func() SinkExecutor[string] {
+		return SinkFunc("snk", 1, func(ctx CnvContext, in string) error { return nil })
+	}, WorkerModeLoop), ErrTypeMismatch)
+	assert.ErrorIs(t, SpecSinkAfterJoint(spec, func() SinkExecutor[int] {
+		return SinkFunc("snk", 1, func(ctx CnvContext, in int) error { return nil })
+	}, WorkerModeLoop), ErrNoJointsAvailable)
+
+	// Failed steps aren't part of the spec
+	cnv, err := spec.NewWithID("run-1")
+	require.NoError(t, err)
+	assert.Equal(t, "run-1", cnv.ID())
+	assert.Len(t, cnv.workers, 1)
+}
+
+func TestPipelineSpec_JointAndConfig(t *testing.T) {
+	spec := NewPipelineSpec("fan_out", 10)
+	spec.Configure(func(cnv *Conveyor) { cnv.SetTimeout(time.Second) })
+
+	var total atomic.Int64
+	sum := func() SinkExecutor[int] {
+		return SinkFunc("sum", 1, func(ctx CnvContext, in int) error {
+			total.Add(int64(in))
+			return nil
+		})
+	}
+	require.NoError(t, SpecSource(spec, func() SourceExecutor[int] {
+		return SourceFromSeq("src", slices.Values([]int{1, 2, 3}))
+	}, WorkerModeTransaction))
+	require.NoError(t, SpecJointAfterNode(spec, func() JointExecutor[int, int] {
+		joint, _ := NewReplicateJoint[int]("replicate", 2)
+		return joint
+	}))
+	require.NoError(t, SpecSinkAfterJoint(spec, sum, WorkerModeTransaction))
+	require.NoError(t, SpecOperationAfterJoint(spec, func() OperationExecutor[int, int] {
+		return OperationFunc("triple", 1, func(ctx CnvContext, in int) (int, error) { return 3 * in, nil })
+	}, WorkerModeTransaction))
+	require.NoError(t, SpecSink(spec, sum, WorkerModeTransaction))
+
+	for run := 1; run <= 3; run++ {
+		cnv, err := spec.New()
+		require.NoError(t, err)
+		_, hasDeadline := cnv.ctx.Deadline()
+		assert.True(t, hasDeadline)
+		require.NoError(t, startWithin(t, cnv, time.Second))
+		assert.Equal(t, StateFinished, cnv.CurrentState())
+		assert.Equal(t, int64(24*run), total.Load())
+	}
+}
+
+// TestPipelineSpec_Stages verifies the spec builders of joins, windows, reduces & dedupes,
+// and that Stage() finds the executors of each conveyor.
+func TestPipelineSpec_Stages(t *testing.T) {
+	spec := NewPipelineSpec("stages", 10)
+	require.NoError(t, SpecSource(spec, func() SourceExecutor[order] {
+		return SourceFromSeq("orders", slices.Values([]order{{ID: 1, Amount: 10}, {ID: 2, Amount: 20}, {ID: 1, Amount: 10}}))
+	}, WorkerModeLoop))
+	require.NoError(t, SpecDedupe(spec, "dedupe", 1, DedupeConfig[order, int]{Key: func(o order) int { return o.ID }, MaxKeys: 10}))
+	assert.ErrorIs(t, SpecJoinJoint(spec, "match", paymentJoin(JoinInner, time.Minute, 0).cfg), ErrNoBranch)
+	require.NoError(t, SpecBranchSource(spec, func() SourceExecutor[payment] {
+		return SourceFromSeq("payments", slices.Values([]payment{{OrderID: 2, Paid: 20}, {OrderID: 1, Paid: 10}}))
+	}, WorkerModeLoop))
+	assert.ErrorIs(t, SpecBranchSource(spec, func() SourceExecutor[payment] { return nil }, WorkerModeLoop), ErrUnjoinedBranch)
+	assert.ErrorIs(t, SpecJoinJoint(spec, "match", JoinConfig[payment, payment, int, string]{}), ErrTypeMismatch)
+	assert.ErrorIs(t, SpecJoinJoint(spec, "match", JoinConfig[order, payment, int, string]{}), ErrInvalidJoin)
+	require.NoError(t, SpecJoinJoint(spec, "match", paymentJoin(JoinInner, time.Minute, 0).cfg))
+	require.NoError(t, SpecOperationAfterJoint(spec, func() OperationExecutor[string, string] {
+		memo, _ := NewMemoized(OperationFunc("upper", 1, func(ctx CnvContext, in string) (string, error) {
+			return strings.ToUpper(in), nil
+		}), CacheConfig[string, string]{Key: func(in string) string { return in }, MaxSize: 10})
+		return memo
+	}, WorkerModeTransaction))
+	assert.ErrorIs(t, SpecReduce(spec, "count", 1, Reducer[string, int, int]{}), ErrInvalidReducer)
+	require.NoError(t, SpecReduce(spec, "count", 1, Reducer[string, int, int]{
+		Key:  func(s string) int { return len(s) },
+		Init: func() int { return 0 },
+		Add:  func(n int, s string) int { return n + 1 },
+	}))
+
+	for run := 0; run < 2; run++ {
+		cnv, err := spec.New()
+		require.NoError(t, err)
+		require.NoError(t, startWithin(t, cnv, time.Second))
+
+		dd, ok := Stage[*Dedupe[order, int]](cnv, "dedupe")
+		require.True(t, ok)
+		assert.Equal(t, int64(1), dd.Duplicates())
+		counts, ok := Stage[*ReduceSink[string, int, int]](cnv, "count")
+		require.True(t, ok)
+		assert.Equal(t, map[int]int{len("1: 10/10"): 2}, counts.Result())
+		assert.Equal(t, int64(2), cnv.Caches().Stage("upper").Misses)
+		_, ok = Stage[*Dedupe[order, int]](cnv, "count")
+		assert.False(t, ok)
+	}
+
+	windows := NewPipelineSpec("windows", 10)
+	require.NoError(t, SpecSource(windows, func() SourceExecutor[int] { return SourceFromSeq("src", upTo(10)) }, WorkerModeLoop))
+	assert.ErrorIs(t, SpecWindow(windows, "window", WindowConfig[int]{}, collectWindow), ErrInvalidWindow)
+	require.NoError(t, SpecWindow(windows, "window", WindowConfig[int]{Size: 5 * time.Second, Timestamp: seconds}, collectWindow))
+	for run := 0; run < 2; run++ {
+		cnv, err := windows.New()
+		require.NoError(t, err)
+		results, err := Collect[[]int](context.Background(), cnv)
+		require.NoError(t, err)
+		assert.Equal(t, [][]int{{0, 5, 0, 1, 2, 3, 4}, {5, 10, 5, 6, 7, 8, 9}}, results)
+	}
+}
@@ -0,0 +1,239 @@
+package conveyor
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Codec serialises the items an edge with OverflowSpill writes to disk
+type Codec interface {
+	NewEncoder(w io.Writer) Encoder
+	NewDecoder(r io.Reader) Decoder
+}
+
+// Encoder writes items to a segment file, in order
+type Encoder interface {
+	Encode(v any) error
+}
+
+// Decoder reads items back from a segment file, in the order they were written
+type Decoder interface {
+	Decode(v any) error
+}
+
+// GobCodec is the default Codec, based on encoding/gob. Items must be gob-encodable,
+// and the concrete types behind interface fields registered with gob.Register().
+type GobCodec struct{}
+
+// NewEncoder returns a gob encoder writing to w
+func (GobCodec) NewEncoder(w io.Writer) Encoder {
+	return gob.NewEncoder(w)
+}
+
+// NewDecoder returns a gob decoder reading from r
+func (GobCodec) NewDecoder(r io.Reader) Decoder {
+	return gob.NewDecoder(r)
+}
+
+// SpillConfig configures where, and how, an edge with OverflowSpill writes the items beyond its buffer
+type SpillConfig struct {
+	// Dir is where the edge's temp directory is created, os.TempDir() if empty
+	Dir string
+	// Codec serialises the items, GobCodec if nil
+	Codec Codec
+}
+
+// spillDirs keeps track of the temp directories of a conveyor's spilling edges, so that cleanup() removes them
+type spillDirs struct {
+	mu   sync.Mutex
+	dirs []string
+}
+
+// create makes a new temp directory in parent, to be removed along with the conveyor
+func (sd *spillDirs) create(parent string) (string, error) {
+	dir, err := os.MkdirTemp(parent, "conveyor-spill-*")
+	if err != nil {
+		return "", err
+	}
+	sd.mu.Lock()
+	sd.dirs = append(sd.dirs, dir)
+	sd.mu.Unlock()
+	return dir, nil
+}
+
+// removeAll deletes every temp directory, with the segment files left in them
+func (sd *spillDirs) removeAll() {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	for _, dir := range sd.dirs {
+		if err := os.RemoveAll(dir); err != nil {
+			log.Printf("Conveyor:[spill] Failed to remove [%s] Error:[%v]\n", dir, err)
+		}
+	}
+	sd.dirs = nil
+}
+
+// segment is a file of spilled items, read back in the order they were written
+type segment[E any] struct {
+	path    string
+	file    *os.File
+	w       *bufio.Writer
+	enc     Encoder
+	reader  *os.File
+	dec     Decoder
+	pending int // items written, and not read back yet
+}
+
+func newSegment[E any](path string, codec Codec) (*segment[E], error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := os.Open(path)
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(path)
+		return nil, err
+	}
+	w := bufio.NewWriter(file)
+	return &segment[E]{path: path, file: file, w: w, enc: codec.NewEncoder(w), reader: reader, dec: codec.NewDecoder(reader)}, nil
+}
+
+func (s *segment[E]) write(v E) error {
+	if err := s.enc.Encode(v); err != nil {
+		return err
+	}
+	s.pending++
+	return nil
+}
+
+// read returns the oldest item not read back yet. Only call it while pending > 0.
+func (s *segment[E]) read() (E, error) {
+	var v E
+	// The decoder must only ever see whole items, so whatever is still buffered gets written first
+	if s.w.Buffered() > 0 {
+		if err := s.w.Flush(); err != nil {
+			return v, err
+		}
+	}
+	s.pending--
+	err := s.dec.Decode(&v)
+	return v, err
+}
+
+// remove closes the segment, and deletes its file
+func (s *segment[E]) remove() {
+	_ = s.reader.Close()
+	_ = s.file.Close()
+	_ = os.Remove(s.path)
+}
+
+// spiller writes the items an edge can't buffer to segment files, a new one each time the consumer catches up
+type spiller struct {
+	e     *edge
+	dir   string
+	count int
+}
+
+func newSegmentOf[E any](sp *spiller) (*segment[E], error) {
+	if sp.dir == "" {
+		dir, err := sp.e.spills.create(sp.e.Spill.Dir)
+		if err != nil {
+			return nil, err
+		}
+		sp.dir = dir
+	}
+	sp.count++
+	codec := sp.e.Spill.Codec
+	if codec == nil {
+		codec = GobCodec{}
+	}
+	return newSegment[E](filepath.Join(sp.dir, fmt.Sprintf("%06d.seg", sp.count)), codec)
+}
+
+// relaySpill moves the inlet's items to buffer, and those arriving while buffer is full, or while older ones
+// are still on disk, to a segment file. They are replayed in order, as the consumer makes room in buffer.
+// Once ctx is done, whatever is left on disk is discarded, as the consumer only drains its input.
+func relaySpill[E any](ctx CnvContext, e *edge, inlet <-chan E, buffer chan E, drop func(E)) {
+	sp := &spiller{e: e}
+	var seg *segment[E]
+	var next E
+	hasNext := false
+
+	spill := func(v E) {
+		if seg == nil {
+			var err error
+			if seg, err = newSegmentOf[E](sp); err != nil {
+				ctx.RecordError(e.stage, err)
+				drop(v)
+				return
+			}
+		}
+		if err := seg.write(v); err != nil {
+			ctx.RecordError(e.stage, err)
+			drop(v)
+		}
+	}
+	discard := func() {
+		seg.remove()
+		seg = nil
+		hasNext = false
+	}
+
+	for inlet != nil || seg != nil {
+		if seg == nil {
+			v, ok := <-inlet
+			if !ok {
+				inlet = nil
+				continue
+			}
+			select {
+			case buffer <- v:
+				continue
+			default:
+			}
+			select {
+			case <-ctx.Done():
+				buffer <- v // The consumer is only draining its input by now
+			default:
+				spill(v)
+			}
+			continue
+		}
+
+		if !hasNext {
+			if seg.pending == 0 {
+				discard()
+				continue
+			}
+			var err error
+			if next, err = seg.read(); err != nil {
+				// The rest of the segment can't be trusted anymore
+				ctx.RecordError(e.stage, err)
+				discard()
+				continue
+			}
+			hasNext = true
+		}
+
+		select {
+		case <-ctx.Done():
+			discard()
+		case v, ok := <-inlet:
+			if !ok {
+				inlet = nil
+				continue
+			}
+			spill(v)
+		case buffer <- next:
+			hasNext = false
+		}
+	}
+	close(buffer)
+}
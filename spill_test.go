@@ -0,0 +1,114 @@
+package conveyor
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jsonCodec is a Codec other than the default one
+type jsonCodec struct{}
+
+func (jsonCodec) NewEncoder(w io.Writer) Encoder { return json.NewEncoder(w) }
+func (jsonCodec) NewDecoder(r io.Reader) Decoder { return json.NewDecoder(r) }
+
+// spilling builds a conveyor sending 0..n-1 to a sink whose edge holds 4 items and spills to dir.
+// The sink only reads once the source has sent everything.
+func spilling(t *testing.T, n int, dir string, codec Codec) (cnv *Conveyor, sent, release chan struct{}, received *[]int) {
+	t.Helper()
+	sent, release, received = make(chan struct{}), make(chan struct{}), new([]int)
+
+	cnv, _ = NewConveyor("spill", 10)
+	cnv.SetEdge("snk", EdgeConfig{Buffer: 4, Overflow: OverflowSpill, Spill: SpillConfig{Dir: dir, Codec: codec}})
+	require.NoError(t, AddSource[int](cnv, SourceLoopFunc("src", 1, func(ctx CnvContext, out chan<- int) error {
+		for i := range n {
+			out <- i
+		}
+		close(sent)
+		return nil
+	}), WorkerModeLoop))
+	require.NoError(t, AddSink[int](cnv, SinkLoopFunc("snk", 1, func(ctx CnvContext, in <-chan int) error {
+		<-release
+		for v := range in {
+			*received = append(*received, v)
+		}
+		return nil
+	}), WorkerModeLoop))
+	return cnv, sent, release, received
+}
+
+// segmentFiles lists the segment files left in dir
+func segmentFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "conveyor-spill-*", "*.seg"))
+	require.NoError(t, err)
+	return files
+}
+
+// TestEdge_Spill verifies that the items beyond the buffer go to disk, come back in order, and that the
+// segment files are gone once the conveyor is done.
+func TestEdge_Spill(t *testing.T) {
+	for name, codec := range map[string]Codec{"gob": nil, "json": jsonCodec{}} {
+		dir := t.TempDir()
+		cnv, sent, release, received := spilling(t, 1000, dir, codec)
+
+		done := make(chan error, 1)
+		go func() { done <- cnv.Start() }()
+		<-sent
+		assert.NotEmpty(t, segmentFiles(t, dir), name)
+		close(release)
+		require.NoError(t, <-done)
+
+		assert.Equal(t, slices.Collect(upTo(1000)), *received, name)
+		assert.Equal(t, int64(0), cnv.Drops().Total(), name)
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries, name)
+	}
+}
+
+// TestEdge_SpillStop verifies that the items still on disk are discarded, along with their files, once the conveyor is stopped.
+func TestEdge_SpillStop(t *testing.T) {
+	dir := t.TempDir()
+	cnv, sent, release, _ := spilling(t, 1000, dir, nil)
+
+	done := make(chan error, 1)
+	go func() { done <- cnv.Start() }()
+	<-sent
+	cnv.Stop()
+	close(release)
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Start() didn't return after Stop()")
+	}
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+// TestEdge_SpillBatched verifies that chunks are spilled as a whole, and keep their order.
+func TestEdge_SpillBatched(t *testing.T) {
+	cnv, _ := NewConveyor("spill_batched", 2)
+	cnv.EnableBatching(10, time.Millisecond).
+		SetEdge("slow", EdgeConfig{Overflow: OverflowSpill, Spill: SpillConfig{Dir: t.TempDir()}})
+	require.NoError(t, AddSource[int](cnv, SourceFromSeq("src", upTo(500)), WorkerModeLoop))
+	require.NoError(t, AddOperation[int, int](cnv, OperationFunc("slow", 1, func(ctx CnvContext, in int) (int, error) {
+		time.Sleep(10 * time.Microsecond)
+		return in, nil
+	}), WorkerModeLoop))
+
+	results, err := Collect[int](context.Background(), cnv)
+	require.NoError(t, err)
+	assert.Equal(t, slices.Collect(upTo(500)), results)
+}
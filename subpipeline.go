@@ -0,0 +1,163 @@
+package conveyor
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SubPipeline is a chain of operations, run as a single OperationExecutor[TIn, TOut], so that a common chain
+// can be reused in any conveyor, with AddOperation() or AddOperationAfterJoint().
+// Start one with NewSubPipeline(), and add more operations with Then().
+//
+// Errors of the inner operations are recorded in ErrorStats under "{sub-pipeline}/{operation}".
+//
+// In transaction mode, every item goes through all the operations' Execute(), in turn, and Count() items
+// are processed at the same time.
+// In loop mode, each of the Count() goroutines runs its own chain of operations' ExecuteLoop(),
+// every operation with Count() goroutines of its own, like they'd run in a conveyor.
+type SubPipeline[TIn, TOut any] struct {
+	ConcreteOperationExecutor[TIn, TOut]
+	concurrency int
+	stages      []nodeExecutor
+
+	// run and loop are the whole chain, composed by Then() on typed values and channels
+	run  func(ctx CnvContext, inData TIn) (TOut, error)
+	loop func(ctx CnvContext, in <-chan TIn, out chan<- TOut)
+}
+
+// NewSubPipeline starts a SubPipeline with its first operation
+func NewSubPipeline[TIn, TOut any](name string, concurrency int, first OperationExecutor[TIn, TOut]) *SubPipeline[TIn, TOut] {
+	return &SubPipeline[TIn, TOut]{
+		ConcreteOperationExecutor: ConcreteOperationExecutor[TIn, TOut]{Name: name},
+		concurrency:               workerCount(concurrency),
+		stages:                    []nodeExecutor{first},
+		run: func(ctx CnvContext, inData TIn) (TOut, error) {
+			return runStage(ctx, first, inData)
+		},
+		loop: func(ctx CnvContext, in <-chan TIn, out chan<- TOut) {
+			loopStage(ctx, first, in, out)
+		},
+	}
+}
+
+// Then returns a new SubPipeline, running next on the output of sp. sp itself is left unchanged,
+// so that a common chain can be extended in different ways.
+func Then[TIn, TMid, TOut any](sp *SubPipeline[TIn, TMid], next OperationExecutor[TMid, TOut]) *SubPipeline[TIn, TOut] {
+	stages := make([]nodeExecutor, 0, len(sp.stages)+1)
+	stages = append(stages, sp.stages...)
+	return &SubPipeline[TIn, TOut]{
+		ConcreteOperationExecutor: ConcreteOperationExecutor[TIn, TOut]{Name: sp.Name},
+		concurrency:               sp.concurrency,
+		stages:                    append(stages, next),
+		run: func(ctx CnvContext, inData TIn) (TOut, error) {
+			mid, err := sp.run(ctx, inData)
+			if err != nil {
+				var zero TOut
+				return zero, err
+			}
+			return runStage(ctx, next, mid)
+		},
+		loop: func(ctx CnvContext, in <-chan TIn, out chan<- TOut) {
+			mid := make(chan TMid)
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				sp.loop(ctx, in, mid)
+			}()
+			loopStage(ctx, next, mid, out)
+			<-done
+		},
+	}
+}
+
+func (sp *SubPipeline[TIn, TOut]) Count() int {
+	return sp.concurrency
+}
+
+// Execute runs inData through every operation. The first one to fail stops it, and its error is returned
+// as a StageError.
+func (sp *SubPipeline[TIn, TOut]) Execute(ctx CnvContext, inData TIn) (TOut, error) {
+	return sp.run(nestContext(ctx, sp.Name), inData)
+}
+
+// ExecuteLoop links the operations with channels, and runs them until inChan is closed
+func (sp *SubPipeline[TIn, TOut]) ExecuteLoop(ctx CnvContext, inChan <-chan TIn, outChan chan<- TOut) error {
+	nested := nestContext(ctx, sp.Name)
+
+	last := make(chan TOut)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sp.loop(nested, inChan, last)
+	}()
+
+	// Keep draining the last operation, even once ctx is done, so that it never blocks
+	for v := range last {
+		select {
+		case <-ctx.Done():
+		case outChan <- v:
+		}
+	}
+	<-done
+	return nil
+}
+
+// CleanUp calls CleanUp() of every operation, and returns the first error
+func (sp *SubPipeline[TIn, TOut]) CleanUp() error {
+	var firstErr error
+	for _, stage := range sp.stages {
+		if err := stage.CleanUp(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%s: %w", stage.GetName(), err)
+		}
+	}
+	return firstErr
+}
+
+// runStage runs one item through the stage's Execute(), and returns its error as a StageError
+func runStage[TIn, TOut any](ctx CnvContext, stage OperationExecutor[TIn, TOut], inData TIn) (TOut, error) {
+	out, err := stage.Execute(ctx, inData)
+	if err != nil {
+		var zero TOut
+		return zero, &StageError{Stage: stage.GetName(), Err: err}
+	}
+	return out, nil
+}
+
+// loopStage runs Count() goroutines of the stage's ExecuteLoop(), and closes out once they have all returned.
+// Whatever they leave in "in" is drained, so that the previous stage doesn't block on it.
+func loopStage[TIn, TOut any](ctx CnvContext, stage OperationExecutor[TIn, TOut], in <-chan TIn, out chan<- TOut) {
+	var wg sync.WaitGroup
+	for i := 0; i < workerCount(stage.Count()); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					ctx.RecordError(stage.GetName(), &PanicError{Value: r})
+				}
+			}()
+			if err := stage.ExecuteLoop(ctx, in, out); err != nil {
+				ctx.RecordError(stage.GetName(), err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	drain(in)
+	close(out)
+}
+
+// nestedContext records errors under the stage of the executor it's given to, e.g. "{sub-pipeline}/{operation}"
+type nestedContext struct {
+	CnvContext
+	stage string
+}
+
+// nestContext returns a context recording errors under stage
+func nestContext(ctx CnvContext, stage string) CnvContext {
+	return &nestedContext{CnvContext: ctx, stage: stage}
+}
+
+func (nc *nestedContext) RecordError(stage string, err error) {
+	nc.CnvContext.RecordError(nc.stage+"/"+stage, err)
+}
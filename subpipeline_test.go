@@ -0,0 +1,96 @@
+package conveyor
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errEmpty = errors.New("empty")
+
+// normalizeChain trims & lower-cases strings, rejects empty ones, and returns their length
+func normalizeChain() *SubPipeline[string, int] {
+	normalize := NewSubPipeline("prep", 2, OperationFunc("normalize", 2, func(ctx CnvContext, in string) (string, error) {
+		return strings.ToLower(strings.TrimSpace(in)), nil
+	}))
+	validated := Then(normalize, OperationFunc("validate", 1, func(ctx CnvContext, in string) (string, error) {
+		if in == "" {
+			return "", errEmpty
+		}
+		return in, nil
+	}))
+	return Then(validated, OperationFunc("measure", 1, func(ctx CnvContext, in string) (int, error) {
+		return len(in), nil
+	}))
+}
+
+func TestSubPipeline_BothModes(t *testing.T) {
+	for _, mode := range []WorkerMode{WorkerModeTransaction, WorkerModeLoop} {
+		cnv, err := NewConveyor("with_sub", 10)
+		require.NoError(t, err)
+		require.NoError(t, AddSource[string](cnv, SourceFromSeq("src", slices.Values([]string{" Go ", "", "Conveyor", "  "})), mode))
+		require.NoError(t, AddOperation[string, int](cnv, normalizeChain(), mode))
+
+		lengths, err := Collect[int](context.Background(), cnv)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []int{2, 8}, lengths, "mode %v", mode)
+
+		// Errors of the inner stages are nested under the sub-pipeline's name
+		assert.Equal(t, int64(2), cnv.Errors().Count("prep/validate", errEmpty), "mode %v", mode)
+		assert.Equal(t, int64(2), cnv.Errors().StageTotal("prep"), "mode %v", mode)
+		assert.Equal(t, int64(0), cnv.Errors().Count("prep", errEmpty), "mode %v", mode)
+	}
+}
+
+func TestSubPipeline_Execute(t *testing.T) {
+	chain := normalizeChain()
+
+	n, err := chain.Execute(newTestContext(), "  ABC")
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	_, err = chain.Execute(newTestContext(), " ")
+	var stageErr *StageError
+	require.ErrorAs(t, err, &stageErr)
+	assert.Equal(t, "validate", stageErr.Stage)
+	assert.ErrorIs(t, err, errEmpty)
+}
+
+// TestSubPipeline_Then verifies that extending a chain leaves the original one as it was.
+func TestSubPipeline_Then(t *testing.T) {
+	base := NewSubPipeline("base", 1, OperationFunc("double", 1, func(ctx CnvContext, in int) (int, error) { return 2 * in, nil }))
+	plusOne := Then(base, OperationFunc("inc", 1, func(ctx CnvContext, in int) (int, error) { return in + 1, nil }))
+	squared := Then(base, OperationFunc("square", 1, func(ctx CnvContext, in int) (int, error) { return in * in, nil }))
+
+	v, _ := base.Execute(newTestContext(), 3)
+	assert.Equal(t, 6, v)
+	v, _ = plusOne.Execute(newTestContext(), 3)
+	assert.Equal(t, 7, v)
+	v, _ = squared.Execute(newTestContext(), 3)
+	assert.Equal(t, 36, v)
+}
+
+// TestSubPipeline_LoopPanic verifies that a panicking inner stage is recorded, and doesn't hold up the conveyor.
+func TestSubPipeline_LoopPanic(t *testing.T) {
+	chain := Then(
+		NewSubPipeline("chain", 1, OperationFunc("ok", 1, func(ctx CnvContext, in int) (int, error) { return in, nil })),
+		OperationLoopFunc("boom", 1, func(ctx CnvContext, in <-chan int, out chan<- int) error {
+			<-in
+			panic("boom")
+		}),
+	)
+
+	cnv, _ := NewConveyor("sub_panic", 10)
+	require.NoError(t, AddSource[int](cnv, SourceFromSeq("src", slices.Values([]int{1, 2, 3, 4})), WorkerModeLoop))
+	require.NoError(t, AddOperation[int, int](cnv, chain, WorkerModeLoop))
+
+	results, err := Collect[int](context.Background(), cnv)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+	assert.Equal(t, int64(1), cnv.Errors().Count("chain/boom", &PanicError{}))
+}
@@ -0,0 +1,455 @@
+package conveyor
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// token follows an item produced by a tracked source, through every item derived from it.
+// Once all of them are done, the item is complete.
+type token struct {
+	seq     uint64
+	refs    atomic.Int64
+	tracker *itemTracker
+
+	// onAck & onNack are set with EnableAcks(), and only one of them is ever called
+	onAck   func()
+	onNack  func(err error)
+	settled atomic.Bool
+
+	mu     sync.Mutex
+	failed atomic.Bool // one of the items derived from the token's item failed, holding checkpoints back
+	undo   []func()    // called once the item fails, to undo what executors did for it, e.g. a Dedupe remembering its key
+
+	meta *Metadata // set with EnableMetadata()
+}
+
+// fork accounts for n more items derived from the token's item, e.g. by a joint replicating it
+func (tok *token) fork(n int) {
+	tok.refs.Add(int64(n))
+}
+
+// done is called once an item derived from the token's item is done: delivered, failed or dropped
+func (tok *token) done() {
+	if tok.refs.Add(-1) != 0 {
+		return
+	}
+	if tok.onAck != nil && tok.settled.CompareAndSwap(false, true) {
+		tok.onAck()
+	}
+	tok.tracker.complete(tok.seq, tok.failed.Load())
+}
+
+// fail nacks the token's item, the first time one of the items derived from it fails. It's still up to
+// the caller to call done(), unless the failed item carries on, e.g. after ctx.RecordError().
+func (tok *token) fail(err error) {
+	tok.mu.Lock()
+	tok.failed.Store(true)
+	undo := tok.undo
+	tok.undo = nil
+	tok.mu.Unlock()
+	for _, fn := range undo {
+		fn()
+	}
+
+	if tok.onNack != nil && tok.settled.CompareAndSwap(false, true) {
+		tok.onNack(err)
+	}
+}
+
+// onFail calls fn once one of the items derived from the token's item fails, right away if one already has
+func (tok *token) onFail(fn func()) {
+	tok.mu.Lock()
+	if !tok.failed.Load() {
+		tok.undo = append(tok.undo, fn)
+		tok.mu.Unlock()
+		return
+	}
+	tok.mu.Unlock()
+	fn()
+}
+
+// tracked is what flows between the worker pools of a conveyor whose items are tracked,
+// the adapters below giving the executors the items alone
+type tracked[T any] struct {
+	item T
+	tok  *token
+}
+
+// Weight keeps the item's own weight, for progress
+func (t tracked[T]) Weight() int64 {
+	if w, ok := any(t.item).(Weighted); ok {
+		return w.Weight()
+	}
+	return 1
+}
+
+func (t tracked[T]) settle() {
+	t.tok.fail(ErrItemDropped)
+	t.tok.done()
+}
+
+// settler is implemented by tracked items, so that edges dropping them can tell they're done
+type settler interface {
+	settle()
+}
+
+// doneOnPanic fails tok if the executor panics, before the pool recovers
+func doneOnPanic(tok *token) {
+	if r := recover(); r != nil {
+		tok.fail(&PanicError{Value: r})
+		tok.done()
+		panic(r)
+	}
+}
+
+// trackedSource gives a token to every item of the source
+type trackedSource[TOut any] struct {
+	nodeExecutor
+	exec    SourceExecutor[TOut]
+	tracker *itemTracker
+	acked   Acknowledged[TOut] // set with EnableAcks()
+
+	metadata  bool // set with EnableMetadata()
+	described Described[TOut]
+	produced  atomic.Uint64
+}
+
+// wrap gives out its token
+func (ts *trackedSource[TOut]) wrap(out TOut) tracked[TOut] {
+	tok := ts.tracker.emit()
+	if ts.acked != nil {
+		tok.onAck = func() { ts.acked.OnAck(out) }
+		tok.onNack = func(err error) { ts.acked.OnNack(out, err) }
+	}
+	if ts.metadata {
+		ts.describe(tok, ts.produced.Add(1)-1, out)
+	}
+	return tracked[TOut]{item: out, tok: tok}
+}
+
+func (ts *trackedSource[TOut]) Execute(ctx CnvContext) (tracked[TOut], error) {
+	if ts.tracker.checkpointing() {
+		ts.tracker.emitMu.Lock()
+		defer ts.tracker.emitMu.Unlock()
+	}
+
+	out, err := ts.exec.Execute(ctx)
+	switch err {
+	case nil:
+		return ts.wrap(out), nil
+	case ErrSourceExhausted:
+		// The last checkpoint is saved once the last items are done, so that the next run has nothing left to do
+		if ts.tracker.checkpointing() && !ts.tracker.exhausted {
+			ts.tracker.exhausted = true
+			ts.tracker.snapshot()
+		}
+	}
+	return tracked[TOut]{}, err
+}
+
+// ExecuteLoop is only used with acks, as checkpoints can't be taken while the executor runs on its own
+func (ts *trackedSource[TOut]) ExecuteLoop(ctx CnvContext, outChan chan<- tracked[TOut]) error {
+	if ts.tracker.checkpointing() {
+		return ErrTrackedLoopMode
+	}
+
+	items := make(chan TOut)
+	var relay sync.WaitGroup
+	relay.Add(1)
+	go func() {
+		defer relay.Done()
+		// Once drained, the next node still reads, so only the whole conveyor being done can block this
+		done := pipelineContext(ctx).Done()
+		for out := range items {
+			select {
+			case <-done:
+			case outChan <- ts.wrap(out):
+			}
+		}
+	}()
+
+	err := ts.exec.ExecuteLoop(ctx, items)
+	close(items)
+	relay.Wait()
+	return err
+}
+
+// trackedOperation hands the token of its input over to its output
+type trackedOperation[TIn, TOut any] struct {
+	nodeExecutor
+	exec    OperationExecutor[TIn, TOut]
+	tracker *itemTracker // set in loop mode, which is only allowed when items merely carry their metadata
+}
+
+func (to *trackedOperation[TIn, TOut]) Execute(ctx CnvContext, inData tracked[TIn]) (tracked[TOut], error) {
+	defer doneOnPanic(inData.tok)
+
+	out, err := to.exec.Execute(contextFor(ctx, inData.tok), inData.item)
+	if errors.Is(err, ErrSkipItem) {
+		inData.tok.done() // Skipped on purpose, so it's still acked
+		return tracked[TOut]{}, err
+	}
+	if err != nil {
+		inData.tok.fail(err)
+		inData.tok.done()
+		return tracked[TOut]{}, err
+	}
+	return tracked[TOut]{item: out, tok: inData.tok}, nil
+}
+
+func (to *trackedOperation[TIn, TOut]) ExecuteLoop(ctx CnvContext, inChan <-chan tracked[TIn], outChan chan<- tracked[TOut]) error {
+	if to.tracker == nil {
+		return ErrTrackedLoopMode
+	}
+	return relayMetadata(ctx, to.tracker, []<-chan tracked[TIn]{inChan}, []chan<- tracked[TOut]{outChan},
+		func(items []chan TIn, outs []chan TOut) error {
+			return to.exec.ExecuteLoop(ctx, items[0], outs[0])
+		})
+}
+
+// trackedSink is done with an item once the executor is. In loop mode, that's once it has read the item,
+// which is only allowed when items merely carry their metadata: acks & checkpoints refuse loop-mode sinks.
+type trackedSink[TIn any] struct {
+	nodeExecutor
+	exec SinkExecutor[TIn]
+}
+
+func (ts *trackedSink[TIn]) Execute(ctx CnvContext, inData tracked[TIn]) error {
+	defer doneOnPanic(inData.tok)
+
+	err := ts.exec.Execute(contextFor(ctx, inData.tok), inData.item)
+	if err != nil {
+		inData.tok.fail(err)
+	}
+	inData.tok.done()
+	return err
+}
+
+func (ts *trackedSink[TIn]) ExecuteLoop(ctx CnvContext, inChan <-chan tracked[TIn]) error {
+	items := make(chan TIn)
+	stopped := make(chan struct{})
+	var relay sync.WaitGroup
+	relay.Add(1)
+	go func() {
+		defer relay.Done()
+		for {
+			select {
+			case <-stopped:
+				return
+			case in, ok := <-inChan:
+				if !ok {
+					close(items)
+					return
+				}
+				select {
+				case <-stopped:
+					return
+				case items <- in.item:
+					in.tok.done()
+				}
+			}
+		}
+	}()
+
+	// Once the executor returns, the pool drains whatever is left of the input
+	err := ts.exec.ExecuteLoop(ctx, items)
+	close(stopped)
+	relay.Wait()
+	return err
+}
+
+// trackedOutputSink is the outputSink of OutputChannel() & Collect(), done with an item once it's on the channel
+type trackedOutputSink[T any] struct {
+	nodeExecutor
+	out *outputSink[T]
+}
+
+func (ts *trackedOutputSink[T]) Execute(ctx CnvContext, inData tracked[T]) error {
+	return ErrExecuteNotImplemented
+}
+
+func (ts *trackedOutputSink[T]) ExecuteLoop(ctx CnvContext, inChan <-chan tracked[T]) error {
+	for v := range inChan {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ts.out.out <- v.item:
+			v.tok.done()
+		}
+	}
+	return nil
+}
+
+// trackedReplicateJoint replicates items along with their tokens, each copy to be done on its own
+type trackedReplicateJoint[T any] struct {
+	*ReplicateJoint[T]
+}
+
+func (rj *trackedReplicateJoint[T]) ExecuteLoop(cnvCtx CnvContext, inChans []chan tracked[T], outChans []chan tracked[T]) error {
+	switch {
+	case len(inChans) == 0:
+		return ErrNoInputChannel
+	case len(outChans) == 0:
+		return ErrNoOutputChannel
+	case len(inChans) > 1:
+		return ErrMultipleInputChannels
+	case len(outChans) == 1:
+		return ErrOneToOneConnection
+	}
+
+	for input := range inChans[0] {
+		input.tok.fork(len(outChans) - 1)
+		for _, outCh := range outChans {
+			outCh <- input
+		}
+	}
+	return nil
+}
+
+// trackedJoint runs any joint, when items merely carry their metadata
+type trackedJoint[TIn, TOut any] struct {
+	jointExecutor
+	exec    JointExecutor[TIn, TOut]
+	tracker *itemTracker
+}
+
+func (tj *trackedJoint[TIn, TOut]) ExecuteLoop(ctx CnvContext, inChans []chan tracked[TIn], outChans []chan tracked[TOut]) error {
+	ins := make([]<-chan tracked[TIn], len(inChans))
+	for i, ch := range inChans {
+		ins[i] = ch
+	}
+	outs := make([]chan<- tracked[TOut], len(outChans))
+	for i, ch := range outChans {
+		outs[i] = ch
+	}
+	return relayMetadata(ctx, tj.tracker, ins, outs, func(items []chan TIn, outs []chan TOut) error {
+		return tj.exec.ExecuteLoop(ctx, items, outs)
+	})
+}
+
+// sinkNode is a sink worker pool, of whatever type
+type sinkNode interface {
+	NodeWorker
+	sinkCounter
+}
+
+// sourceWorkerFor creates the worker pool of a source, giving a token to its items if they're tracked,
+// for checkpoints, acks or metadata
+func sourceWorkerFor[TOut any](cnv *Conveyor, exec SourceExecutor[TOut], mode WorkerMode) (NodeWorker, error) {
+	if cnv.checkpoints == nil && !cnv.acks && !cnv.metadata {
+		return NewSourceWorkerPool[TOut](exec, mode), nil
+	}
+	if err := checkTrackedEdge(cnv, exec.GetName()); err != nil {
+		return nil, err
+	}
+
+	ts := &trackedSource[TOut]{nodeExecutor: exec, exec: exec, tracker: &itemTracker{}}
+	if cnv.acks {
+		acked, ok := exec.(Acknowledged[TOut])
+		if !ok {
+			return nil, ErrNotAcknowledged
+		}
+		ts.acked = acked
+	}
+	if cnv.metadata {
+		ts.metadata = true
+		ts.described, _ = exec.(Described[TOut])
+	}
+	if cnv.checkpoints != nil {
+		src, ok := exec.(Checkpointable)
+		if !ok {
+			return nil, ErrNotCheckpointable
+		}
+		if mode != WorkerModeTransaction {
+			return nil, ErrTrackedLoopMode
+		}
+		tr, err := newItemTracker(cnv.ID(), src, cnv.checkpoints)
+		if err != nil {
+			return nil, err
+		}
+		ts.tracker = tr
+	}
+
+	cnv.items = ts.tracker
+	return NewSourceWorkerPool[tracked[TOut]](ts, mode), nil
+}
+
+// operationWorkerFor creates the worker pool of an operation, handing tokens over if items are tracked
+func operationWorkerFor[TIn, TOut any](cnv *Conveyor, exec OperationExecutor[TIn, TOut], mode WorkerMode) (NodeWorker, error) {
+	if cnv.items == nil {
+		return NewOperationWorkerPool[TIn, TOut](exec, mode), nil
+	}
+	if err := checkTrackedEdge(cnv, exec.GetName()); err != nil {
+		return nil, err
+	}
+	op := &trackedOperation[TIn, TOut]{nodeExecutor: exec, exec: exec}
+	if mode != WorkerModeTransaction {
+		if cnv.settlesItems() {
+			return nil, ErrTrackedLoopMode
+		}
+		op.tracker = cnv.items
+	}
+	return NewOperationWorkerPool[tracked[TIn], tracked[TOut]](op, mode), nil
+}
+
+// settlesItems tells whether items are acked or checkpointed, so that every stage must tell when it's done with one
+func (cnv *Conveyor) settlesItems() bool {
+	return cnv.acks || cnv.checkpoints != nil
+}
+
+// sinkWorkerFor creates the worker pool of a sink, marking items done if they're tracked
+func sinkWorkerFor[TIn any](cnv *Conveyor, exec SinkExecutor[TIn], mode WorkerMode) (sinkNode, error) {
+	if cnv.items == nil {
+		swp := newSinkWorkerPool[TIn](exec, mode)
+		swp.trackDelivery = cnv.needProgress
+		return swp, nil
+	}
+	if err := checkTrackedEdge(cnv, exec.GetName()); err != nil {
+		return nil, err
+	}
+	var snk SinkExecutor[tracked[TIn]] = &trackedSink[TIn]{nodeExecutor: exec, exec: exec}
+	if mode == WorkerModeLoop && cnv.settlesItems() {
+		out, ok := exec.(*outputSink[TIn])
+		if !ok {
+			return nil, fmt.Errorf("%w: sink %s", ErrTrackedLoopMode, exec.GetName())
+		}
+		snk = &trackedOutputSink[TIn]{nodeExecutor: out, out: out}
+	}
+	swp := newSinkWorkerPool[tracked[TIn]](snk, mode)
+	swp.trackDelivery = cnv.needProgress
+	return swp, nil
+}
+
+// jointWorkerFor creates the worker pool of a joint. ReplicateJoint is the only one that can replicate tokens,
+// other joints being only allowed when items merely carry their metadata.
+func jointWorkerFor[TIn, TOut any](cnv *Conveyor, exec JointExecutor[TIn, TOut]) (JointWorker, error) {
+	if cnv.items == nil {
+		return NewJointWorkerPool[TIn, TOut](exec), nil
+	}
+	if err := checkTrackedEdge(cnv, exec.GetName()); err != nil {
+		return nil, err
+	}
+
+	rj, ok := any(exec).(*ReplicateJoint[TIn])
+	if !ok {
+		if cnv.settlesItems() {
+			return nil, fmt.Errorf("%w: %s is a %v", ErrTrackedJoint, exec.GetName(), reflect.TypeOf(exec))
+		}
+		return NewJointWorkerPool[tracked[TIn], tracked[TOut]](&trackedJoint[TIn, TOut]{jointExecutor: exec, exec: exec, tracker: cnv.items}), nil
+	}
+	// TIn and TOut are the same type for a ReplicateJoint
+	joint := any(&trackedReplicateJoint[TIn]{ReplicateJoint: rj}).(JointExecutor[tracked[TIn], tracked[TOut]])
+	return NewJointWorkerPool[tracked[TIn], tracked[TOut]](joint), nil
+}
+
+// checkTrackedEdge refuses edges spilling to disk, as tokens only live in memory
+func checkTrackedEdge(cnv *Conveyor, stage string) error {
+	if cnv.edges[stage].Overflow == OverflowSpill {
+		return fmt.Errorf("%w: edge of %s", ErrTrackedSpill, stage)
+	}
+	return nil
+}
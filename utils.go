@@ -11,13 +11,9 @@ func LinkWorker2Worker(a NodeWorker, b NodeWorker) error {
 
 // LinkJointAfterNode links JointWorker after NodeWorkers, maps input channel of joint worker on output channel of node worker
 func LinkJointAfterNode(nw NodeWorker, jw JointWorker, index int) error {
-	chnls, err := jw.GetInputChannels()
+	ch, err := jw.GetInputChannel(index)
 	if err == nil {
-		if index < len(chnls) {
-			ch := chnls[index]
-			return nw.SetOutputChannel(ch)
-		}
-		return ErrLessInputChannelsInJoint
+		return nw.SetOutputChannel(ch)
 	}
 	return err
 }
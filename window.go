@@ -0,0 +1,203 @@
+package conveyor
+
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
+// WindowConfig sets how a window operation groups its items
+type WindowConfig[TIn any] struct {
+	// Size is how long each window lasts
+	Size time.Duration
+	// Slide is how often a window starts. Windows are tumbling when it's 0 or Size,
+	// and sliding when it's shorter, an item then belonging to Size/Slide windows
+	Slide time.Duration
+	// Timestamp returns the event time of an item. Items are grouped by the time they're read
+	// when it's nil, and a window closes once that time has passed. With event time, a window closes
+	// once an item past its end has been read, and items read after that are late, and dropped
+	Timestamp func(item TIn) time.Time
+}
+
+// Window is the time span [Start, End) of a window
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// windowOperation is the OperationExecutor, for WorkerModeLoop, behind AddWindow
+type windowOperation[TIn, TOut any] struct {
+	ConcreteOperationExecutor[TIn, TOut]
+	size      time.Duration
+	slide     time.Duration
+	timestamp func(TIn) time.Time
+	aggregate func(ctx CnvContext, w Window, items []TIn) (TOut, error)
+}
+
+// NewWindow returns an OperationExecutor, for WorkerModeLoop, that groups its items into windows, as set by cfg,
+// and writes the result of aggregate for each of them once it closes. Windows without any item are skipped,
+// and those still open once the input is closed, i.e. the source is exhausted or the conveyor drained, are flushed.
+func NewWindow[TIn, TOut any](name string, cfg WindowConfig[TIn],
+	aggregate func(ctx CnvContext, w Window, items []TIn) (TOut, error)) (OperationExecutor[TIn, TOut], error) {
+	if cfg.Size <= 0 || cfg.Slide < 0 || cfg.Slide > cfg.Size || aggregate == nil {
+		return nil, ErrInvalidWindow
+	}
+
+	slide := cfg.Slide
+	if slide == 0 {
+		slide = cfg.Size
+	}
+	return &windowOperation[TIn, TOut]{
+		ConcreteOperationExecutor: ConcreteOperationExecutor[TIn, TOut]{Name: name},
+		size:                      cfg.Size,
+		slide:                     slide,
+		timestamp:                 cfg.Timestamp,
+		aggregate:                 aggregate,
+	}, nil
+}
+
+// AddWindow adds a window operation, see NewWindow(), after the last node.
+// As it runs in WorkerModeLoop, it can't be added once items are acked or checkpointed.
+func AddWindow[TIn, TOut any](cnv *Conveyor, name string, cfg WindowConfig[TIn],
+	aggregate func(ctx CnvContext, w Window, items []TIn) (TOut, error)) error {
+	exec, err := NewWindow(name, cfg, aggregate)
+	if err != nil {
+		return err
+	}
+	return AddOperation[TIn, TOut](cnv, exec, WorkerModeLoop)
+}
+
+// Count is 1, as a single go-routine holds the open windows
+func (wo *windowOperation[TIn, TOut]) Count() int {
+	return 1
+}
+
+func (wo *windowOperation[TIn, TOut]) ExecuteLoop(ctx CnvContext, inChan <-chan TIn, outChan chan<- TOut) error {
+	open := make(map[int64][]TIn)  // items of the open windows, by start
+	owned := make(map[int64]int64) // progress units of the items whose last window is open, by start
+	var owed int64                 // progress units the results have counted beyond the items they merged
+	var closed time.Time           // end of the last window closed, with event time
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
+
+	// emit closes the windows ending by until, in order, and tells whether the conveyor is still running
+	emit := func(until time.Time, all bool) bool {
+		var starts []int64
+		for start := range open {
+			if all || !wo.end(start).After(until) {
+				starts = append(starts, start)
+			}
+		}
+		slices.Sort(starts)
+		for _, start := range starts {
+			items, merged := open[start], owned[start]
+			delete(open, start)
+			delete(owned, start)
+			w := Window{Start: time.Unix(0, start), End: wo.end(start)}
+			if w.End.After(closed) {
+				closed = w.End
+			}
+			out, err := wo.aggregate(ctx, w, items)
+			if err == nil {
+				// The result counts towards progress once it reaches the sink, in place of the items it merged
+				merged -= weightOf(out)
+			}
+			if merged -= owed; merged > 0 {
+				skipItem(ctx, units(merged))
+				owed = 0
+			} else {
+				owed = -merged
+			}
+			if err != nil {
+				ctx.RecordError(wo.Name, err)
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return false
+			case outChan <- out:
+			}
+		}
+		return true
+	}
+
+	// rearm makes the timer fire once the earliest open window ends, with processing time
+	rearm := func() {
+		if wo.timestamp != nil || len(open) == 0 {
+			return
+		}
+		first := wo.end(slices.Min(slices.Collect(maps.Keys(open))))
+		timer.Reset(time.Until(first))
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case now := <-timer.C:
+			if !emit(now, false) {
+				return nil
+			}
+			rearm()
+
+		case in, ok := <-inChan:
+			if !ok {
+				emit(time.Time{}, true)
+				return nil
+			}
+
+			at := time.Now()
+			if wo.timestamp != nil {
+				at = wo.timestamp(in)
+			}
+			added, last := false, int64(0) // last is the start of the last window to close that in is added to
+			for _, start := range wo.starts(at) {
+				if wo.timestamp != nil && !wo.end(start).After(closed) {
+					continue // Late for this window, which has already closed
+				}
+				open[start] = append(open[start], in)
+				if !added || start > last {
+					last = start
+				}
+				added = true
+			}
+			if !added {
+				ctx.RecordError(wo.Name, ErrLateItem)
+				skipItem(ctx, in)
+			} else {
+				owned[last] += weightOf(in)
+			}
+
+			if wo.timestamp != nil {
+				if !emit(at, false) {
+					return nil
+				}
+			}
+			rearm()
+		}
+	}
+}
+
+// starts returns the start of every window at belongs to, in UnixNano
+func (wo *windowOperation[TIn, TOut]) starts(at time.Time) []int64 {
+	t := at.UnixNano()
+	slide, size := int64(wo.slide), int64(wo.size)
+	last := t - mod(t, slide)
+
+	var starts []int64
+	for start := last; start > t-size; start -= slide {
+		starts = append(starts, start)
+	}
+	return starts
+}
+
+func (wo *windowOperation[TIn, TOut]) end(start int64) time.Time {
+	return time.Unix(0, start).Add(wo.size)
+}
+
+// mod is the remainder of a / b, positive even for times before 1970
+func mod(a, b int64) int64 {
+	return ((a % b) + b) % b
+}
@@ -0,0 +1,174 @@
+package conveyor
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seconds is the event time of item i
+func seconds(i int) time.Time {
+	return time.Unix(int64(i), 0)
+}
+
+// collectWindow returns the items of every window, and its span in seconds
+func collectWindow(ctx CnvContext, w Window, items []int) ([]int, error) {
+	return append([]int{int(w.Start.Unix()), int(w.End.Unix())}, items...), nil
+}
+
+// TestWindow_EventTime verifies that tumbling & sliding windows group items by their event time,
+// and that the last windows are flushed once the source is exhausted.
+func TestWindow_EventTime(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   WindowConfig[int]
+		wants [][]int
+	}{
+		{
+			name: "tumbling",
+			cfg:  WindowConfig[int]{Size: 3 * time.Second, Timestamp: seconds},
+			wants: [][]int{
+				{0, 3, 0, 1, 2}, {3, 6, 3, 4, 5}, {6, 9, 6, 7, 8}, {9, 12, 9},
+			},
+		},
+		{
+			name: "sliding",
+			cfg:  WindowConfig[int]{Size: 4 * time.Second, Slide: 2 * time.Second, Timestamp: seconds},
+			wants: [][]int{
+				{-2, 2, 0, 1}, {0, 4, 0, 1, 2, 3}, {2, 6, 2, 3, 4, 5}, {4, 8, 4, 5, 6, 7}, {6, 10, 6, 7, 8, 9}, {8, 12, 8, 9},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cnv, _ := NewConveyor("event_time", 10)
+			require.NoError(t, AddSource[int](cnv, SourceFromSeq("src", upTo(10)), WorkerModeLoop))
+			require.NoError(t, AddWindow(cnv, "window", tt.cfg, collectWindow))
+
+			results, err := Collect[[]int](context.Background(), cnv)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wants, results)
+		})
+	}
+}
+
+// TestWindow_Late verifies that items of windows that have already closed are dropped, and recorded.
+func TestWindow_Late(t *testing.T) {
+	cnv, _ := NewConveyor("late", 10)
+	require.NoError(t, AddSource[int](cnv, SourceFromSeq("src", slices.Values([]int{0, 1, 5, 2, 6})), WorkerModeLoop))
+	require.NoError(t, AddWindow(cnv, "window", WindowConfig[int]{Size: 3 * time.Second, Timestamp: seconds}, collectWindow))
+
+	results, err := Collect[[]int](context.Background(), cnv)
+	require.NoError(t, err)
+	assert.Equal(t, [][]int{{0, 3, 0, 1}, {3, 6, 5}, {6, 9, 6}}, results)
+	assert.Equal(t, int64(1), cnv.Errors().Count("window", ErrLateItem))
+}
+
+// sizedSeqSource is a source of n items, that reports its size
+type sizedSeqSource struct {
+	SourceExecutor[int]
+	n int64
+}
+
+func (s *sizedSeqSource) Size() int64 { return s.n }
+
+// TestWindow_Progress verifies that the items merged into windows, or dropped as late, count towards progress.
+func TestWindow_Progress(t *testing.T) {
+	items := append(slices.Collect(upTo(50)), 1, 50) // 1 is late
+	for _, cfg := range []WindowConfig[int]{
+		{Size: 3 * time.Second, Timestamp: seconds},
+		{Size: 4 * time.Second, Slide: 2 * time.Second, Timestamp: seconds},
+	} {
+		cnv, _ := NewConveyor("window_progress", 10)
+		cnv.EnableProgress(time.Hour)
+		require.NoError(t, AddSource[int](cnv, &sizedSeqSource{SourceFromSeq("src", slices.Values(items)), int64(len(items))}, WorkerModeLoop))
+		require.NoError(t, AddWindow(cnv, "window", cfg, collectWindow))
+		require.NoError(t, AddSink[[]int](cnv, SinkFunc("snk", 1, func(ctx CnvContext, in []int) error { return nil }), WorkerModeTransaction))
+		require.NoError(t, startWithin(t, cnv, time.Second))
+
+		report := cnv.ProgressReport()
+		assert.Equal(t, int64(1), cnv.Errors().Count("window", ErrLateItem))
+		assert.Equal(t, int64(52), report.Total, "slide: %v", cfg.Slide)
+		assert.Equal(t, int64(52), report.Done, "slide: %v", cfg.Slide)
+		assert.Equal(t, float64(100), report.Percent, "slide: %v", cfg.Slide)
+	}
+}
+
+// TestWindow_ProcessingTime verifies that a window closes once its time has passed, while the source is still running.
+func TestWindow_ProcessingTime(t *testing.T) {
+	closed := make(chan struct{}, 1)
+	cnv, _ := NewConveyor("processing_time", 10)
+	require.NoError(t, AddSource[int](cnv, SourceLoopFunc("src", 1, func(ctx CnvContext, out chan<- int) error {
+		for i := range 3 {
+			out <- i
+		}
+		select {
+		case <-closed: // Only once the window went through
+		case <-time.After(time.Second):
+		}
+		return nil
+	}), WorkerModeLoop))
+	require.NoError(t, AddWindow(cnv, "window", WindowConfig[int]{Size: 20 * time.Millisecond},
+		func(ctx CnvContext, w Window, items []int) (int, error) {
+			assert.Equal(t, 20*time.Millisecond, w.End.Sub(w.Start))
+			return len(items), nil
+		}))
+	var total int
+	require.NoError(t, AddSink[int](cnv, SinkFunc("snk", 1, func(ctx CnvContext, in int) error {
+		total += in
+		if total == 3 {
+			closed <- struct{}{}
+		}
+		return nil
+	}), WorkerModeTransaction))
+
+	start := time.Now()
+	require.NoError(t, startWithin(t, cnv, 2*time.Second))
+	assert.Equal(t, 3, total)
+	assert.Less(t, time.Since(start), time.Second, "the window closed before the source was done")
+}
+
+// TestWindow_Drain verifies that open windows are flushed once the conveyor has drained.
+func TestWindow_Drain(t *testing.T) {
+	cnv, _ := NewConveyor("drained", 10)
+	require.NoError(t, AddSource[int](cnv, SourceLoopFunc("src", 1, func(ctx CnvContext, out chan<- int) error {
+		for i := range 5 {
+			out <- i
+		}
+		<-ctx.Done()
+		return nil
+	}), WorkerModeLoop))
+	require.NoError(t, AddWindow(cnv, "window", WindowConfig[int]{Size: time.Hour, Timestamp: seconds}, collectWindow))
+	out, err := OutputChannel[[]int](cnv)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- cnv.Start() }()
+	time.Sleep(10 * time.Millisecond)
+	cnv.Drain()
+
+	var results [][]int
+	for window := range out {
+		results = append(results, window)
+	}
+	require.NoError(t, <-done)
+	assert.Equal(t, [][]int{{0, 3600, 0, 1, 2, 3, 4}}, results)
+}
+
+// TestWindow_Invalid verifies the window's config, and that it can't be added once items are acked.
+func TestWindow_Invalid(t *testing.T) {
+	for _, cfg := range []WindowConfig[int]{{}, {Size: time.Second, Slide: 2 * time.Second}, {Size: time.Second, Slide: -1}} {
+		_, err := NewWindow("window", cfg, collectWindow)
+		assert.ErrorIs(t, err, ErrInvalidWindow)
+	}
+
+	cnv, _ := NewConveyor("tracked", 10)
+	cnv.EnableAcks()
+	require.NoError(t, AddSource[int](cnv, newAckingSource(SourceFromSeq("src", upTo(10))), WorkerModeLoop))
+	assert.ErrorIs(t, AddWindow(cnv, "window", WindowConfig[int]{Size: time.Second}, collectWindow), ErrTrackedLoopMode)
+}
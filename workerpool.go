@@ -1,10 +1,13 @@
 package conveyor
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"reflect"
 	"runtime/debug"
 	"sync"
+	"sync/atomic"
 
 	"golang.org/x/sync/semaphore"
 )
@@ -44,6 +47,14 @@ type WPool struct {
 	Name string
 	Wg   sync.WaitGroup
 	sem  *semaphore.Weighted
+	live atomic.Int64 // goroutines started through spawn(), that haven't returned yet
+
+	batch  *batchConfig   // set when the worker's channels carry chunks of items
+	relays sync.WaitGroup // goroutines moving items between chunks and the executor's own channels
+
+	edge    *edge              // configuration of the worker's input edges, set by the conveyor
+	inlets  []any              // channels the previous stages write to, by input index, when an input edge drops items on overflow
+	onStart []func(CnvContext) // relays of the inlets, started along with the worker
 }
 
 // ConcreteNodeWorker to run different nodes
@@ -60,29 +71,33 @@ type ConcreteJointWorker struct {
 	Executor jointExecutor
 }
 
-// NodeWorker interface binds to nodes that have the capability to fetch intermediate data, and forward it to next node
+// NodeWorker interface binds to nodes that have the capability to fetch intermediate data, and forward it to next node.
+// Channels are typed (e.g. a chan int for a node producing ints), and handed around as any, so that workers of
+// different types can be linked. Setting a channel of the wrong type returns ErrTypeMismatch.
 type NodeWorker interface {
 	Start(ctx CnvContext) error
 	WaitAndStop(ctx CnvContext) error
 	CreateChannels(int)
 	WorkerType() string
-	SetInputChannel(chan any) error
-	SetOutputChannel(chan any) error
-	GetInputChannel() (chan any, error)
-	GetOutputChannel() (chan any, error)
+	SetInputChannel(any) error
+	SetOutputChannel(any) error
+	GetInputChannel() (any, error)
+	GetOutputChannel() (any, error)
 }
 
-// JointWorker interface binds to nodes that have the capability to fetch intermidiate data, and forward it to next node
+// JointWorker interface binds to nodes that have the capability to fetch intermidiate data, and forward it to next node.
+// Like for a NodeWorker, channels are typed, and handed around as any: a slice of channels is a []chan T.
 type JointWorker interface {
 	Start(ctx CnvContext) error
 	WaitAndStop() error
 	CreateChannels(int)
-	SetInputChannels([]chan any) error
-	SetOutputChannels([]chan any) error
-	GetInputChannels() ([]chan any, error)
-	GetOutputChannels() ([]chan any, error)
-	AddInputChannel(chan any) error
-	AddOutputChannel(chan any) error
+	SetInputChannels(any) error
+	SetOutputChannels(any) error
+	GetInputChannels() (any, error)
+	GetOutputChannels() (any, error)
+	GetInputChannel(index int) (any, error)
+	AddInputChannel(any) error
+	AddOutputChannel(any) error
 }
 
 func newConcreteNodeWorker(executor nodeExecutor, mode WorkerMode) *ConcreteNodeWorker {
@@ -111,16 +126,15 @@ func (cnw *ConcreteNodeWorker) Start() {
 	}
 }
 
-// startLoopMode starts ConcreteNodeWorker in loop mode
-func (cnw *ConcreteNodeWorker) startLoopMode(ctx CnvContext, inputChannel chan any,
-	outChannel chan any) error {
+// startLoopMode starts ConcreteNodeWorker in loop mode, with WorkerCount goroutines calling executeLoop
+func (cnw *ConcreteNodeWorker) startLoopMode(ctx CnvContext, executeLoop func() error) error {
 
 	for i := 0; i < cnw.WorkerCount; i++ {
 		cnw.Wg.Add(1)
-		go func() {
+		cnw.spawn(func() {
 			defer cnw.recovery(ctx, "ConcreteNodeWorker")
 			defer cnw.Wg.Done()
-			if err := cnw.Executor.executeLoopUntyped(ctx, inputChannel, outChannel); err != nil {
+			if err := executeLoop(); err != nil {
 				if err == ErrExecuteLoopNotImplemented {
 					ctx.SendLog(0, fmt.Sprintf("Executor:[%s] ", cnw.Executor.GetUniqueIdentifier()), err)
 
@@ -130,7 +144,7 @@ func (cnw *ConcreteNodeWorker) startLoopMode(ctx CnvContext, inputChannel chan a
 				ctx.RecordError(cnw.Executor.GetName(), err)
 				return
 			}
-		}()
+		})
 	}
 
 	return nil
@@ -139,19 +153,16 @@ func (cnw *ConcreteNodeWorker) startLoopMode(ctx CnvContext, inputChannel chan a
 // CreateChannels creates channels for the worker
 func (cnw *ConcreteNodeWorker) CreateChannels(buffer int) {}
 
-// WaitAndStop ConcreteNodeWorker
+// WaitAndStop ConcreteNodeWorker. It waits for every goroutine of the worker to return, even if ctx is done,
+// so that none of them is left behind to write on a channel that's about to be closed.
+// The library's own goroutines never block once ctx is done, so only an executor ignoring ctx can hold it up.
 func (cnw *ConcreteNodeWorker) WaitAndStop(ctx CnvContext) error {
 
-	select {
-	case <-ctx.Done():
-		return nil
-	default:
-	}
-
 	if cnw.Mode == WorkerModeTransaction {
-		if err := cnw.sem.Acquire(ctx, int64(cnw.WorkerCount)); err != nil {
-			ctx.SendLog(0, fmt.Sprintf("Worker:[%s] for Executor:[%s] Failed to acquire semaphore",
-				cnw.Name, cnw.Executor.GetUniqueIdentifier()), err)
+		// sem is only missing if the worker failed to start
+		if cnw.sem != nil {
+			// All in-flight transactions are done, once every slot can be acquired
+			_ = cnw.sem.Acquire(context.Background(), int64(cnw.WorkerCount))
 		}
 	} else {
 		cnw.Wg.Wait()
@@ -173,6 +184,31 @@ func (wp *ConcreteJointWorker) Start() {
 	}
 }
 
+// spawn runs f in a new goroutine, counted as live for the pool until f returns
+func (wp *WPool) spawn(f func()) {
+	wp.live.Add(1)
+	go func() {
+		defer wp.live.Add(-1)
+		f()
+	}()
+}
+
+// drain reads ch until it's closed. Workers drain their input once they are done,
+// so that the previous node never blocks on it, even if an executor stopped reading early.
+func drain[T any](ch <-chan T) {
+	for range ch {
+	}
+}
+
+// channelOf returns ch as a chan T, or ErrTypeMismatch if it's a channel of another type
+func channelOf[T any](ch any) (chan T, error) {
+	typed, ok := ch.(chan T)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected %v but got %T", ErrTypeMismatch, reflect.TypeFor[chan T](), ch)
+	}
+	return typed, nil
+}
+
 // Wait for worker to finish
 func (wp *WPool) Wait() {
 	wp.Wg.Wait()
@@ -186,6 +222,7 @@ func (cnw *ConcreteNodeWorker) recovery(ctx CnvContext, caller string) {
 	if r := recover(); r != nil {
 		ctx.SendLog(0, fmt.Sprintf("Worker:[%s] for Executor:[%s] recovered:[%v] caller:[%s]",
 			cnw.Name, cnw.Executor.GetUniqueIdentifier(), r, caller), nil)
+		ctx.RecordError(cnw.Executor.GetName(), &PanicError{Value: r})
 
 		fmt.Println("recovered:", r, caller)
 		debug.PrintStack()